@@ -9,6 +9,9 @@ PROJECT_DIR="$(dirname "$SCRIPT_DIR")"
 GO_HELPER_DIR="$PROJECT_DIR/src/analyzer/go/go-helper"
 OUTPUT_DIR="$PROJECT_DIR/dist/go-helper"
 
+VERSION="${VERSION:-$(git -C "$PROJECT_DIR" describe --tags --always 2>/dev/null || echo dev)}"
+LDFLAGS="-s -w -X main.version=$VERSION"
+
 mkdir -p "$OUTPUT_DIR"
 
 echo "Building Go helper from $GO_HELPER_DIR..."
@@ -31,9 +34,16 @@ for target in "${TARGETS[@]}"; do
   fi
 
   echo "  Building ${goos}/${goarch}..."
-  GOOS="$goos" GOARCH="$goarch" CGO_ENABLED=0 go build -ldflags="-s -w" -o "$OUTPUT_DIR/$output_name" .
+  GOOS="$goos" GOARCH="$goarch" CGO_ENABLED=0 go build -ldflags="$LDFLAGS" -o "$OUTPUT_DIR/$output_name" .
 done
 
+# Browser build: AST-only analysis of in-memory sources, exposed to JavaScript
+# as codegraphAnalyzeSources. Load it with Go's wasm_exec.js support file.
+echo "  Building js/wasm..."
+GOOS=js GOARCH=wasm go build -ldflags="$LDFLAGS" -o "$OUTPUT_DIR/go-helper.wasm" .
+cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" "$OUTPUT_DIR/" 2>/dev/null \
+  || cp "$(go env GOROOT)/misc/wasm/wasm_exec.js" "$OUTPUT_DIR/"
+
 echo ""
 echo "Built binaries:"
 ls -la "$OUTPUT_DIR/"
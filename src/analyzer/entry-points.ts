@@ -83,8 +83,8 @@ function autoDetectEntryPoint(node: GraphNode): boolean {
 }
 
 /**
- * Propagate liveness from entry points through the call graph.
- * Uses BFS to mark all reachable nodes as "live".
+ * Propagate liveness from entry points (and nodes marked keptBy) through the
+ * call graph. Uses BFS to mark all reachable nodes as "live".
  */
 export function propagateEntryPoints(
   nodes: GraphNode[],
@@ -99,9 +99,12 @@ export function propagateEntryPoints(
     adjacency.set(edge.source, targets);
   }
 
-  // BFS from entry points
+  // BFS from entry points and from functions the analyzer keeps live
   const reachable = new Set<string>();
   const queue: string[] = [...entryPointIds];
+  for (const node of nodes) {
+    if (node.keptBy) queue.push(node.id);
+  }
 
   while (queue.length > 0) {
     const nodeId = queue.shift()!;
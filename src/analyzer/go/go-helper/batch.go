@@ -0,0 +1,71 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// batchError is the line written for a request that fails.
+type batchError struct {
+	Error string `json:"error"`
+}
+
+// batch answers a stream of requests: each line of in is a JSON
+// goanalyzer.Options, and each gets one line on out, its graph or a
+// batchError. Packages loaded for one request are reused by the next ones
+// on the same unchanged project (see goanalyzer.LoadCache), so that an
+// orchestrator keeping the helper running pays for type checking once.
+func batch(cfg *cliConfig, in io.Reader, out, log io.Writer) error {
+	cache := goanalyzer.NewLoadCache()
+	r := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var resp any
+			if graph, err := batchRequest(cfg, line, cache, log); err != nil {
+				resp = batchError{Error: err.Error()}
+			} else {
+				resp = graph
+			}
+			if err := enc.Encode(resp); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func batchRequest(cfg *cliConfig, line []byte, cache *goanalyzer.LoadCache, log io.Writer) (goanalyzer.Graph, error) {
+	opts, err := goanalyzer.ParseOptions(line)
+	if err != nil {
+		return goanalyzer.Graph{}, err
+	}
+	if opts, err = cfg.complete(opts); err != nil {
+		return goanalyzer.Graph{}, err
+	}
+	opts.Cache, opts.Log = cache, log
+	graph, err := goanalyzer.Analyze(context.Background(), opts)
+	if err != nil {
+		return graph, err
+	}
+	if len(opts.Reports) > 0 {
+		if _, err := goanalyzer.WriteReports(&graph, opts); err != nil {
+			return graph, err
+		}
+	}
+	return graph, nil
+}
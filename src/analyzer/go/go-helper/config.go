@@ -0,0 +1,317 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+	"golang.org/x/mod/modfile"
+)
+
+// cliConfig holds the command-line flags.
+type cliConfig struct {
+	config    string
+	root      string
+	exclude   []string
+	algorithm string
+	format    string
+	output    string
+	version   bool
+	// schema names the JSON Schema to print ("input" or "output").
+	schema string
+	// reanalyze and previous select focused re-analysis of one function
+	// against a previously written graph (see goanalyzer.Reanalyze). With
+	// merge, previous is the existing graph whose curated fields carry over
+	// (see goanalyzer.Graph.CarryCuration).
+	reanalyze string
+	previous  string
+	// merge combines the graph files named by the arguments instead of
+	// analyzing (see goanalyzer.Merge).
+	merge   bool
+	shards  []string
+	debug   bool
+	explain string
+	// diff compares the two graph files named by the arguments (see
+	// goanalyzer.DiffGraphs).
+	diff      bool
+	diffFiles []string
+	// baseline and updateBaseline set Options.Baseline and
+	// Options.UpdateBaseline.
+	baseline       string
+	updateBaseline bool
+	// reports and reportDir add to Options.Reports and set Options.ReportDir.
+	reports   []string
+	reportDir string
+	// compat is the "base..head" revision range for goanalyzer.CompareRefs.
+	compat string
+	// batch answers a stream of requests on stdin (see batch).
+	batch bool
+	// grpc is the address to serve the gRPC service on (see serveGRPC), and
+	// grpcAllowRemote lets it be a non-loopback TCP address.
+	grpc            string
+	grpcAllowRemote bool
+	// mcp serves the Model Context Protocol on stdio (see serveMCP).
+	mcp bool
+	// lsp serves the Language Server Protocol on stdio (see serveLSP).
+	lsp bool
+	// sync, syncBatch and syncHeaders push the graph's changes to an HTTP
+	// endpoint (see syncGraph).
+	sync        string
+	syncBatch   int
+	syncHeaders []string
+}
+
+func parseFlags(args []string) (*cliConfig, error) {
+	cfg := &cliConfig{}
+	fs := flag.NewFlagSet("go-helper", flag.ContinueOnError)
+	fs.StringVar(&cfg.config, "config", "", "read options from a JSON `file` instead of stdin")
+	fs.StringVar(&cfg.root, "root", "", "analyze the Go module in `dir` (overrides projectRoot)")
+	fs.Func("exclude", "skip files matching `glob` (repeatable; \"**\" spans directories)", func(glob string) error {
+		cfg.exclude = append(cfg.exclude, glob)
+		return nil
+	})
+	fs.StringVar(&cfg.algorithm, "algorithm", "", "call resolution: \"types\" (default) or \"ast\"")
+	fs.StringVar(&cfg.format, "format", formatJSON, "output format: \"json\" or \"dot\"")
+	fs.StringVar(&cfg.output, "output", "", "write the graph to `file` instead of stdout")
+	fs.StringVar(&cfg.reanalyze, "reanalyze", "", "re-analyze only the function with node `id` and print the delta (requires --previous)")
+	fs.StringVar(&cfg.previous, "previous", "", "previous JSON graph `file` for --reanalyze, or whose curated fields --merge keeps")
+	fs.BoolVar(&cfg.merge, "merge", false, "merge the graph files given as arguments (`[prefix=]file`, prefix being the shard's root within the project)")
+	fs.BoolVar(&cfg.diff, "diff", false, "compare the graph files `old new` given as arguments, following moved and renamed functions")
+	fs.BoolVar(&cfg.debug, "debug", false, "record the source expression behind each edge in its provenance")
+	fs.StringVar(&cfg.explain, "explain", "", "explain the edge `source->target`, or the status of a node ID, in the output's explanation")
+	fs.StringVar(&cfg.baseline, "baseline", "", "report only dead functions missing from the baseline `file`")
+	fs.BoolVar(&cfg.updateBaseline, "update-baseline", false, "rewrite the --baseline file with the current dead functions")
+	fs.Func("report", "also write the report `kind`: \"html\", \"csv\", \"tsv\" or \"zip\" (repeatable)", func(kind string) error {
+		cfg.reports = append(cfg.reports, kind)
+		return nil
+	})
+	fs.StringVar(&cfg.reportDir, "report-dir", "", "write reports into `dir` (default: the project root)")
+	fs.StringVar(&cfg.compat, "compat", "", "print the breaking API changes between the git revisions `base..head` (head defaults to the working tree)")
+	fs.BoolVar(&cfg.batch, "batch", false, "keep running, answering each line of JSON options on stdin with a line of JSON graph")
+	fs.StringVar(&cfg.grpc, "grpc", "", "serve the gRPC service of proto/codegraph.proto on `addr` (host:port, or unix:path)")
+	fs.BoolVar(&cfg.grpcAllowRemote, "grpc-allow-remote", false, "let --grpc listen on a non-loopback address; requests are not authenticated")
+	fs.BoolVar(&cfg.mcp, "mcp", false, "serve the call graph of --root or --config to MCP clients on stdin and stdout")
+	fs.BoolVar(&cfg.lsp, "lsp", false, "serve call hierarchy and dead code diagnostics to editors on stdin and stdout (the project defaults to the workspace root)")
+	fs.StringVar(&cfg.sync, "sync", "", "keep running, posting the graph's node and edge upserts and deletes to `url` on every change")
+	fs.IntVar(&cfg.syncBatch, "sync-batch", defaultSyncBatch, "post at most `n` writes per --sync request")
+	fs.Func("sync-header", "add the HTTP header `name: value` to --sync requests (repeatable)", func(h string) error {
+		if !strings.Contains(h, ":") {
+			return fmt.Errorf("%q is not \"name: value\"", h)
+		}
+		cfg.syncHeaders = append(cfg.syncHeaders, h)
+		return nil
+	})
+	fs.StringVar(&cfg.schema, "schema", "", "print the JSON Schema of the helper's \"input\" or \"output\" and exit")
+	fs.BoolVar(&cfg.version, "version", false, "print the helper and schema versions and exit")
+	if err := fs.Parse(args); err != nil {
+		// The flag set has already reported the problem and usage.
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if cfg.merge {
+		if fs.NArg() == 0 {
+			return nil, errors.New("--merge needs at least one graph file")
+		}
+		cfg.shards = fs.Args()
+	} else if cfg.diff {
+		if fs.NArg() != 2 {
+			return nil, errors.New("--diff needs two graph files")
+		}
+		cfg.diffFiles = fs.Args()
+	} else if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if cfg.format != formatJSON && cfg.format != formatDOT {
+		return nil, fmt.Errorf("unknown format %q (want %q or %q)", cfg.format, formatJSON, formatDOT)
+	}
+	if cfg.schema != "" && cfg.schema != "input" && cfg.schema != "output" {
+		return nil, fmt.Errorf("unknown schema %q (want \"input\" or \"output\")", cfg.schema)
+	}
+	if cfg.updateBaseline && cfg.baseline == "" {
+		return nil, errors.New("--update-baseline requires --baseline")
+	}
+	if cfg.batch && (cfg.merge || cfg.diff || cfg.reanalyze != "" || cfg.compat != "" || cfg.config != "" || cfg.output != "" || cfg.format != formatJSON) {
+		return nil, errors.New("--batch reads JSON options from stdin and writes JSON graphs to stdout; it cannot be combined with --merge, --diff, --reanalyze, --compat, --config, --output or --format")
+	}
+	if cfg.grpc != "" && (cfg.batch || cfg.merge || cfg.diff || cfg.reanalyze != "" || cfg.compat != "" || cfg.config != "" || cfg.output != "") {
+		return nil, errors.New("--grpc takes its requests over gRPC; it cannot be combined with --batch, --merge, --diff, --reanalyze, --compat, --config or --output")
+	}
+	if cfg.grpcAllowRemote && cfg.grpc == "" {
+		return nil, errors.New("--grpc-allow-remote requires --grpc")
+	}
+	if cfg.mcp && (cfg.batch || cfg.grpc != "" || cfg.merge || cfg.diff || cfg.reanalyze != "" || cfg.compat != "" || cfg.output != "") {
+		return nil, errors.New("--mcp talks MCP on stdin and stdout; it cannot be combined with --batch, --grpc, --merge, --diff, --reanalyze, --compat or --output")
+	}
+	if cfg.mcp && cfg.root == "" && cfg.config == "" {
+		return nil, errors.New("--mcp requires --root or --config, since stdin carries the protocol")
+	}
+	if cfg.lsp && (cfg.mcp || cfg.batch || cfg.grpc != "" || cfg.merge || cfg.diff || cfg.reanalyze != "" || cfg.compat != "" || cfg.output != "") {
+		return nil, errors.New("--lsp talks LSP on stdin and stdout; it cannot be combined with --mcp, --batch, --grpc, --merge, --diff, --reanalyze, --compat or --output")
+	}
+	if cfg.sync != "" && (cfg.lsp || cfg.mcp || cfg.batch || cfg.grpc != "" || cfg.merge || cfg.diff || cfg.reanalyze != "" || cfg.compat != "" || cfg.output != "") {
+		return nil, errors.New("--sync pushes to its endpoint; it cannot be combined with --lsp, --mcp, --batch, --grpc, --merge, --diff, --reanalyze, --compat or --output")
+	}
+	if cfg.syncBatch <= 0 {
+		return nil, errors.New("--sync-batch must be positive")
+	}
+	if cfg.reanalyze != "" && cfg.previous == "" {
+		return nil, errors.New("--reanalyze needs --previous")
+	}
+	if cfg.previous != "" && cfg.reanalyze == "" && !cfg.merge {
+		return nil, errors.New("--previous is only used with --reanalyze or --merge")
+	}
+	return cfg, nil
+}
+
+// chunkLocation returns the directory and base name of the chunk files of
+// Options.MaxNodesPerChunk: next to the --output file and named after it,
+// or else "graph" in the report directory.
+func (cfg *cliConfig) chunkLocation(opts goanalyzer.Options) (dir, base string) {
+	if cfg.output != "" {
+		base = filepath.Base(cfg.output)
+		return filepath.Dir(cfg.output), strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	dir = opts.ReportDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(opts.ProjectRoot, dir)
+	}
+	return dir, "graph"
+}
+
+// readPrevious loads the graph named by --previous.
+func (cfg *cliConfig) readPrevious() (goanalyzer.Graph, error) {
+	return readGraph(cfg.previous)
+}
+
+// readShards loads the graph files given to --merge.
+func (cfg *cliConfig) readShards() ([]goanalyzer.Shard, error) {
+	var shards []goanalyzer.Shard
+	for _, arg := range cfg.shards {
+		prefix, file, ok := strings.Cut(arg, "=")
+		if !ok {
+			prefix, file = "", arg
+		}
+		graph, err := readGraph(file)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, goanalyzer.Shard{Prefix: filepath.ToSlash(prefix), Graph: graph})
+	}
+	return shards, nil
+}
+
+func readGraph(file string) (goanalyzer.Graph, error) {
+	var graph goanalyzer.Graph
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return graph, err
+	}
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return graph, fmt.Errorf("%s: %w", file, err)
+	}
+	return graph, nil
+}
+
+// options assembles the analysis options. They are read from the --config
+// file, or from stdin unless --root is given, and then overridden by flags.
+// When no file list is supplied, the .go files under the root outside the
+// excludes are discovered; Analyze then limits the graph to them.
+func (cfg *cliConfig) options(stdin io.Reader) (goanalyzer.Options, error) {
+	opts, err := cfg.readOptions(stdin)
+	if err != nil {
+		return opts, err
+	}
+	// Flags give paths as the helper sees them, options as the
+	// orchestrator does.
+	return cfg.complete(opts.MapPaths())
+}
+
+// readOptions reads the options from the --config file, or from stdin
+// unless --root is given.
+func (cfg *cliConfig) readOptions(stdin io.Reader) (opts goanalyzer.Options, err error) {
+	switch {
+	case cfg.config != "":
+		f, err := os.Open(cfg.config)
+		if err != nil {
+			return opts, err
+		}
+		defer f.Close()
+		if opts, err = goanalyzer.DecodeOptions(f); err != nil {
+			return opts, fmt.Errorf("%s: %w", cfg.config, err)
+		}
+	case cfg.root == "":
+		if opts, err = goanalyzer.DecodeOptions(stdin); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// complete overrides opts with the flags and fills in the module and the
+// file list.
+func (cfg *cliConfig) complete(opts goanalyzer.Options) (_ goanalyzer.Options, err error) {
+	if cfg.root != "" {
+		opts.ProjectRoot = cfg.root
+	}
+	if opts.ProjectRoot == "" {
+		opts.ProjectRoot = "."
+	}
+	if cfg.algorithm != "" {
+		opts.Algorithm = cfg.algorithm
+	}
+	if cfg.debug {
+		opts.Debug = true
+	}
+	if cfg.baseline != "" {
+		// Unlike the options file, the flag is relative to the working directory.
+		if opts.Baseline, err = filepath.Abs(cfg.baseline); err != nil {
+			return opts, err
+		}
+		opts.UpdateBaseline = cfg.updateBaseline
+	}
+	opts.Reports = append(opts.Reports, cfg.reports...)
+	if cfg.reportDir != "" {
+		if opts.ReportDir, err = filepath.Abs(cfg.reportDir); err != nil {
+			return opts, err
+		}
+	}
+	if cfg.explain != "" {
+		if source, target, ok := strings.Cut(cfg.explain, "->"); ok {
+			opts.Explain = &goanalyzer.ExplainRequest{Edge: &goanalyzer.EdgeRef{Source: source, Target: target}}
+		} else {
+			opts.Explain = &goanalyzer.ExplainRequest{Node: cfg.explain}
+		}
+	}
+	if opts.Module == "" {
+		if data, err := os.ReadFile(filepath.Join(opts.ProjectRoot, "go.mod")); err == nil {
+			opts.Module = modfile.ModulePath(data)
+		}
+	}
+
+	if cfg.compat != "" {
+		// Each revision's files are discovered by CompareRefs.
+		return opts, nil
+	}
+	if len(opts.Files) == 0 && opts.FilesFrom == "" {
+		exclude := append(append([]string{}, goanalyzer.DefaultExclude...), cfg.exclude...)
+		opts.Files, err = goanalyzer.DiscoverFiles(opts.ProjectRoot, exclude)
+		return opts, err
+	}
+	if len(cfg.exclude) > 0 {
+		if opts, err = opts.ExpandFiles(); err != nil {
+			return opts, err
+		}
+		opts.Files = goanalyzer.FilterFiles(opts.Files, cfg.exclude)
+	}
+	return opts, nil
+}
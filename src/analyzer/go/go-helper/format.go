@@ -0,0 +1,52 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+const (
+	formatJSON = "json"
+	formatDOT  = "dot"
+)
+
+func writeGraph(w io.Writer, graph goanalyzer.Graph, format string) error {
+	if format == formatDOT {
+		return writeDOT(w, graph)
+	}
+	return json.NewEncoder(w).Encode(graph)
+}
+
+// writeDOT renders the graph for Graphviz: one box per node, colored by its
+// liveness status, and one arrow per edge, dashed when unresolved.
+func writeDOT(w io.Writer, graph goanalyzer.Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph codegraph {")
+	fmt.Fprintln(bw, "  node [shape=box, style=rounded];")
+	for _, n := range graph.Nodes {
+		label := n.Name
+		if n.PackageOrModule != "" {
+			label = n.PackageOrModule + "." + n.Name
+		}
+		fmt.Fprintf(bw, "  %q [label=%q", n.ID, label)
+		if n.Color != "" {
+			fmt.Fprintf(bw, ", color=%q", n.Color)
+		}
+		fmt.Fprintln(bw, "];")
+	}
+	for _, e := range graph.Edges {
+		fmt.Fprintf(bw, "  %q -> %q", e.Source, e.Target)
+		if !e.IsResolved {
+			fmt.Fprint(bw, " [style=dashed]")
+		}
+		fmt.Fprintln(bw, ";")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
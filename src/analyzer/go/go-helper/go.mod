@@ -2,9 +2,18 @@ module github.com/codegraph/go-helper
 
 go 1.24.0
 
-require golang.org/x/tools v0.42.0
+require (
+	github.com/google/pprof v0.0.0-20250602020802-c6617b811d0e
+	golang.org/x/mod v0.33.0
+	golang.org/x/tools v0.42.0
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.6
+)
 
 require (
-	golang.org/x/mod v0.33.0 // indirect
+	golang.org/x/net v0.50.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 )
@@ -0,0 +1,305 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+	"github.com/codegraph/go-helper/proto/codegraphv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchInterval is how often Watch checks the project for changes.
+const watchInterval = 500 * time.Millisecond
+
+// serveGRPC runs the GoHelper service of proto/codegraph.proto on addr, a
+// TCP address ("localhost:50051") or "unix:" followed by a socket path,
+// until the listener fails. Requests share a goanalyzer.LoadCache, as in
+// --batch. Callers are not authenticated, so a TCP address must be a
+// loopback one unless --grpc-allow-remote is given.
+func serveGRPC(cfg *cliConfig, addr string) error {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		network, addr = "unix", path
+	} else if !cfg.grpcAllowRemote {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("--grpc %s: not a loopback address; add --grpc-allow-remote to serve other hosts", addr)
+		}
+	}
+	lis, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Serving gRPC on %s\n", lis.Addr())
+	return newGRPCServer(cfg).Serve(lis)
+}
+
+func newGRPCServer(cfg *cliConfig) *grpc.Server {
+	srv := grpc.NewServer()
+	codegraphv1.RegisterGoHelperServer(srv, &goHelperServer{cfg: cfg, cache: goanalyzer.NewLoadCache()})
+	return srv
+}
+
+type goHelperServer struct {
+	codegraphv1.UnimplementedGoHelperServer
+	cfg   *cliConfig
+	cache *goanalyzer.LoadCache
+}
+
+// options parses a request's options JSON and applies the flags, as for
+// --batch. Options that run programs or write files are refused (see
+// checkRequestOptions); only the server's flags may set them.
+func (s *goHelperServer) options(options string) (goanalyzer.Options, error) {
+	opts, err := goanalyzer.ParseOptions([]byte(options))
+	if err == nil {
+		err = checkRequestOptions(opts)
+	}
+	if err == nil {
+		opts, err = s.cfg.complete(opts)
+	}
+	if err != nil {
+		return opts, status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts.Cache, opts.Log = s.cache, os.Stderr
+	return opts, nil
+}
+
+// checkRequestOptions refuses the options of an unauthenticated request
+// that would run programs (extensions, a packages driver, a toolchain
+// download, go build for inlining or escapes) or write a file (the
+// baseline).
+func checkRequestOptions(opts goanalyzer.Options) error {
+	var refused []string
+	for name, set := range map[string]bool{
+		"extensions":     len(opts.Extensions) > 0,
+		"packagesDriver": opts.PackagesDriver != "",
+		"goVersion":      opts.GoVersion != "",
+		"inlining":       opts.Inlining,
+		"escapes":        opts.Escapes,
+		"updateBaseline": opts.UpdateBaseline,
+	} {
+		if set {
+			refused = append(refused, name)
+		}
+	}
+	if len(refused) == 0 {
+		return nil
+	}
+	sort.Strings(refused)
+	return fmt.Errorf("%s cannot be set over gRPC", strings.Join(refused, ", "))
+}
+
+// analyze runs the analysis and returns the graph message.
+func (s *goHelperServer) analyze(ctx context.Context, opts goanalyzer.Options) (*codegraphv1.Graph, error) {
+	graph, err := goanalyzer.Analyze(ctx, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return graphToProto(graph), nil
+}
+
+func (s *goHelperServer) Analyze(ctx context.Context, req *codegraphv1.AnalyzeRequest) (*codegraphv1.Graph, error) {
+	opts, err := s.options(req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+	return s.analyze(ctx, opts)
+}
+
+func (s *goHelperServer) Query(ctx context.Context, req *codegraphv1.QueryRequest) (*codegraphv1.Graph, error) {
+	opts, err := s.options(req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+	opts.Roots, opts.MaxDepth = req.GetRoots(), int(req.GetMaxDepth())
+	return s.analyze(ctx, opts)
+}
+
+func (s *goHelperServer) Diff(ctx context.Context, req *codegraphv1.DiffRequest) (*codegraphv1.GraphDiff, error) {
+	diff := goanalyzer.DiffGraphs(graphFromProto(req.GetOldGraph()), graphFromProto(req.GetNewGraph()))
+	moved := make([]*codegraphv1.NodeMove, len(diff.Moved))
+	for i, m := range diff.Moved {
+		moved[i] = &codegraphv1.NodeMove{From: m.From, To: m.To, FromSymbol: m.FromSymbol, ToSymbol: m.ToSymbol, Reason: m.Reason, Renamed: m.Renamed}
+	}
+	return &codegraphv1.GraphDiff{Added: diff.Added, Removed: diff.Removed, Changed: diff.Changed, Moved: moved}, nil
+}
+
+// Watch sends a graph for each version of the project. A failed analysis
+// is sent as an error event, and the next change is analyzed again.
+func (s *goHelperServer) Watch(req *codegraphv1.AnalyzeRequest, stream grpc.ServerStreamingServer[codegraphv1.WatchEvent]) error {
+	ctx := stream.Context()
+	opts, err := s.options(req.GetOptions())
+	if err != nil {
+		return err
+	}
+	root, err := filepath.Abs(opts.ProjectRoot)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var last string
+	for {
+		// The project is fingerprinted before each analysis, so that an
+		// edit made during one triggers the next.
+		fingerprint, err := goanalyzer.FingerprintProject(root)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if fingerprint != last {
+			last = fingerprint
+			event := &codegraphv1.WatchEvent{}
+			graph, err := s.analyze(ctx, opts)
+			switch {
+			case ctx.Err() != nil:
+				return status.FromContextError(ctx.Err()).Err()
+			case err != nil:
+				event.Event = &codegraphv1.WatchEvent_Error{Error: status.Convert(err).Message()}
+			default:
+				event.Event = &codegraphv1.WatchEvent_Graph{Graph: graph}
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// graphToProto converts the call graph of g, leaving out the optional
+// sections proto/codegraph.proto does not carry.
+func graphToProto(g goanalyzer.Graph) *codegraphv1.Graph {
+	out := &codegraphv1.Graph{
+		Nodes:         make([]*codegraphv1.Node, len(g.Nodes)),
+		Edges:         make([]*codegraphv1.Edge, len(g.Edges)),
+		Diagnostics:   make([]*codegraphv1.Diagnostic, len(g.Diagnostics)),
+		GraphHash:     g.GraphHash,
+		PackageHashes: g.PackageHashes,
+	}
+	for i, n := range g.Nodes {
+		params := make([]*codegraphv1.Parameter, len(n.Parameters))
+		for j, p := range n.Parameters {
+			params[j] = &codegraphv1.Parameter{Name: p.Name, Type: p.Type, IsUsed: p.IsUsed, Position: int32(p.Position)}
+		}
+		out.Nodes[i] = &codegraphv1.Node{
+			Id:               n.ID,
+			Name:             n.Name,
+			QualifiedName:    n.QualifiedName,
+			FilePath:         n.FilePath,
+			StartLine:        int32(n.StartLine),
+			EndLine:          int32(n.EndLine),
+			Language:         n.Language,
+			Kind:             n.Kind,
+			Visibility:       n.Visibility,
+			IsEntryPoint:     n.IsEntryPoint,
+			Parameters:       params,
+			UnusedParameters: n.UnusedParameters,
+			PackageOrModule:  n.PackageOrModule,
+			LinesOfCode:      int32(n.LinesOfCode),
+			Status:           n.Status,
+			Color:            n.Color,
+			SymbolId:         n.SymbolID,
+			BodyHash:         n.BodyHash,
+			Dependency:       n.Dependency,
+			Approximate:      n.Approximate,
+			RegisteredBy:     n.RegisteredBy,
+			CallerCount:      int32(n.CallerCount),
+			CalleeCount:      int32(n.CalleeCount),
+		}
+	}
+	for i, e := range g.Edges {
+		out.Edges[i] = &codegraphv1.Edge{
+			Source:     e.Source,
+			Target:     e.Target,
+			CallSite:   &codegraphv1.CallSite{FilePath: e.CallSite.FilePath, Line: int32(e.CallSite.Line), Column: int32(e.CallSite.Column)},
+			Kind:       e.Kind,
+			IsResolved: e.IsResolved,
+			Weight:     e.Weight,
+			Provenance: &codegraphv1.Provenance{Phase: e.Provenance.Phase, Rule: e.Provenance.Rule, Snippet: e.Provenance.Snippet},
+			Route:      e.Route,
+			Middleware: e.Middleware,
+		}
+	}
+	for i, d := range g.Diagnostics {
+		out.Diagnostics[i] = &codegraphv1.Diagnostic{Kind: d.Kind, FilePath: d.FilePath, Line: int32(d.Line), Column: int32(d.Column), Message: d.Message}
+	}
+	return out
+}
+
+// graphFromProto is the inverse of graphToProto.
+func graphFromProto(g *codegraphv1.Graph) goanalyzer.Graph {
+	out := goanalyzer.Graph{
+		Nodes:         make([]goanalyzer.Node, len(g.GetNodes())),
+		Edges:         make([]goanalyzer.Edge, len(g.GetEdges())),
+		GraphHash:     g.GetGraphHash(),
+		PackageHashes: g.GetPackageHashes(),
+	}
+	for i, n := range g.GetNodes() {
+		params := make([]goanalyzer.Parameter, len(n.GetParameters()))
+		for j, p := range n.GetParameters() {
+			params[j] = goanalyzer.Parameter{Name: p.GetName(), Type: p.Type, IsUsed: p.GetIsUsed(), Position: int(p.GetPosition())}
+		}
+		out.Nodes[i] = goanalyzer.Node{
+			ID:               n.GetId(),
+			Name:             n.GetName(),
+			QualifiedName:    n.GetQualifiedName(),
+			FilePath:         n.GetFilePath(),
+			StartLine:        int(n.GetStartLine()),
+			EndLine:          int(n.GetEndLine()),
+			Language:         n.GetLanguage(),
+			Kind:             n.GetKind(),
+			Visibility:       n.GetVisibility(),
+			IsEntryPoint:     n.GetIsEntryPoint(),
+			Parameters:       params,
+			UnusedParameters: n.GetUnusedParameters(),
+			PackageOrModule:  n.GetPackageOrModule(),
+			LinesOfCode:      int(n.GetLinesOfCode()),
+			Status:           n.GetStatus(),
+			Color:            n.GetColor(),
+			SymbolID:         n.GetSymbolId(),
+			BodyHash:         n.GetBodyHash(),
+			Dependency:       n.GetDependency(),
+			Approximate:      n.GetApproximate(),
+			RegisteredBy:     n.GetRegisteredBy(),
+			CallerCount:      int(n.GetCallerCount()),
+			CalleeCount:      int(n.GetCalleeCount()),
+		}
+	}
+	for i, e := range g.GetEdges() {
+		site, prov := e.GetCallSite(), e.GetProvenance()
+		out.Edges[i] = goanalyzer.Edge{
+			Source:     e.GetSource(),
+			Target:     e.GetTarget(),
+			CallSite:   goanalyzer.CallSite{FilePath: site.GetFilePath(), Line: int(site.GetLine()), Column: int(site.GetColumn())},
+			Kind:       e.GetKind(),
+			IsResolved: e.GetIsResolved(),
+			Weight:     e.GetWeight(),
+			Provenance: goanalyzer.Provenance{Phase: prov.GetPhase(), Rule: prov.GetRule(), Snippet: prov.GetSnippet()},
+			Route:      e.GetRoute(),
+			Middleware: e.GetMiddleware(),
+		}
+	}
+	for _, d := range g.GetDiagnostics() {
+		out.Diagnostics = append(out.Diagnostics, goanalyzer.Diagnostic{Kind: d.GetKind(), FilePath: d.GetFilePath(), Line: int(d.GetLine()), Column: int(d.GetColumn()), Message: d.GetMessage()})
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codegraph/go-helper/proto/codegraphv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcClient serves the GoHelper service in memory and returns a client
+// of it.
+func grpcClient(t *testing.T) codegraphv1.GoHelperClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(&cliConfig{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return codegraphv1.NewGoHelperClient(conn)
+}
+
+// grpcProject writes a two-function project and returns its root.
+func grpcProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":  "module example.com/grpc\n\ngo 1.24\n",
+		"main.go": "package main\n\nfunc helper(n int) int { return n }\n\nfunc main() { helper(1) }\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func grpcOptions(t *testing.T, opts map[string]any) string {
+	t.Helper()
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGRPCRoundTrip(t *testing.T) {
+	client := grpcClient(t)
+	root := grpcProject(t)
+	ctx := context.Background()
+
+	graph, err := client.Analyze(ctx, &codegraphv1.AnalyzeRequest{Options: grpcOptions(t, map[string]any{"projectRoot": root, "algorithm": "ast"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(map[string]*codegraphv1.Node)
+	for _, n := range graph.GetNodes() {
+		nodes[n.GetId()] = n
+	}
+	helper := nodes["main.go:helper"]
+	if helper == nil || nodes["main.go:main"] == nil {
+		t.Fatalf("got nodes %v, want main.go:main and main.go:helper", graph.GetNodes())
+	}
+	if params := helper.GetParameters(); len(params) != 1 || params[0].GetName() != "n" || params[0].GetType() != "int" {
+		t.Errorf("helper has parameters %v, want n int", params)
+	}
+	var called bool
+	for _, e := range graph.GetEdges() {
+		called = called || e.GetSource() == "main.go:main" && e.GetTarget() == "main.go:helper" && e.GetCallSite().GetLine() == 5
+	}
+	if !called {
+		t.Errorf("no edge main.go:main → main.go:helper at line 5 in %v", graph.GetEdges())
+	}
+
+	diff, err := client.Diff(ctx, &codegraphv1.DiffRequest{OldGraph: graph, NewGraph: graph})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.GetAdded())+len(diff.GetRemoved())+len(diff.GetChanged())+len(diff.GetMoved()) != 0 {
+		t.Errorf("graph differs from itself: %v", diff)
+	}
+}
+
+func TestGRPCWatchErrors(t *testing.T) {
+	client := grpcClient(t)
+	root := grpcProject(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Analyze rejects the options on every run, and watching goes on.
+	stream, err := client.Watch(ctx, &codegraphv1.AnalyzeRequest{Options: grpcOptions(t, map[string]any{
+		"projectRoot":    root,
+		"algorithm":      "ast",
+		"parameterLists": map[string]any{"maxParameters": -1},
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 2 {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if event.GetError() == "" {
+			t.Fatalf("event %d is %v, want an error", i, event)
+		}
+		if i == 0 {
+			if err := os.WriteFile(filepath.Join(root, "extra.go"), []byte("package main\n\nfunc extra() {}\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestGRPCRefusesUnsafeOptions(t *testing.T) {
+	client := grpcClient(t)
+	root := grpcProject(t)
+
+	for _, opts := range []map[string]any{
+		{"extensions": []map[string]any{{"name": "x", "command": "true"}}},
+		{"packagesDriver": "driver.sh"},
+		{"goVersion": "1.24.0"},
+		{"inlining": true},
+		{"escapes": true},
+		{"baseline": "baseline.json", "updateBaseline": true},
+	} {
+		opts["projectRoot"], opts["algorithm"] = root, "ast"
+		_, err := client.Analyze(context.Background(), &codegraphv1.AnalyzeRequest{Options: grpcOptions(t, opts)})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Analyze(%v) = %v, want InvalidArgument", opts, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "baseline.json")); !os.IsNotExist(err) {
+		t.Errorf("baseline written: %v", err)
+	}
+}
+
+func TestGRPCListensOnLoopback(t *testing.T) {
+	for _, addr := range []string{":0", "0.0.0.0:0", "example.com:0"} {
+		if err := serveGRPC(&cliConfig{}, addr); err == nil || !strings.Contains(err.Error(), "--grpc-allow-remote") {
+			t.Errorf("serveGRPC(%q) = %v, want a loopback error", addr, err)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// JSON-RPC error codes of the Language Server Protocol.
+const (
+	rpcInternalError        = -32603
+	lspServerNotInitialized = -32002
+	lspRequestFailed        = -32803
+)
+
+// LSP enumerations used below.
+const (
+	lspSyncFull           = 1
+	lspSymbolMethod       = 6
+	lspSymbolFunction     = 12
+	lspSeverityHint       = 4
+	lspTagUnnecessary     = 1
+	lspDeadCodeDiagnostic = "deadCode"
+)
+
+func (e *rpcError) Error() string { return e.Message }
+
+type rpcNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+	Tags     []int    `json:"tags"`
+}
+
+// callHierarchyItem carries the node ID in data, which the client hands
+// back in incomingCalls and outgoingCalls.
+type callHierarchyItem struct {
+	Name           string   `json:"name"`
+	Kind           int      `json:"kind"`
+	Detail         string   `json:"detail,omitempty"`
+	URI            string   `json:"uri"`
+	Range          lspRange `json:"range"`
+	SelectionRange lspRange `json:"selectionRange"`
+	Data           string   `json:"data"`
+}
+
+type callHierarchyCall struct {
+	From       *callHierarchyItem `json:"from,omitempty"`
+	To         *callHierarchyItem `json:"to,omitempty"`
+	FromRanges []lspRange         `json:"fromRanges"`
+}
+
+// lspServer is a language server for editors: call hierarchy
+// (textDocument/prepareCallHierarchy, callHierarchy/incomingCalls and
+// callHierarchy/outgoingCalls) over the call graph, and a "deadCode" hint
+// diagnostic on each dead function of the open documents. The project is
+// analyzed in full when the server starts and on every save; in between,
+// each edit re-analyzes the functions of the edited document with
+// goanalyzer.Reanalyze, which resolves their calls by name, so that
+// functions added since the last save only appear once it is saved.
+// Positions are converted between the graph's byte columns and the
+// protocol's UTF-16 ones as if the source were ASCII.
+type lspServer struct {
+	cfg   *cliConfig
+	out   io.Writer
+	log   io.Writer
+	cache *goanalyzer.LoadCache
+	// base holds the options before completion by the flags, which is
+	// repeated on every full analysis to pick up new files.
+	base  goanalyzer.Options
+	opts  goanalyzer.Options
+	root  string
+	graph *goanalyzer.Graph
+	// open maps the open documents, relative to root, to their text.
+	open map[string]string
+}
+
+// serveLSP runs the language server over stdio until the client sends
+// "exit" or closes in.
+func serveLSP(cfg *cliConfig, in io.Reader, out, log io.Writer) error {
+	s := &lspServer{cfg: cfg, out: out, log: log, cache: goanalyzer.NewLoadCache(), open: make(map[string]string)}
+	r := bufio.NewReader(in)
+	for {
+		body, err := readLSPMessage(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.send(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(req)
+		if req.ID == nil {
+			if err != nil {
+				fmt.Fprintf(log, "%s: %v\n", req.Method, err)
+			}
+			continue
+		}
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+		if err != nil {
+			var rpcErr *rpcError
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &rpcError{Code: lspRequestFailed, Message: err.Error()}
+			}
+			resp.Result, resp.Error = nil, rpcErr
+		} else if result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		if err := s.send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// readLSPMessage reads one message: headers, of which only Content-Length
+// matters, then the JSON body.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+func (s *lspServer) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// handle runs a request or notification and returns its result, nil
+// meaning null.
+func (s *lspServer) handle(req rpcRequest) (any, error) {
+	if req.Method != "initialize" && s.graph == nil {
+		if req.ID == nil {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: lspServerNotInitialized, Message: "server not initialized"}
+	}
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			RootURI  string `json:"rootUri"`
+			RootPath string `json:"rootPath"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.initialize(params.RootURI, params.RootPath)
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.edited(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   lspDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// With full synchronization, the last change is the whole text.
+		return nil, s.edited(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didSave":
+		if err := s.analyze(); err != nil {
+			return nil, err
+		}
+		return nil, s.publishAll()
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		rel, err := s.rel(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.open, rel)
+		return nil, s.send(rpcNotification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: map[string]any{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []lspDiagnostic{},
+		}})
+	case "textDocument/prepareCallHierarchy":
+		var params struct {
+			TextDocument lspDocument `json:"textDocument"`
+			Position     lspPosition `json:"position"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		rel, err := s.rel(params.TextDocument.URI)
+		if err != nil {
+			return nil, nil
+		}
+		if n := s.nodeAt(rel, params.Position.Line+1); n != nil {
+			return []callHierarchyItem{s.item(n)}, nil
+		}
+		return nil, nil
+	case "callHierarchy/incomingCalls", "callHierarchy/outgoingCalls":
+		var params struct {
+			Item callHierarchyItem `json:"item"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.calls(params.Item.Data, req.Method == "callHierarchy/incomingCalls"), nil
+	}
+	if req.ID == nil {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}
+}
+
+// initialize reads the options, from the flags or else the workspace root,
+// and runs the first analysis.
+func (s *lspServer) initialize(rootURI, rootPath string) (any, error) {
+	if s.cfg.root != "" || s.cfg.config != "" {
+		var err error
+		if s.base, err = s.cfg.readOptions(nil); err != nil {
+			return nil, err
+		}
+	} else {
+		root := rootPath
+		if u, err := url.Parse(rootURI); err == nil && u.Scheme == "file" {
+			root = filepath.FromSlash(u.Path)
+		}
+		if root == "" {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "no workspace root; start the server with --root or --config"}
+		}
+		s.base.ProjectRoot = root
+	}
+	if err := s.analyze(); err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+	}
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":      map[string]any{"openClose": true, "change": lspSyncFull, "save": true},
+			"callHierarchyProvider": true,
+		},
+		"serverInfo": map[string]any{"name": "codegraph-go", "version": version},
+	}, nil
+}
+
+// analyze analyzes the whole project, with the open documents as
+// overlays.
+func (s *lspServer) analyze() error {
+	opts, err := s.cfg.complete(s.base)
+	if err != nil {
+		return err
+	}
+	if s.root, err = filepath.Abs(opts.ProjectRoot); err != nil {
+		return err
+	}
+	opts.Overlays = make(map[string]string, len(s.open))
+	for rel, text := range s.open {
+		opts.Overlays[rel] = text
+	}
+	opts.Cache, opts.Log = s.cache, s.log
+	graph, err := goanalyzer.Analyze(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	s.opts, s.graph = opts, &graph
+	return nil
+}
+
+// edited records the text of a document and re-analyzes its functions.
+func (s *lspServer) edited(uri, text string) error {
+	rel, err := s.rel(uri)
+	if err != nil {
+		// Documents outside the project are not analyzed.
+		return nil
+	}
+	s.open[rel] = text
+	if !strings.HasSuffix(rel, ".go") {
+		return nil
+	}
+	opts := s.opts
+	opts.Overlays = map[string]string{rel: text}
+	var ids []string
+	for _, n := range s.graph.Nodes {
+		// Synthetic nodes such as __var_init__ are left to the next save.
+		if n.FilePath == rel && (n.Kind == "function" || n.Kind == "method") {
+			ids = append(ids, n.ID)
+		}
+	}
+	for _, id := range ids {
+		delta, err := goanalyzer.Reanalyze(opts, *s.graph, id)
+		if err != nil {
+			fmt.Fprintf(s.log, "re-analyzing %s: %v\n", id, err)
+			continue
+		}
+		s.graph.ApplyDelta(delta)
+	}
+	return s.publish(rel)
+}
+
+// publishAll publishes the diagnostics of every open document.
+func (s *lspServer) publishAll() error {
+	rels := make([]string, 0, len(s.open))
+	for rel := range s.open {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		if err := s.publish(rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish sends a "deadCode" diagnostic for each dead function of the
+// document rel, tagged unnecessary so that editors fade it out.
+func (s *lspServer) publish(rel string) error {
+	diagnostics := []lspDiagnostic{}
+	for _, n := range s.graph.Nodes {
+		if n.FilePath != rel || n.Status != "dead" {
+			continue
+		}
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range:    nodeRange(&n),
+			Severity: lspSeverityHint,
+			Code:     lspDeadCodeDiagnostic,
+			Source:   "codegraph",
+			Message:  fmt.Sprintf("%s is unreachable from any entry point", n.Name),
+			Tags:     []int{lspTagUnnecessary},
+		})
+	}
+	return s.send(rpcNotification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: map[string]any{
+		"uri":         s.uri(rel),
+		"diagnostics": diagnostics,
+	}})
+}
+
+// nodeAt returns the innermost function of the document rel spanning the
+// 1-based line.
+func (s *lspServer) nodeAt(rel string, line int) *goanalyzer.Node {
+	var best *goanalyzer.Node
+	for i := range s.graph.Nodes {
+		n := &s.graph.Nodes[i]
+		if n.FilePath != rel || line < n.StartLine || line > n.EndLine {
+			continue
+		}
+		if best == nil || n.EndLine-n.StartLine < best.EndLine-best.StartLine {
+			best = n
+		}
+	}
+	return best
+}
+
+// calls answers incomingCalls (the callers of id, with the call sites in
+// each) or outgoingCalls (its callees, with the call sites in id). Nodes
+// outside the project's files have no document and are left out.
+func (s *lspServer) calls(id string, incoming bool) []callHierarchyCall {
+	edges := s.graph.Callees(id)
+	if incoming {
+		edges = s.graph.Callers(id)
+	}
+	calls := []callHierarchyCall{}
+	index := make(map[string]int)
+	for _, e := range edges {
+		other := e.Target
+		if incoming {
+			other = e.Source
+		}
+		i, ok := index[other]
+		if !ok {
+			n, err := s.graph.FindNode(other)
+			if err != nil || !strings.HasSuffix(n.FilePath, ".go") || n.Dependency {
+				continue
+			}
+			item := s.item(n)
+			call := callHierarchyCall{FromRanges: []lspRange{}}
+			if incoming {
+				call.From = &item
+			} else {
+				call.To = &item
+			}
+			i = len(calls)
+			index[other] = i
+			calls = append(calls, call)
+		}
+		if e.CallSite.Line > 0 {
+			pos := lspPosition{Line: e.CallSite.Line - 1, Character: max(e.CallSite.Column-1, 0)}
+			calls[i].FromRanges = append(calls[i].FromRanges, lspRange{Start: pos, End: pos})
+		}
+	}
+	return calls
+}
+
+func (s *lspServer) item(n *goanalyzer.Node) callHierarchyItem {
+	kind := lspSymbolFunction
+	if n.Kind == "method" {
+		kind = lspSymbolMethod
+	}
+	r := nodeRange(n)
+	return callHierarchyItem{
+		Name:           n.Name,
+		Kind:           kind,
+		Detail:         n.PackageOrModule,
+		URI:            s.uri(n.FilePath),
+		Range:          r,
+		SelectionRange: lspRange{Start: r.Start, End: r.Start},
+		Data:           n.ID,
+	}
+}
+
+// nodeRange spans the lines of a function.
+func nodeRange(n *goanalyzer.Node) lspRange {
+	return lspRange{
+		Start: lspPosition{Line: max(n.StartLine-1, 0)},
+		End:   lspPosition{Line: max(n.EndLine, n.StartLine)},
+	}
+}
+
+// rel converts a file URI to a path relative to the project root.
+func (s *lspServer) rel(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("%s: not a file URI", uri)
+	}
+	rel, err := filepath.Rel(s.root, filepath.FromSlash(u.Path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project", uri)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (s *lspServer) uri(rel string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(s.root, rel))}).String()
+}
@@ -0,0 +1,51 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// TestLSPEditKeepsVarInitializers edits a file whose package-level var
+// initializer is the only caller of its functions.
+func TestLSPEditKeepsVarInitializers(t *testing.T) {
+	root := t.TempDir()
+	src := "package main\n\nvar handler = build()\n\nfunc build() func() { return helper }\n\nfunc helper() {}\n\nfunc main() { handler() }\n"
+	for name, content := range map[string]string{
+		"go.mod":  "module example.com/lsp\n\ngo 1.24\n",
+		"main.go": src,
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var log bytes.Buffer
+	s := &lspServer{cfg: &cliConfig{}, out: io.Discard, log: &log, cache: goanalyzer.NewLoadCache(), open: make(map[string]string)}
+	if _, err := s.initialize("", root); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.edited(s.uri("main.go"), src+"\n"); err != nil {
+		t.Fatal(err)
+	}
+	if log.Len() > 0 {
+		t.Errorf("edit logged %q, want only functions re-analyzed", log.String())
+	}
+	status := make(map[string]string)
+	for _, n := range s.graph.Nodes {
+		status[n.ID] = n.Status
+	}
+	if _, ok := status["main.go:__var_init__"]; !ok {
+		t.Error("main.go:__var_init__ dropped by the edit")
+	}
+	for _, id := range []string{"main.go:build", "main.go:helper"} {
+		if status[id] != "live" {
+			t.Errorf("%s is %q after the edit, want live", id, status[id])
+		}
+	}
+}
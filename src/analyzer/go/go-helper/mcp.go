@@ -0,0 +1,266 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// mcpProtocolVersion is the Model Context Protocol revision served.
+const mcpProtocolVersion = "2025-06-18"
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool describes a tool in the tools/list reply.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// mcpArgs are the arguments of every tool; each uses some of them.
+type mcpArgs struct {
+	Function string `json:"function"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Package  string `json:"package"`
+}
+
+func stringProps(names ...string) map[string]any {
+	props := make(map[string]any)
+	for _, name := range names {
+		props[name] = map[string]any{"type": "string"}
+	}
+	return props
+}
+
+const functionRef = "a node ID, linker symbol (pkg/path.(*T).Method) or unambiguous function name"
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "get_callers",
+		Description: "List the functions that call a function, with the call sites. The function is " + functionRef + ".",
+		InputSchema: map[string]any{"type": "object", "properties": stringProps("function"), "required": []string{"function"}},
+	},
+	{
+		Name:        "get_callees",
+		Description: "List the functions a function calls, with the call sites. The function is " + functionRef + ".",
+		InputSchema: map[string]any{"type": "object", "properties": stringProps("function"), "required": []string{"function"}},
+	},
+	{
+		Name:        "find_dead_code",
+		Description: "List the functions unreachable from any entry point, optionally only those of a package and the packages below it, given by import path or directory relative to the project root.",
+		InputSchema: map[string]any{"type": "object", "properties": stringProps("package")},
+	},
+	{
+		Name:        "path_between",
+		Description: "Find a shortest chain of calls from one function to another. Each is " + functionRef + ".",
+		InputSchema: map[string]any{"type": "object", "properties": stringProps("from", "to"), "required": []string{"from", "to"}},
+	},
+}
+
+// mcpServer answers Model Context Protocol requests about the project
+// configured by the flags. Every tool call analyzes the project again, so
+// that the answers follow edits; the loaded packages are reused while the
+// project is unchanged (see goanalyzer.LoadCache).
+type mcpServer struct {
+	opts  goanalyzer.Options
+	cache *goanalyzer.LoadCache
+	log   io.Writer
+}
+
+// serveMCP runs the MCP server over stdio: newline-delimited JSON-RPC
+// messages on in, the replies on out.
+func serveMCP(opts goanalyzer.Options, in io.Reader, out, log io.Writer) error {
+	s := &mcpServer{opts: opts, cache: goanalyzer.NewLoadCache(), log: log}
+	r := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := enc.Encode(resp); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers one message; notifications, which have no ID, get no
+// reply.
+func (s *mcpServer) handle(line []byte) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}
+	}
+	if req.ID == nil {
+		return nil
+	}
+	resp := &rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	switch req.Method {
+	case "initialize":
+		resp.Result = map[string]any{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "codegraph-go", "version": version},
+		}
+	case "ping":
+		resp.Result = map[string]any{}
+	case "tools/list":
+		resp.Result = map[string]any{"tools": mcpTools}
+	case "tools/call":
+		var params struct {
+			Name      string  `json:"name"`
+			Arguments mcpArgs `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+			break
+		}
+		resp.Result = s.call(params.Name, params.Arguments)
+	default:
+		resp.Error = &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method}
+	}
+	return resp
+}
+
+// call runs a tool. Its failures, such as an unknown function, are tool
+// results flagged isError, which the model gets to see, not protocol
+// errors.
+func (s *mcpServer) call(name string, args mcpArgs) map[string]any {
+	text, err := s.tool(name, args)
+	if err != nil {
+		return map[string]any{"content": []map[string]any{{"type": "text", "text": err.Error()}}, "isError": true}
+	}
+	return map[string]any{"content": []map[string]any{{"type": "text", "text": text}}}
+}
+
+// mcpCall is an edge as reported to the client, from the side of the
+// function asked about.
+type mcpCall struct {
+	Function string `json:"function"`
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	Kind     string `json:"kind"`
+}
+
+// inPackage reports whether n is declared in the package pkg, an import
+// path of module or a directory relative to the project root, or in a
+// package below it.
+func inPackage(n goanalyzer.Node, module, pkg string) bool {
+	if module != "" {
+		if pkg == module {
+			return true
+		}
+		pkg = strings.TrimPrefix(pkg, module+"/")
+	}
+	pkg = path.Clean(pkg)
+	dir := path.Dir(filepath.ToSlash(n.FilePath))
+	return pkg == "." || dir == pkg || strings.HasPrefix(dir, pkg+"/")
+}
+
+func (s *mcpServer) tool(name string, args mcpArgs) (string, error) {
+	switch name {
+	case "get_callers", "get_callees", "find_dead_code", "path_between":
+	default:
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	opts := s.opts
+	opts.Cache, opts.Log = s.cache, s.log
+	g, err := goanalyzer.Analyze(context.Background(), opts)
+	if err != nil {
+		return "", err
+	}
+
+	var result any
+	switch name {
+	case "get_callers", "get_callees":
+		n, err := g.FindNode(args.Function)
+		if err != nil {
+			return "", err
+		}
+		calls := []mcpCall{}
+		if name == "get_callers" {
+			for _, e := range g.Callers(n.ID) {
+				calls = append(calls, mcpCall{Function: e.Source, FilePath: e.CallSite.FilePath, Line: e.CallSite.Line, Kind: e.Kind})
+			}
+		} else {
+			for _, e := range g.Callees(n.ID) {
+				calls = append(calls, mcpCall{Function: e.Target, FilePath: e.CallSite.FilePath, Line: e.CallSite.Line, Kind: e.Kind})
+			}
+		}
+		result = map[string]any{"function": n.ID, strings.TrimPrefix(name, "get_"): calls}
+	case "find_dead_code":
+		type deadFunction struct {
+			Function  string `json:"function"`
+			FilePath  string `json:"filePath"`
+			StartLine int    `json:"startLine"`
+			Lines     int    `json:"linesOfCode"`
+		}
+		dead := []deadFunction{}
+		for _, n := range g.DeadFunctions() {
+			if args.Package != "" && !inPackage(n, s.opts.Module, args.Package) {
+				continue
+			}
+			dead = append(dead, deadFunction{Function: n.ID, FilePath: n.FilePath, StartLine: n.StartLine, Lines: n.LinesOfCode})
+		}
+		result = map[string]any{"dead": dead}
+	case "path_between":
+		from, err := g.FindNode(args.From)
+		if err != nil {
+			return "", err
+		}
+		to, err := g.FindNode(args.To)
+		if err != nil {
+			return "", err
+		}
+		path := g.PathBetween(from.ID, to.ID)
+		if path == nil {
+			return "", fmt.Errorf("%s does not reach %s", from.ID, to.ID)
+		}
+		result = map[string]any{"path": path}
+	}
+	data, err := json.Marshal(result)
+	return string(data), err
+}
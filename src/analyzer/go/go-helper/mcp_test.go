@@ -0,0 +1,30 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestInPackage(t *testing.T) {
+	const module = "example.com/mod"
+	for _, tc := range []struct {
+		file, pkg string
+		want      bool
+	}{
+		{"internal/foo/foo.go", "example.com/mod/internal", true},
+		{"internal/foo/foo.go", "internal", true},
+		{"internal/foo/foo.go", "internal/foo/", true},
+		{"internal/foobar/bar.go", "internal/foo", false},
+		{"internal/foobar/bar.go", "example.com/mod/internal/foo", false},
+		{"main.go", "example.com/mod", true},
+		{"main.go", "internal", false},
+		{"cmd/tool/main.go", ".", true},
+	} {
+		if got := inPackage(goanalyzer.Node{FilePath: tc.file}, module, tc.pkg); got != tc.want {
+			t.Errorf("inPackage(%s, %q) = %v, want %v", tc.file, tc.pkg, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// AllocHotspot is a pattern inside a loop known to allocate on every
+// iteration.
+type AllocHotspot struct {
+	// Kind is "stringConcatInLoop" (s += ...), "sprintfInLoop"
+	// (fmt.Sprintf and friends) or "appendWithoutPrealloc" (append to a
+	// slice declared in the function without a capacity).
+	Kind string `json:"kind"`
+	// Name is the variable concatenated or appended to, or the fmt
+	// function called.
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// collectAllocHotspots returns the allocation-heavy patterns in the loops
+// of body. Without type information, a concatenation is recognized by a
+// string literal or fmt.Sprint call on its right-hand side.
+func collectAllocHotspots(body *ast.BlockStmt, ctx *passContext) []AllocHotspot {
+	// Slices declared in the function, and whether with a capacity.
+	preallocated := make(map[string]bool)
+	var loops []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ForStmt:
+			loops = append(loops, x.Body)
+		case *ast.RangeStmt:
+			loops = append(loops, x.Body)
+		case *ast.AssignStmt:
+			if x.Tok == token.DEFINE && len(x.Lhs) == len(x.Rhs) {
+				for i, lhs := range x.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						if prealloc, isSlice := sliceAllocation(x.Rhs[i], ctx); isSlice {
+							preallocated[id.Name] = prealloc
+						}
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if at, ok := x.Type.(*ast.ArrayType); ok && at.Len == nil && len(x.Values) == 0 {
+				for _, id := range x.Names {
+					preallocated[id.Name] = false
+				}
+			}
+		}
+		return true
+	})
+	if len(loops) == 0 {
+		return nil
+	}
+	inLoop := func(n ast.Node) bool {
+		for _, l := range loops {
+			if n.Pos() >= l.Pos() && n.End() <= l.End() {
+				return true
+			}
+		}
+		return false
+	}
+
+	var hotspots []AllocHotspot
+	add := func(kind, name string, at ast.Node) {
+		hotspots = append(hotspots, AllocHotspot{Kind: kind, Name: name, Line: ctx.fset.Position(at.Pos()).Line})
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if !inLoop(x) || len(x.Lhs) != 1 || len(x.Rhs) != 1 {
+				return true
+			}
+			lhs := exprText(x.Lhs[0])
+			if lhs == "" {
+				return true
+			}
+			switch {
+			case x.Tok == token.ADD_ASSIGN && isStringConcat(x.Lhs[0], x.Rhs[0], ctx):
+				add("stringConcatInLoop", lhs, x)
+			case x.Tok == token.ASSIGN:
+				rhs := ast.Unparen(x.Rhs[0])
+				if first := firstOperand(rhs); first != rhs && exprText(first) == lhs && isStringConcat(first, rhs, ctx) {
+					add("stringConcatInLoop", lhs, x)
+				}
+				if call, ok := rhs.(*ast.CallExpr); ok && isBuiltinAppend(call, ctx) && len(call.Args) > 0 && exprText(call.Args[0]) == lhs {
+					if prealloc, declared := preallocated[lhs]; declared && !prealloc {
+						add("appendWithoutPrealloc", lhs, x)
+					}
+				}
+			}
+		case *ast.CallExpr:
+			if !inLoop(x) {
+				return true
+			}
+			if t, ok := ctx.resolveCall(x); ok && t.pkgPath == "fmt" && strings.HasPrefix(t.name, "Sprint") {
+				add("sprintfInLoop", "fmt."+t.name, x)
+			}
+		}
+		return true
+	})
+	return hotspots
+}
+
+// sliceAllocation reports whether e creates a slice, and whether with a
+// capacity: []T{} and make([]T, 0) have none, make([]T, 0, n) has one, and
+// so does make([]T, n) with a non-zero length.
+func sliceAllocation(e ast.Expr, ctx *passContext) (prealloc, isSlice bool) {
+	switch x := ast.Unparen(e).(type) {
+	case *ast.CompositeLit:
+		if at, ok := x.Type.(*ast.ArrayType); ok && at.Len == nil {
+			return false, true
+		}
+	case *ast.CallExpr:
+		if id, ok := x.Fun.(*ast.Ident); !ok || id.Name != "make" || len(x.Args) < 2 {
+			return false, false
+		}
+		if at, ok := x.Args[0].(*ast.ArrayType); !ok || at.Len != nil {
+			return false, false
+		}
+		if len(x.Args) > 2 {
+			return true, true
+		}
+		lit, ok := x.Args[1].(*ast.BasicLit)
+		return !ok || lit.Value != "0", true
+	}
+	return false, false
+}
+
+// isStringConcat reports whether lhs + rhs concatenates strings.
+func isStringConcat(lhs, rhs ast.Expr, ctx *passContext) bool {
+	if ctx.info != nil {
+		t := ctx.info.TypeOf(lhs)
+		if t == nil {
+			return false
+		}
+		b, ok := t.Underlying().(*types.Basic)
+		return ok && b.Info()&types.IsString != 0
+	}
+	switch x := ast.Unparen(rhs).(type) {
+	case *ast.BasicLit:
+		return x.Kind == token.STRING
+	case *ast.CallExpr:
+		t, ok := ctx.resolveCall(x)
+		return ok && t.pkgPath == "fmt" && strings.HasPrefix(t.name, "Sprint")
+	case *ast.BinaryExpr:
+		return x.Op == token.ADD && (isStringConcat(lhs, x.X, ctx) || isStringConcat(lhs, x.Y, ctx))
+	}
+	return false
+}
+
+// firstOperand returns the leftmost operand of a chain of additions, or e
+// itself.
+func firstOperand(e ast.Expr) ast.Expr {
+	for {
+		bin, ok := ast.Unparen(e).(*ast.BinaryExpr)
+		if !ok || bin.Op != token.ADD {
+			return e
+		}
+		e = bin.X
+	}
+}
+
+func isBuiltinAppend(call *ast.CallExpr, ctx *passContext) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || id.Name != "append" {
+		return false
+	}
+	if ctx.info != nil {
+		_, builtin := ctx.info.Uses[id].(*types.Builtin)
+		return builtin
+	}
+	return true
+}
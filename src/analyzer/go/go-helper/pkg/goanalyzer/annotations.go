@@ -0,0 +1,108 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// Annotation is a TODO/FIXME/HACK comment inside a function body.
+type Annotation struct {
+	Kind string `json:"kind"`
+	// Owner is the name in "TODO(owner): ...", if given.
+	Owner string `json:"owner,omitempty"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+}
+
+var annotationPattern = regexp.MustCompile(`\b(TODO|FIXME|HACK)\b(?:\(([^)]*)\))?:?\s*(.*)`)
+
+// collectAnnotations returns the tech-debt markers in comments between the
+// braces of body.
+func collectAnnotations(body *ast.BlockStmt, ctx *passContext) []Annotation {
+	var annotations []Annotation
+	for _, group := range ctx.file.Comments {
+		if group.Pos() < body.Lbrace || group.End() > body.Rbrace {
+			continue
+		}
+		for _, c := range group.List {
+			text := strings.TrimPrefix(strings.TrimPrefix(c.Text, "//"), "/*")
+			text = strings.TrimSuffix(text, "*/")
+			lineOffset := 0
+			for _, line := range strings.Split(text, "\n") {
+				if m := annotationPattern.FindStringSubmatch(line); m != nil {
+					annotations = append(annotations, Annotation{
+						Kind:  m[1],
+						Owner: m[2],
+						Text:  strings.TrimSpace(m[3]),
+						Line:  ctx.fset.Position(c.Pos()).Line + lineOffset,
+					})
+				}
+				lineOffset++
+			}
+		}
+	}
+	return annotations
+}
+
+// panicsOnError reports whether body contains the must-style pattern
+//
+//	if err != nil {
+//		panic(err)
+//	}
+//
+// i.e. an error is converted into a panic instead of being returned.
+func panicsOnError(body *ast.BlockStmt, ctx *passContext) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok {
+			return true
+		}
+		cond, ok := ast.Unparen(ifStmt.Cond).(*ast.BinaryExpr)
+		if !ok || cond.Op != token.NEQ || !isNilIdent(cond.Y) || !isErrorExpr(cond.X, ctx) {
+			return true
+		}
+		for _, stmt := range ifStmt.Body.List {
+			expr, ok := stmt.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			call, ok := expr.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && ident.Name == "panic" {
+				if ctx.info == nil || ctx.info.Uses[ident] == types.Universe.Lookup("panic") {
+					found = true
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+func isNilIdent(e ast.Expr) bool {
+	ident, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+var errorInterface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// isErrorExpr reports whether e has an error type, or without type
+// information, whether it is named like an error variable.
+func isErrorExpr(e ast.Expr, ctx *passContext) bool {
+	if ctx.info != nil {
+		t := ctx.info.TypeOf(e)
+		return t != nil && types.Implements(t, errorInterface)
+	}
+	name := bindingName(e)
+	return name == "err" || strings.HasSuffix(name, "Err") || strings.HasSuffix(name, "err")
+}
@@ -0,0 +1,84 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+)
+
+// Policies for Options.AnyDispatch.
+const (
+	// AnyDispatchIgnore adds no edges for a function returning an empty
+	// interface.
+	AnyDispatchIgnore = "ignore"
+	// AnyDispatchFanOut treats the result like any other interface, with
+	// edges to the methods of every project type, unless there are more
+	// than Options.AnyDispatchLimit of them.
+	AnyDispatchFanOut = "fanout-limited"
+	// AnyDispatchUnresolved adds a single unresolved edge to anyNodeID.
+	AnyDispatchUnresolved = "unresolved-edge"
+)
+
+// defaultAnyDispatchLimit is Options.AnyDispatchLimit when unset.
+const defaultAnyDispatchLimit = 20
+
+// anyNodeID is the synthetic node standing for whatever an any-typed
+// result turns out to be (AnyDispatchUnresolved).
+const anyNodeID = "external:any"
+
+// checkAnyDispatch reports an unknown Options.AnyDispatch policy.
+func (o Options) checkAnyDispatch() error {
+	switch o.AnyDispatch {
+	case "", AnyDispatchIgnore, AnyDispatchFanOut, AnyDispatchUnresolved:
+		return nil
+	}
+	return fmt.Errorf("unknown anyDispatch %q (want %q, %q or %q)", o.AnyDispatch, AnyDispatchIgnore, AnyDispatchFanOut, AnyDispatchUnresolved)
+}
+
+// isEmptyInterface reports whether t is any, interface{} or a named type of
+// either. Type parameters are not: their constraint is not their type.
+func isEmptyInterface(t types.Type) bool {
+	if _, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return false
+	}
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// anyDispatch applies Options.AnyDispatch to fn, a function returning an
+// empty interface, which every project type implements. A fan-out over the
+// limit adds no edges and returns a diagnostic at pos instead; the
+// unresolved-edge policy reports whether anyNodeID is needed.
+func anyDispatch(input Options, sourceID string, fn *types.Func, pos token.Position, relPath string, concreteTypes []*types.Named, large *largeTypes,
+	objToNodeID map[types.Object]string, prov Provenance, edges *[]Edge) (diag *Diagnostic, useAnyNode bool) {
+	switch input.AnyDispatch {
+	case AnyDispatchFanOut:
+		var fanout []Edge
+		for _, ct := range concreteTypes {
+			addMethodEdgesForType(sourceID, ct, large, objToNodeID, prov, &fanout)
+		}
+		limit := input.AnyDispatchLimit
+		if limit <= 0 {
+			limit = defaultAnyDispatchLimit
+		}
+		if len(fanout) > limit {
+			return &Diagnostic{
+				Kind:     "anyDispatch",
+				FilePath: relPath,
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Message:  fmt.Sprintf("%s returns an empty interface that %d project methods could stand behind, more than the limit of %d; no edges added", fn.Name(), len(fanout), limit),
+			}, false
+		}
+		*edges = append(*edges, fanout...)
+	case AnyDispatchUnresolved:
+		*edges = append(*edges, Edge{
+			Source:     sourceID,
+			Target:     anyNodeID,
+			Kind:       "provided",
+			Provenance: prov,
+		})
+		return nil, true
+	}
+	return nil, false
+}
@@ -0,0 +1,147 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// analyzeAPISurface lists the public API of the packages in input.Files
+// (Options.APISurface): exported functions, methods of exported types and
+// exported types, with their signatures and doc summaries, and no edges.
+// Only syntax is used; main packages and tests, which cannot be imported,
+// are skipped. Files present in sources are parsed from memory.
+func analyzeAPISurface(input Options, sources map[string][]byte, stats *Stats) Graph {
+	t := time.Now()
+	fset := token.NewFileSet()
+	nodes := []Node{}
+	for _, filePath := range input.Files {
+		if strings.HasSuffix(filePath, "_test.go") {
+			continue
+		}
+		src, ok := sources[filePath]
+		if !ok {
+			src, ok = sources[cleanRel(filePath)]
+		}
+		absPath := filepath.Join(input.ProjectRoot, filePath)
+		if !ok {
+			var err error
+			if src, err = os.ReadFile(absPath); err != nil {
+				continue
+			}
+		}
+		f, err := parser.ParseFile(fset, absPath, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil || f.Name.Name == "main" {
+			continue
+		}
+		stats.FilesParsed++
+		nodes = append(nodes, apiNodes(f, fset, filePath, input.Module)...)
+	}
+	stats.Algorithm = AlgorithmAST
+	stats.phase("api", t)
+	return Graph{Nodes: nodes, Edges: []Edge{}}
+}
+
+// apiNodes returns the exported declarations of f. Functions and methods
+// come from the node builder, with liveness fields cleared.
+func apiNodes(f *ast.File, fset *token.FileSet, filePath, module string) []Node {
+	pkgPath := module
+	if dir := filepath.ToSlash(filepath.Dir(filePath)); dir != "." {
+		pkgPath = module + "/" + dir
+	}
+	funcs := extractNodes(f, fset, filePath, f.Name.Name, pkgPath)
+	var out []Node
+	i := 0
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			n := funcs[i]
+			i++
+			if !ast.IsExported(n.Name) || decl.Recv != nil && !ast.IsExported(getReceiverTypeName(decl.Recv.List[0].Type)) {
+				continue
+			}
+			sig := *decl
+			sig.Doc, sig.Body = nil, nil
+			n.Signature = printSignature(fset, &sig)
+			n.Doc = docSummary(decl.Doc)
+			n.IsEntryPoint, n.UnusedParameters, n.Status, n.Color, n.KeptBy = false, []string{}, "", "", ""
+			out = append(out, n)
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !ast.IsExported(ts.Name.Name) {
+					continue
+				}
+				out = append(out, typeNode(ts, decl, fset, filePath, f.Name.Name, pkgPath))
+			}
+		}
+	}
+	return out
+}
+
+// typeNode builds the node of an exported type. Struct and interface
+// bodies are left out of its signature.
+func typeNode(ts *ast.TypeSpec, decl *ast.GenDecl, fset *token.FileSet, filePath, pkgName, pkgPath string) Node {
+	sig := *ts
+	sig.Doc, sig.Comment = nil, nil
+	switch ts.Type.(type) {
+	case *ast.StructType:
+		sig.Type = &ast.Ident{Name: "struct"}
+	case *ast.InterfaceType:
+		sig.Type = &ast.Ident{Name: "interface"}
+	}
+	doc := ts.Doc
+	if doc == nil && len(decl.Specs) == 1 {
+		doc = decl.Doc
+	}
+	start, end := fset.Position(ts.Pos()), fset.Position(ts.End())
+	pkg := filepath.Dir(filePath)
+	if pkg == "." {
+		pkg = pkgName
+	}
+	return Node{
+		ID:               filePath + ":" + ts.Name.Name,
+		Name:             ts.Name.Name,
+		QualifiedName:    filePath + ":" + ts.Name.Name,
+		FilePath:         filePath,
+		StartLine:        start.Line,
+		EndLine:          end.Line,
+		Language:         "go",
+		Kind:             "type",
+		Visibility:       "exported",
+		Parameters:       []Parameter{},
+		UnusedParameters: []string{},
+		PackageOrModule:  pkg,
+		LinesOfCode:      end.Line - start.Line + 1,
+		SymbolID:         pkgPath + "." + ts.Name.Name,
+		Signature:        "type " + printSignature(fset, &sig),
+		Doc:              docSummary(doc),
+	}
+}
+
+// printSignature prints a declaration on one line.
+func printSignature(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// docSummary is the first sentence of a doc comment.
+func docSummary(c *ast.CommentGroup) string {
+	if c == nil {
+		return ""
+	}
+	return new(doc.Package).Synopsis(c.Text())
+}
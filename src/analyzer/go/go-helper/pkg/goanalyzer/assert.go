@@ -0,0 +1,71 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// assertedVars maps the variables of body bound by a type assertion to the
+// asserted types: v in "v := x.(T)" or "v, ok := x.(T)" to T, and the v of
+// each clause of "switch v := x.(type)" to the types the clause lists.
+func assertedVars(body *ast.BlockStmt, info *types.Info) map[types.Object][]types.Type {
+	vars := make(map[types.Object][]types.Type)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE || len(s.Rhs) != 1 {
+				return true
+			}
+			assert, ok := ast.Unparen(s.Rhs[0]).(*ast.TypeAssertExpr)
+			if !ok || assert.Type == nil {
+				return true
+			}
+			if id, ok := s.Lhs[0].(*ast.Ident); ok && info.Defs[id] != nil {
+				vars[info.Defs[id]] = []types.Type{info.TypeOf(assert.Type)}
+			}
+		case *ast.TypeSwitchStmt:
+			if _, ok := s.Assign.(*ast.AssignStmt); !ok {
+				return true
+			}
+			for _, stmt := range s.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				obj := info.Implicits[clause]
+				if obj == nil || len(clause.List) == 0 {
+					continue
+				}
+				var asserted []types.Type
+				for _, e := range clause.List {
+					if t := info.TypeOf(e); t != nil && t != types.Typ[types.UntypedNil] {
+						asserted = append(asserted, t)
+					}
+				}
+				vars[obj] = asserted
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// assertedTypes returns the concrete types a method receiver is known to
+// have from a type assertion: x.(T) itself, or a variable bound by one. It
+// returns nil when some asserted type is an interface, whose
+// implementations are left to interface dispatch.
+func assertedTypes(recv ast.Expr, info *types.Info, vars map[types.Object][]types.Type) []types.Type {
+	var asserted []types.Type
+	switch e := ast.Unparen(recv).(type) {
+	case *ast.TypeAssertExpr:
+		if e.Type != nil {
+			asserted = []types.Type{info.TypeOf(e.Type)}
+		}
+	case *ast.Ident:
+		asserted = vars[info.Uses[e]]
+	}
+	for _, t := range asserted {
+		if t == nil || types.IsInterface(t) {
+			return nil
+		}
+	}
+	return asserted
+}
@@ -0,0 +1,136 @@
+package goanalyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// Baseline is the file named by Options.Baseline: dead functions that have
+// been acknowledged and should not be reported again.
+type Baseline struct {
+	DeadFunctions []BaselineEntry `json:"deadFunctions"`
+}
+
+// BaselineEntry identifies a function by SymbolID, which survives moving it
+// to another file of the same package, and by node ID for functions without
+// one (and for readers of the file).
+type BaselineEntry struct {
+	ID       string `json:"id"`
+	SymbolID string `json:"symbolId,omitempty"`
+}
+
+// DeadCodeReport compares the dead functions of a run with Options.Baseline.
+type DeadCodeReport struct {
+	// New are the node IDs of dead functions missing from the baseline.
+	New []string `json:"new"`
+	// Baselined counts the dead functions listed in the baseline.
+	Baselined int `json:"baselined"`
+	// Resolved lists baseline entries that are no longer dead functions
+	// (deleted, or now reached); regenerating the baseline drops them.
+	Resolved []BaselineEntry `json:"resolved,omitempty"`
+	// Updated is set when the run rewrote the baseline
+	// (Options.UpdateBaseline), in which case nothing is new.
+	Updated bool `json:"updated,omitempty"`
+}
+
+// deadFunctions returns the functions and methods markLiveness left dead,
+// sorted by ID.
+func deadFunctions(g *Graph) []Node {
+	var dead []Node
+	for _, n := range g.Nodes {
+		if n.Status == "dead" && (n.Kind == "function" || n.Kind == "method") {
+			dead = append(dead, n)
+		}
+	}
+	sort.Slice(dead, func(i, j int) bool { return dead[i].ID < dead[j].ID })
+	return dead
+}
+
+// loadBaseline reads a baseline file; a missing file is an empty baseline,
+// so that a first run with UpdateBaseline can create it.
+func loadBaseline(path string) (Baseline, error) {
+	var b Baseline
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+	err = json.Unmarshal(data, &b)
+	return b, err
+}
+
+func writeBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// compareBaseline splits the graph's dead functions into those acknowledged
+// by b and new ones.
+func compareBaseline(g *Graph, b Baseline) *DeadCodeReport {
+	bySymbol := make(map[string]int)
+	byID := make(map[string]int)
+	for i, e := range b.DeadFunctions {
+		if e.SymbolID != "" {
+			bySymbol[e.SymbolID] = i
+		}
+		byID[e.ID] = i
+	}
+	report := &DeadCodeReport{New: []string{}}
+	matched := make(map[int]bool)
+	for _, n := range deadFunctions(g) {
+		i, ok := bySymbol[n.SymbolID]
+		if !ok || n.SymbolID == "" {
+			i, ok = byID[n.ID]
+		}
+		if !ok {
+			report.New = append(report.New, n.ID)
+			continue
+		}
+		report.Baselined++
+		matched[i] = true
+	}
+	for i, e := range b.DeadFunctions {
+		if !matched[i] {
+			report.Resolved = append(report.Resolved, e)
+		}
+	}
+	return report
+}
+
+// newBaseline acknowledges every dead function of the graph.
+func newBaseline(g *Graph) Baseline {
+	b := Baseline{DeadFunctions: []BaselineEntry{}}
+	for _, n := range deadFunctions(g) {
+		b.DeadFunctions = append(b.DeadFunctions, BaselineEntry{ID: n.ID, SymbolID: n.SymbolID})
+	}
+	return b
+}
+
+// applyBaseline sets Graph.DeadCode from the baseline file, first rewriting
+// it with the current dead functions if input.UpdateBaseline is set.
+func applyBaseline(g *Graph, input Options) error {
+	path := resolvePath(input.ProjectRoot, input.Baseline)
+	if input.UpdateBaseline {
+		b := newBaseline(g)
+		if err := writeBaseline(path, b); err != nil {
+			return err
+		}
+		g.DeadCode = compareBaseline(g, b)
+		g.DeadCode.Updated = true
+		return nil
+	}
+	b, err := loadBaseline(path)
+	if err != nil {
+		return err
+	}
+	g.DeadCode = compareBaseline(g, b)
+	return nil
+}
@@ -0,0 +1,101 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// BenchmarkInterfaceDispatch analyzes a generated project of many small
+// interfaces and many types implementing some of them, where interface
+// matching dominates. Compare GOMAXPROCS settings to see the sharding pay
+// off:
+//
+//	go test ./pkg/goanalyzer -run '^$' -bench InterfaceDispatch -cpu 1,4
+func BenchmarkInterfaceDispatch(b *testing.B) {
+	requireTypedAnalysis(b)
+	const ifaces, types = 100, 1000
+	root := b.TempDir()
+	writeFile(b, root, "go.mod", "module example.com/bench\n\ngo 1.24\n")
+
+	var src strings.Builder
+	src.WriteString("package main\n\n")
+	for i := range ifaces {
+		fmt.Fprintf(&src, "type I%d interface{ M%d(); M%d() }\n\n", i, i, (i+1)%ifaces)
+		fmt.Fprintf(&src, "func NewI%d() I%d { return nil }\n\n", i, i)
+		fmt.Fprintf(&src, "func call%d(v I%d) { v.M%d() }\n\n", i, i, i)
+	}
+	for i := range types {
+		fmt.Fprintf(&src, "type T%d struct{}\n\n", i)
+		for m := i % ifaces; m < i%ifaces+3; m++ {
+			fmt.Fprintf(&src, "func (*T%d) M%d() {}\n", i, m%ifaces)
+		}
+		src.WriteString("\n")
+	}
+	src.WriteString("func main() {}\n")
+	writeFile(b, root, "main.go", src.String())
+
+	b.ResetTimer()
+	for range b.N {
+		graph, err := goanalyzer.Analyze(context.Background(), goanalyzer.Options{ProjectRoot: root, Module: "example.com/bench"})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if graph.Stats.Algorithm != goanalyzer.AlgorithmTypes {
+			b.Skip("type-aware analysis unavailable in this environment")
+		}
+	}
+}
+
+// TestInterfaceDispatchConcurrent checks interfaces in shards, as
+// BenchmarkInterfaceDispatch does, over interfaces that embed others, take
+// interface literals and are embedded in the implementing structs, which
+// go/types completes lazily. Run it with the race detector:
+//
+//	go test -race ./pkg/goanalyzer -run InterfaceDispatchConcurrent
+func TestInterfaceDispatchConcurrent(t *testing.T) {
+	requireTypedAnalysis(t)
+	// Enough types for several shards of implementers.of.
+	const ifaces, types = 10, 400
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example.com/race\n\ngo 1.24\n")
+
+	var src strings.Builder
+	src.WriteString("package main\n\n")
+	for i := range ifaces {
+		fmt.Fprintf(&src, "type Base%d interface{ M%d() }\n\n", i, i)
+		fmt.Fprintf(&src, "type I%d interface {\n\tBase%d\n\tN%d(v interface{ M%d() }) Base%d\n}\n\n", i, i, i, i, i)
+		fmt.Fprintf(&src, "func call%d(v I%d) { v.N%d(nil) }\n\n", i, i, i)
+	}
+	for i := range types {
+		fmt.Fprintf(&src, "type T%d struct{ Base%d }\n\n", i, i%ifaces)
+		fmt.Fprintf(&src, "func (*T%d) N%d(v interface{ M%d() }) Base%d { return nil }\n\n", i, i%ifaces, i%ifaces, i%ifaces)
+	}
+	src.WriteString("func main() {}\n")
+	writeFile(t, root, "main.go", src.String())
+
+	graph, err := goanalyzer.Analyze(context.Background(), goanalyzer.Options{ProjectRoot: root, Module: "example.com/race"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Stats.Algorithm != goanalyzer.AlgorithmTypes {
+		t.Skip("type-aware analysis unavailable in this environment")
+	}
+	dispatched := make(map[string]int)
+	for _, e := range graph.Edges {
+		if strings.HasPrefix(e.Source, "main.go:call") {
+			dispatched[e.Source]++
+		}
+	}
+	for i := range ifaces {
+		source := fmt.Sprintf("main.go:call%d", i)
+		if got, want := dispatched[source], types/ifaces; got != want {
+			t.Errorf("%s dispatches to %d implementations, want %d", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Budgets caps the wall-clock time of the slow phases of type-aware
+// analysis, in milliseconds; zero leaves a phase unbounded. A phase over
+// budget stops with what it has computed, a "truncated" diagnostic says
+// so, and the optional phases that would build on it (the deadparams pass
+// and references) are skipped:
+//
+//   - over LoadMs, package loading is abandoned and calls are resolved by
+//     name, as by the AST fallback;
+//   - over ResolveMs, counted from the end of loading, call resolution
+//     stops, leaving the functions not reached yet without outgoing calls.
+type Budgets struct {
+	LoadMs    int `json:"loadMs,omitempty"`
+	ResolveMs int `json:"resolveMs,omitempty"`
+}
+
+func budget(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// overBudget records that phase ran past its budget of ms milliseconds;
+// consequence says what the graph lacks as a result.
+func (s *Stats) overBudget(phase string, ms int, consequence string) {
+	s.truncated = append(s.truncated, Diagnostic{
+		Kind:    "truncated",
+		Message: fmt.Sprintf("%s exceeded its budget of %d ms; %s", phase, ms, consequence),
+	})
+}
@@ -0,0 +1,260 @@
+package goanalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// BuildInfo is how the shipped binary is built, as read from
+// Options.BuildInfo.
+type BuildInfo struct {
+	GOOS   string   `json:"goos,omitempty"`
+	GOARCH string   `json:"goarch,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	// Ldflags are the linker flags; their -X importpath.name=value
+	// settings become Graph.LinkerVars.
+	Ldflags string `json:"ldflags,omitempty"`
+}
+
+// LinkerVar is a package-level string variable set with -ldflags -X.
+type LinkerVar struct {
+	// Name is "importpath.name", as written after -X.
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// FilePath and Line locate the declaration; both are empty when no
+	// loaded package declares the variable.
+	FilePath string `json:"filePath,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	// ReadBy are the node IDs of the functions using the variable.
+	ReadBy []string `json:"readBy"`
+}
+
+// readBuildInfo reads a build description: a JSON BuildInfo when the file
+// ends in .json, or else a Dockerfile, Makefile or shell script, from whose
+// first "go build" or "go install" command the -tags and -ldflags flags and
+// the GOOS and GOARCH assignments are taken.
+func readBuildInfo(path string) (*BuildInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info := &BuildInfo{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, info); err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+	script := strings.ReplaceAll(string(data), "\\\n", " ")
+	for _, line := range strings.Split(script, "\n") {
+		words := shellWords(line)
+		for _, w := range words {
+			// ENV and ARG lines of a Dockerfile set the platform for
+			// the commands that follow.
+			if k, v, ok := strings.Cut(w, "="); ok && (k == "GOOS" || k == "GOARCH") {
+				info.setEnv(k, v)
+			}
+		}
+		for i := 0; i+1 < len(words); i++ {
+			if words[i] == "go" && (words[i+1] == "build" || words[i+1] == "install") {
+				info.parseFlags(words[i+2:])
+				return info, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: no go build or go install command", path)
+}
+
+func (b *BuildInfo) setEnv(key, value string) {
+	if key == "GOOS" {
+		b.GOOS = value
+	} else {
+		b.GOARCH = value
+	}
+}
+
+// parseFlags reads the -tags and -ldflags flags of a go build command, up
+// to the end of the command.
+func (b *BuildInfo) parseFlags(args []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "&&" || arg == ";" || arg == "||" || arg == "|" {
+			return
+		}
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "-"), "=")
+		if name != "tags" && name != "ldflags" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		if name == "tags" {
+			b.Tags = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+		} else {
+			b.Ldflags = value
+		}
+	}
+}
+
+// context is the build context the packages are loaded under.
+func (b *BuildInfo) context() *BuildContext {
+	return &BuildContext{GOOS: b.GOOS, GOARCH: b.GOARCH, Tags: b.Tags}
+}
+
+// linkerVars returns the -X settings of the linker flags, by name.
+func (b *BuildInfo) linkerVars() map[string]string {
+	vars := make(map[string]string)
+	words := shellWords(b.Ldflags)
+	for i := 0; i < len(words); i++ {
+		setting, ok := strings.CutPrefix(words[i], "-X=")
+		if !ok {
+			if words[i] != "-X" && words[i] != "--X" || i+1 == len(words) {
+				continue
+			}
+			i++
+			setting = words[i]
+		}
+		if name, value, ok := strings.Cut(setting, "="); ok {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// shellWords splits a command line into words as a POSIX shell would,
+// honoring quotes and backslashes, without expanding anything. A "#" at the
+// start of a word begins a comment.
+func shellWords(line string) []string {
+	var words []string
+	var w strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(line) {
+				i++
+				w.WriteByte(line[i])
+			} else {
+				w.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == '\\' && i+1 < len(line):
+			i++
+			w.WriteByte(line[i])
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\r':
+			if inWord {
+				words = append(words, w.String())
+				w.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			return words
+		default:
+			w.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, w.String())
+	}
+	return words
+}
+
+// withBuildInfo reads Options.BuildInfo and makes it the build context of
+// the analysis.
+func (o Options) withBuildInfo() (Options, error) {
+	if o.BuildInfo == "" {
+		return o, nil
+	}
+	if len(o.BuildMatrix) > 0 {
+		return o, fmt.Errorf("buildInfo and buildMatrix cannot be combined")
+	}
+	info, err := readBuildInfo(resolvePath(o.ProjectRoot, o.BuildInfo))
+	if err != nil {
+		return o, fmt.Errorf("buildInfo: %w", err)
+	}
+	o.buildInfo = info
+	o.build = info.context()
+	return o, nil
+}
+
+// findLinkerVars locates the variables set by the -X linker flags in the
+// project packages and the functions using them. A main package's
+// variables are named "main.name", as the linker expects.
+func findLinkerVars(settings map[string]string, pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, log func(string, ...any)) []LinkerVar {
+	if len(settings) == 0 {
+		return nil
+	}
+	vars := make(map[types.Object]*LinkerVar)
+	var result []*LinkerVar
+	for name, value := range settings {
+		v := &LinkerVar{Name: name, Value: value, ReadBy: []string{}}
+		result = append(result, v)
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			continue
+		}
+		pkgPath, varName := name[:dot], name[dot+1:]
+		for _, pkg := range pkgs {
+			if pkg.PkgPath != pkgPath && !(pkgPath == "main" && pkg.Name == "main") || pkg.Types == nil {
+				continue
+			}
+			obj, ok := pkg.Types.Scope().Lookup(varName).(*types.Var)
+			if !ok {
+				continue
+			}
+			pos := pkg.Fset.Position(obj.Pos())
+			if rel, err := paths.rel(pos.Filename); err == nil {
+				v.FilePath, v.Line = filepath.ToSlash(rel), pos.Line
+			}
+			vars[obj] = v
+		}
+		if v.FilePath == "" {
+			log("-X %s: no such package-level variable", name)
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+				id := objToNodeID[pkg.TypesInfo.Defs[funcDecl.Name]]
+				if id == "" {
+					continue
+				}
+				ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+					if ident, ok := n.(*ast.Ident); ok {
+						if v := vars[pkg.TypesInfo.Uses[ident]]; v != nil && !contains(v.ReadBy, id) {
+							v.ReadBy = append(v.ReadBy, id)
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	out := make([]LinkerVar, len(result))
+	for i, v := range result {
+		sort.Strings(v.ReadBy)
+		out[i] = *v
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package goanalyzer
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// BuildContext is one GOOS/GOARCH/build-tag combination of
+// Options.BuildMatrix.
+type BuildContext struct {
+	// Name labels the context in Node.BuildContexts and Edge.BuildContexts;
+	// it defaults to "GOOS/GOARCH,tag1,tag2" with the unset parts left out.
+	Name   string   `json:"name,omitempty"`
+	GOOS   string   `json:"goos,omitempty"`
+	GOARCH string   `json:"goarch,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+func (b BuildContext) name() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	platform := b.GOOS
+	if b.GOARCH != "" {
+		platform += "/" + b.GOARCH
+	}
+	var parts []string
+	if platform != "" {
+		parts = append(parts, platform)
+	}
+	parts = append(parts, b.Tags...)
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, ",")
+}
+
+// env returns the environment go list runs with under b, or nil for the
+// current one.
+func (b BuildContext) env() []string {
+	if b.GOOS == "" && b.GOARCH == "" {
+		return nil
+	}
+	env := os.Environ()
+	if b.GOOS != "" {
+		env = append(env, "GOOS="+b.GOOS)
+	}
+	if b.GOARCH != "" {
+		env = append(env, "GOARCH="+b.GOARCH)
+	}
+	return env
+}
+
+func (b BuildContext) buildFlags() []string {
+	if len(b.Tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(b.Tags, ",")}
+}
+
+// analyzeBuildMatrix runs the type-aware analysis once per context of
+// input.BuildMatrix and unites the graphs, so that code compiled only for
+// some platforms or tags is analyzed, and is live if any context reaches it.
+// Nodes and edges missing from some contexts list the ones they exist in.
+// The Stats counters add up over the contexts.
+func analyzeBuildMatrix(ctx context.Context, input Options, stats *Stats) (Graph, error) {
+	var graphs []Graph
+	for _, b := range input.BuildMatrix {
+		o := input
+		o.build = &b
+		g, err := analyzeWithTypes(ctx, o, stats)
+		if err != nil {
+			return Graph{}, err
+		}
+		graphs = append(graphs, g)
+	}
+	return uniteBuildContexts(input.BuildMatrix, graphs), nil
+}
+
+// uniteBuildContexts merges the graphs of the contexts, keeping the first
+// version of each node and edge.
+func uniteBuildContexts(contexts []BuildContext, graphs []Graph) Graph {
+	united := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	nodeIndex := make(map[string]int)
+	edgeIndex := make(map[edgeKey]int)
+	diagnostics := make(map[Diagnostic]bool)
+	routes := make(map[routeKey]bool)
+	for i, g := range graphs {
+		name := contexts[i].name()
+		for _, n := range g.Nodes {
+			j, ok := nodeIndex[n.ID]
+			if !ok {
+				j = len(united.Nodes)
+				nodeIndex[n.ID] = j
+				united.Nodes = append(united.Nodes, n)
+			}
+			united.Nodes[j].BuildContexts = append(united.Nodes[j].BuildContexts, name)
+		}
+		for _, e := range g.Edges {
+			key := keyOf(e)
+			j, ok := edgeIndex[key]
+			if !ok {
+				j = len(united.Edges)
+				edgeIndex[key] = j
+				united.Edges = append(united.Edges, e)
+			}
+			united.Edges[j].BuildContexts = append(united.Edges[j].BuildContexts, name)
+		}
+		for _, d := range g.Diagnostics {
+			if !diagnostics[d] {
+				diagnostics[d] = true
+				united.Diagnostics = append(united.Diagnostics, d)
+			}
+		}
+		for _, r := range g.Routes {
+			if key := r.key(); !routes[key] {
+				routes[key] = true
+				united.Routes = append(united.Routes, r)
+			}
+		}
+	}
+	// Whatever exists in every context needs no label.
+	for i := range united.Nodes {
+		if len(united.Nodes[i].BuildContexts) == len(graphs) {
+			united.Nodes[i].BuildContexts = nil
+		}
+	}
+	for i := range united.Edges {
+		if len(united.Edges[i].BuildContexts) == len(graphs) {
+			united.Edges[i].BuildContexts = nil
+		}
+	}
+	return united
+}
+
+// edgeKey identifies an edge by its call: the same call found twice, e.g.
+// in two shards or build contexts, has the same key.
+type edgeKey struct {
+	source, target, kind string
+	site                 CallSite
+}
+
+func keyOf(e Edge) edgeKey {
+	return edgeKey{e.Source, e.Target, e.Kind, e.CallSite}
+}
@@ -0,0 +1,92 @@
+package goanalyzer
+
+import "sort"
+
+// bundleSamples is how many representative calls a bundle keeps.
+const bundleSamples = 3
+
+// Bundle aggregates the calls from one package to another (Options.Bundles),
+// so a renderer can draw dense graphs package to package and expand a
+// bundle on demand.
+type Bundle struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Count is the number of call edges between the packages.
+	Count int `json:"count"`
+	// Samples are the most frequent caller → callee pairs of the bundle.
+	Samples []BundleSample `json:"samples"`
+}
+
+// BundleSample is a caller → callee pair with its number of call edges.
+type BundleSample struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Count  int    `json:"count"`
+}
+
+// buildBundles aggregates g's edges between different packages, heaviest
+// bundles first. Edges to or from unknown nodes are left out.
+func buildBundles(g *Graph) []Bundle {
+	pkgOf := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		pkgOf[n.ID] = n.PackageOrModule
+	}
+	type pair struct{ source, target string }
+	bundles := make(map[pair]*Bundle)
+	calls := make(map[pair]map[pair]int)
+	for _, e := range g.Edges {
+		from, ok1 := pkgOf[e.Source]
+		to, ok2 := pkgOf[e.Target]
+		if !ok1 || !ok2 || from == to {
+			continue
+		}
+		key := pair{from, to}
+		b := bundles[key]
+		if b == nil {
+			b = &Bundle{Source: from, Target: to}
+			bundles[key] = b
+			calls[key] = make(map[pair]int)
+		}
+		b.Count++
+		calls[key][pair{e.Source, e.Target}]++
+	}
+
+	out := make([]Bundle, 0, len(bundles))
+	for key, b := range bundles {
+		for c, n := range calls[key] {
+			b.Samples = append(b.Samples, BundleSample{Source: c.source, Target: c.target, Count: n})
+		}
+		sort.Slice(b.Samples, func(i, j int) bool {
+			x, y := b.Samples[i], b.Samples[j]
+			if x.Count != y.Count {
+				return x.Count > y.Count
+			}
+			if x.Source != y.Source {
+				return x.Source < y.Source
+			}
+			return x.Target < y.Target
+		})
+		if len(b.Samples) > bundleSamples {
+			b.Samples = b.Samples[:bundleSamples]
+		}
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		x, y := out[i], out[j]
+		if x.Count != y.Count {
+			return x.Count > y.Count
+		}
+		if x.Source != y.Source {
+			return x.Source < y.Source
+		}
+		return x.Target < y.Target
+	})
+	return out
+}
+
+// refreshBundles rebuilds the bundles if the graph has them.
+func (g *Graph) refreshBundles() {
+	if g.Bundles != nil {
+		g.Bundles = buildBundles(g)
+	}
+}
@@ -0,0 +1,89 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ChangeSet lists the record-level writes that turn a store holding one
+// graph into one holding another, for mirroring the graph into a database.
+// Unlike GraphDiff it does not follow moves: a moved node is deleted under
+// its old ID and upserted under its new one. Edges are keyed by source,
+// target and kind; when several share a key, the first stands for them.
+type ChangeSet struct {
+	UpsertNodes []Node    `json:"upsertNodes"`
+	DeleteNodes []string  `json:"deleteNodes"`
+	UpsertEdges []Edge    `json:"upsertEdges"`
+	DeleteEdges []EdgeKey `json:"deleteEdges"`
+}
+
+// EdgeKey identifies an edge in a ChangeSet.
+type EdgeKey struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+}
+
+// Len is the number of writes in the change set.
+func (c ChangeSet) Len() int {
+	return len(c.UpsertNodes) + len(c.DeleteNodes) + len(c.UpsertEdges) + len(c.DeleteEdges)
+}
+
+// Changes returns the writes from prev to cur: the nodes and edges that are
+// new or differ in any field, and the keys of those that are gone.
+func Changes(prev, cur Graph) ChangeSet {
+	c := ChangeSet{UpsertNodes: []Node{}, DeleteNodes: []string{}, UpsertEdges: []Edge{}, DeleteEdges: []EdgeKey{}}
+
+	prevNodes := make(map[string][]byte, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		prevNodes[n.ID] = marshalRecord(n)
+	}
+	curNodes := make(map[string]bool, len(cur.Nodes))
+	for _, n := range cur.Nodes {
+		curNodes[n.ID] = true
+		if old, ok := prevNodes[n.ID]; !ok || !bytes.Equal(old, marshalRecord(n)) {
+			c.UpsertNodes = append(c.UpsertNodes, n)
+		}
+	}
+	for _, n := range prev.Nodes {
+		if !curNodes[n.ID] {
+			c.DeleteNodes = append(c.DeleteNodes, n.ID)
+		}
+	}
+
+	prevEdges := make(map[EdgeKey][]byte, len(prev.Edges))
+	for _, e := range prev.Edges {
+		if k := changeKey(e); prevEdges[k] == nil {
+			prevEdges[k] = marshalRecord(e)
+		}
+	}
+	curEdges := make(map[EdgeKey]bool, len(cur.Edges))
+	for _, e := range cur.Edges {
+		k := changeKey(e)
+		if curEdges[k] {
+			continue
+		}
+		curEdges[k] = true
+		if old, ok := prevEdges[k]; !ok || !bytes.Equal(old, marshalRecord(e)) {
+			c.UpsertEdges = append(c.UpsertEdges, e)
+		}
+	}
+	for _, e := range prev.Edges {
+		if k := changeKey(e); !curEdges[k] {
+			curEdges[k] = true // report each key once
+			c.DeleteEdges = append(c.DeleteEdges, k)
+		}
+	}
+	return c
+}
+
+func changeKey(e Edge) EdgeKey {
+	return EdgeKey{Source: e.Source, Target: e.Target, Kind: e.Kind}
+}
+
+// marshalRecord encodes a node or edge for comparison; the types encode
+// without error.
+func marshalRecord(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,142 @@
+package goanalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ChunkManifest describes a graph split by WriteChunks, for consumers that
+// cannot hold the whole graph in memory.
+type ChunkManifest struct {
+	SchemaVersion string `json:"schemaVersion"`
+	// Dir is the absolute directory of the chunk files.
+	Dir        string      `json:"dir"`
+	TotalNodes int         `json:"totalNodes"`
+	TotalEdges int         `json:"totalEdges"`
+	Chunks     []ChunkInfo `json:"chunks"`
+	// CrossChunkEdges counts the edges from the nodes of one chunk to those
+	// of another, so that a consumer knows which chunks a chunk's edges
+	// lead into.
+	CrossChunkEdges []ChunkLink `json:"crossChunkEdges"`
+	// Graph holds the graph-level output (summary, stats, diagnostics and
+	// so on), without nodes and edges.
+	Graph Graph `json:"graph"`
+}
+
+// ChunkInfo describes one chunk file.
+type ChunkInfo struct {
+	Index int    `json:"index"`
+	File  string `json:"file"`
+	Nodes int    `json:"nodes"`
+	Edges int    `json:"edges"`
+}
+
+// ChunkLink counts the edges from the chunk From into the chunk To.
+type ChunkLink struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Edges int `json:"edges"`
+}
+
+// GraphChunk is the content of a chunk file: a run of the graph's nodes
+// and the edges leaving them. TargetChunks gives, for each edge target in
+// another chunk, the index of that chunk.
+type GraphChunk struct {
+	Index        int            `json:"index"`
+	Nodes        []Node         `json:"nodes"`
+	Edges        []Edge         `json:"edges"`
+	TargetChunks map[string]int `json:"targetChunks,omitempty"`
+}
+
+// WriteChunks splits g into chunks of at most maxNodes nodes, in graph
+// order, and writes them as dir/base.0001.json, dir/base.0002.json and so
+// on. Each edge goes into the chunk of its source, or of its target when
+// the source is not a node.
+func WriteChunks(g *Graph, maxNodes int, dir, base string) (*ChunkManifest, error) {
+	if maxNodes <= 0 {
+		return nil, fmt.Errorf("maxNodesPerChunk must be positive, not %d", maxNodes)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var chunks []*GraphChunk
+	chunkOf := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if i%maxNodes == 0 {
+			chunks = append(chunks, &GraphChunk{Index: len(chunks) + 1, Nodes: []Node{}, Edges: []Edge{}})
+		}
+		c := chunks[len(chunks)-1]
+		c.Nodes = append(c.Nodes, n)
+		chunkOf[n.ID] = c.Index
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, &GraphChunk{Index: 1, Nodes: []Node{}, Edges: []Edge{}})
+	}
+
+	links := make(map[[2]int]int)
+	for _, e := range g.Edges {
+		from, ok := chunkOf[e.Source]
+		if !ok {
+			if from, ok = chunkOf[e.Target]; !ok {
+				from = 1
+			}
+		}
+		c := chunks[from-1]
+		c.Edges = append(c.Edges, e)
+		if to, ok := chunkOf[e.Target]; ok && to != from {
+			if c.TargetChunks == nil {
+				c.TargetChunks = make(map[string]int)
+			}
+			c.TargetChunks[e.Target] = to
+			links[[2]int{from, to}]++
+		}
+	}
+
+	m := &ChunkManifest{
+		SchemaVersion:   SchemaVersion,
+		Dir:             absDir,
+		TotalNodes:      len(g.Nodes),
+		TotalEdges:      len(g.Edges),
+		CrossChunkEdges: []ChunkLink{},
+		Graph:           *g,
+	}
+	m.Graph.Nodes, m.Graph.Edges = []Node{}, []Edge{}
+	for _, c := range chunks {
+		name := fmt.Sprintf("%s.%04d.json", base, c.Index)
+		if err := writeChunk(filepath.Join(absDir, name), c); err != nil {
+			return nil, err
+		}
+		m.Chunks = append(m.Chunks, ChunkInfo{Index: c.Index, File: name, Nodes: len(c.Nodes), Edges: len(c.Edges)})
+	}
+	for link, n := range links {
+		m.CrossChunkEdges = append(m.CrossChunkEdges, ChunkLink{From: link[0], To: link[1], Edges: n})
+	}
+	sort.Slice(m.CrossChunkEdges, func(i, j int) bool {
+		a, b := m.CrossChunkEdges[i], m.CrossChunkEdges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return m, nil
+}
+
+func writeChunk(file string, c *GraphChunk) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(c); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
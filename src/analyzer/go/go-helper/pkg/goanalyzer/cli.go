@@ -0,0 +1,323 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// CLIFlag is a command-line flag defined with flag or pflag.
+type CLIFlag struct {
+	Name string `json:"name"`
+	// Shorthand is the one-letter alias of pflag's ...P functions.
+	Shorthand string `json:"shorthand,omitempty"`
+	// Type is the defining function without Var and P ("String",
+	// "Duration", "StringSlice").
+	Type string `json:"type"`
+	// Default is the source text of the default value.
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage,omitempty"`
+	// Line is the line of the definition, which may be a package-level
+	// variable in another file than the function.
+	Line int `json:"line"`
+	// DefinedIn is the node defining the flag, in a CLISurface.
+	DefinedIn string `json:"definedIn,omitempty"`
+}
+
+// CLICommand is a subcommand: a cobra.Command or urfave/cli Command
+// literal, or a flag.NewFlagSet.
+type CLICommand struct {
+	Name string `json:"name"`
+	// Framework is "cobra", "urfave" or "flagset".
+	Framework string `json:"framework"`
+	Short     string `json:"short,omitempty"`
+	Line      int    `json:"line"`
+	DefinedIn string `json:"definedIn,omitempty"`
+}
+
+// CLISurface gathers the flags and subcommands of a binary on its main
+// function.
+type CLISurface struct {
+	Flags    []CLIFlag    `json:"flags"`
+	Commands []CLICommand `json:"commands"`
+}
+
+const (
+	cobraPkg  = "github.com/spf13/cobra"
+	urfavePkg = "github.com/urfave/cli"
+)
+
+// cliDefinition is a flag or a subcommand.
+type cliDefinition struct {
+	flag    *CLIFlag
+	command *CLICommand
+}
+
+// cliBindings records the package-level variables initialized with a flag
+// or command definition (var port = flag.Int(...), var rootCmd =
+// &cobra.Command{...}), so that the functions using them are credited
+// with the definition.
+type cliBindings struct {
+	byObj  map[types.Object]cliDefinition
+	byName map[string]cliDefinition
+}
+
+func newCLIBindings() *cliBindings {
+	return &cliBindings{byObj: make(map[types.Object]cliDefinition), byName: make(map[string]cliDefinition)}
+}
+
+// scanCLIDefinitions records the package-level variables of file that hold
+// a flag or command.
+func scanCLIDefinitions(file *ast.File, ctx *passContext) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, v := range vs.Values {
+				if i >= len(vs.Names) {
+					break
+				}
+				def, ok := cliDefinitionOf(v, ctx)
+				if !ok {
+					continue
+				}
+				if ctx.info != nil {
+					if obj := ctx.info.Defs[vs.Names[i]]; obj != nil {
+						ctx.shared.cli.byObj[obj] = def
+					}
+				}
+				ctx.shared.cli.byName[vs.Names[i].Name] = def
+			}
+		}
+	}
+}
+
+// collectCLIDefinitions returns the flags and commands a function defines,
+// or uses through a package-level variable.
+func collectCLIDefinitions(body *ast.BlockStmt, ctx *passContext) (flags []CLIFlag, commands []CLICommand) {
+	seen := make(map[cliDefinition]bool)
+	add := func(def cliDefinition) {
+		if seen[def] {
+			return
+		}
+		seen[def] = true
+		if def.flag != nil {
+			flags = append(flags, *def.flag)
+		} else {
+			commands = append(commands, *def.command)
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr, *ast.CompositeLit:
+			if def, ok := cliDefinitionOf(x.(ast.Expr), ctx); ok {
+				add(def)
+			}
+		case *ast.Ident:
+			if ctx.info != nil {
+				if def, ok := ctx.shared.cli.byObj[ctx.info.Uses[x]]; ok {
+					add(def)
+				}
+			} else if def, ok := ctx.shared.cli.byName[x.Name]; ok {
+				add(def)
+			}
+		}
+		return true
+	})
+	return flags, commands
+}
+
+// cliDefinitionOf recognizes a flag definition, a command literal or a
+// flag.NewFlagSet call with a constant name.
+func cliDefinitionOf(e ast.Expr, ctx *passContext) (cliDefinition, bool) {
+	e = ast.Unparen(e)
+	if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		e = ast.Unparen(u.X)
+	}
+	line := ctx.fset.Position(e.Pos()).Line
+	switch x := e.(type) {
+	case *ast.CallExpr:
+		target, ok := ctx.resolveCall(x)
+		if !ok {
+			return cliDefinition{}, false
+		}
+		if target.pkgPath == "flag" && target.name == "NewFlagSet" || target.inPackage(pflagPkg) && target.name == "NewFlagSet" {
+			if len(x.Args) > 0 {
+				if name, ok := ctx.constString(x.Args[0]); ok && name != "" {
+					return cliDefinition{command: &CLICommand{Name: name, Framework: "flagset", Line: line}}, true
+				}
+			}
+			return cliDefinition{}, false
+		}
+		if source, idx := configKeySource(target, ctx.info != nil); source != "flag" || target.name == "Lookup" || idx >= len(x.Args) {
+			return cliDefinition{}, false
+		}
+		return flagDefinitionOf(x, target.name, line, ctx)
+	case *ast.CompositeLit:
+		framework := commandFramework(x, ctx)
+		if framework == "" {
+			return cliDefinition{}, false
+		}
+		nameField, shortField := "Name", "Usage"
+		if framework == "cobra" {
+			nameField, shortField = "Use", "Short"
+		}
+		cmd := &CLICommand{Framework: framework, Line: line}
+		for _, elt := range x.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, _ := kv.Key.(*ast.Ident)
+			if key == nil {
+				continue
+			}
+			value, ok := ctx.constString(kv.Value)
+			if !ok {
+				continue
+			}
+			switch key.Name {
+			case nameField:
+				// cobra's Use is "serve [flags] <addr>".
+				cmd.Name, _, _ = strings.Cut(value, " ")
+			case shortField:
+				cmd.Short = value
+			}
+		}
+		if cmd.Name == "" {
+			return cliDefinition{}, false
+		}
+		return cliDefinition{command: cmd}, true
+	}
+	return cliDefinition{}, false
+}
+
+// flagDefinitionOf reads the name, shorthand, default and usage of a call
+// to a flag or pflag definition function. The arguments follow the
+// destination, if any: name, then the shorthand for ...P functions, then
+// the default and the usage, except for Var, Func and BoolFunc, which have
+// no default.
+func flagDefinitionOf(call *ast.CallExpr, fn string, line int, ctx *passContext) (cliDefinition, bool) {
+	i := flagNameArg(fn)
+	name, ok := ctx.constString(call.Args[i])
+	if !ok || name == "" {
+		return cliDefinition{}, false
+	}
+	base := strings.TrimSuffix(fn, "P")
+	f := &CLIFlag{Name: name, Type: strings.TrimSuffix(base, "Var"), Line: line}
+	if f.Type == "" {
+		f.Type = "Var"
+	}
+	if base != fn && i+1 < len(call.Args) {
+		f.Shorthand, _ = ctx.constString(call.Args[i+1])
+		i++
+	}
+	rest := call.Args[i+1:]
+	switch fn {
+	case "Var", "VarP", "Func", "BoolFunc", "FuncP", "BoolFuncP":
+		if len(rest) > 0 {
+			f.Usage, _ = ctx.constString(rest[0])
+		}
+	default:
+		if len(rest) >= 2 {
+			f.Default = types.ExprString(rest[0])
+			f.Usage, _ = ctx.constString(rest[len(rest)-1])
+		}
+	}
+	return cliDefinition{flag: f}, true
+}
+
+// commandFramework returns "cobra" or "urfave" for a composite literal of
+// cobra.Command or urfave/cli's Command, or "".
+func commandFramework(lit *ast.CompositeLit, ctx *passContext) string {
+	var pkgPath string
+	if ctx.info != nil {
+		t := ctx.info.TypeOf(lit)
+		named, ok := t.(*types.Named)
+		if !ok || named.Obj().Name() != "Command" || named.Obj().Pkg() == nil {
+			return ""
+		}
+		pkgPath = named.Obj().Pkg().Path()
+	} else {
+		sel, ok := lit.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Command" {
+			return ""
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		pkgPath = ctx.imports[pkg.Name]
+	}
+	switch {
+	case pkgPath == cobraPkg:
+		return "cobra"
+	case pkgPath == urfavePkg || strings.HasPrefix(pkgPath, urfavePkg+"/"):
+		return "urfave"
+	}
+	return ""
+}
+
+// attachCLISurfaces sets Metadata.CLI on the main function of each binary
+// to the flags and commands of the functions the binary reaches (see
+// markBinaries), and of the init functions and package-level initializers
+// of the packages it reaches. The flags and commands of each function are
+// then dropped, so that they are output once.
+func attachCLISurfaces(g *Graph) {
+	reachedPkgs := make(map[string]map[string]bool)
+	for _, n := range g.Nodes {
+		for _, binary := range n.ReachableFrom {
+			if reachedPkgs[binary] == nil {
+				reachedPkgs[binary] = make(map[string]bool)
+			}
+			reachedPkgs[binary][n.PackageOrModule] = true
+		}
+	}
+	for i := range g.Nodes {
+		main := &g.Nodes[i]
+		if main.Kind != "function" || main.Name != "main" || !main.IsEntryPoint || main.ReachableFrom == nil {
+			continue
+		}
+		binary := main.PackageOrModule
+		surface := &CLISurface{Flags: []CLIFlag{}, Commands: []CLICommand{}}
+		for _, n := range g.Nodes {
+			if n.Metadata == nil || !slices.Contains(n.ReachableFrom, binary) &&
+				!(reachedPkgs[binary][n.PackageOrModule] && (n.Kind == "init" || n.Name == "init")) {
+				continue
+			}
+			for _, f := range n.Metadata.CLIFlags {
+				f.DefinedIn = n.ID
+				surface.Flags = append(surface.Flags, f)
+			}
+			for _, c := range n.Metadata.CLICommands {
+				c.DefinedIn = n.ID
+				surface.Commands = append(surface.Commands, c)
+			}
+		}
+		if len(surface.Flags) == 0 && len(surface.Commands) == 0 {
+			continue
+		}
+		sort.SliceStable(surface.Flags, func(a, b int) bool { return surface.Flags[a].Name < surface.Flags[b].Name })
+		sort.SliceStable(surface.Commands, func(a, b int) bool { return surface.Commands[a].Name < surface.Commands[b].Name })
+		if main.Metadata == nil {
+			main.Metadata = &NodeMetadata{}
+		}
+		main.Metadata.CLI = surface
+	}
+	for i := range g.Nodes {
+		md := g.Nodes[i].Metadata
+		if md == nil {
+			continue
+		}
+		md.CLIFlags, md.CLICommands = nil, nil
+		if md.isEmpty() {
+			g.Nodes[i].Metadata = nil
+		}
+	}
+}
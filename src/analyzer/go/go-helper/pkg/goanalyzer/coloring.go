@@ -0,0 +1,89 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// ColorRule restyles the nodes matching When (Options.ColorRules). Rules are
+// tried in order after liveness is computed, and the first match sets the
+// node's Status and Color; empty fields keep the computed value.
+type ColorRule struct {
+	When   ColorCondition `json:"when"`
+	Status string         `json:"status,omitempty"`
+	Color  string         `json:"color,omitempty"`
+}
+
+// ColorCondition holds the conditions of a ColorRule, all of which must
+// hold; unset fields are not checked.
+type ColorCondition struct {
+	// Status is the computed liveness: "entry", "live", "test-only" or
+	// "dead".
+	Status string `json:"status,omitempty"`
+	// Observed matches Node.ObservedAtRuntime, set from Options.Executed
+	// or a coverage profile in Options.ExecutionLog. Nodes without
+	// runtime data never match.
+	Observed *bool `json:"observed,omitempty"`
+	// Deprecated matches functions whose doc comment has a "Deprecated: "
+	// paragraph.
+	Deprecated *bool `json:"deprecated,omitempty"`
+	// MinSloc, MinCallers and MinCallees are lower bounds on Node.Sloc and
+	// on the distinct callers and callees of the node, counted over the
+	// whole graph before Options.Files or pruning narrow it.
+	MinSloc    int `json:"minSloc,omitempty"`
+	MinCallers int `json:"minCallers,omitempty"`
+	MinCallees int `json:"minCallees,omitempty"`
+	// Kind matches Node.Kind ("function", "method", ...).
+	Kind string `json:"kind,omitempty"`
+}
+
+func (c ColorCondition) matches(n Node, callers, callees int) bool {
+	switch {
+	case c.Status != "" && n.Status != c.Status,
+		c.Kind != "" && n.Kind != c.Kind,
+		c.Observed != nil && (n.ObservedAtRuntime == nil || *n.ObservedAtRuntime != *c.Observed),
+		c.Deprecated != nil && n.Deprecated != *c.Deprecated,
+		n.Sloc < c.MinSloc, callers < c.MinCallers, callees < c.MinCallees:
+		return false
+	}
+	return true
+}
+
+// applyColorRules restyles the nodes with g.ColorRules. markLiveness calls
+// it, so the rules survive every recomputation of liveness. A rule setting
+// Status changes what counts as dead in DeadFunctions and the baseline.
+func applyColorRules(g *Graph) {
+	if len(g.ColorRules) == 0 {
+		return
+	}
+	fanIn, fanOut := fanCounts(g)
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		for _, r := range g.ColorRules {
+			if !r.When.matches(*n, fanIn[n.ID], fanOut[n.ID]) {
+				continue
+			}
+			if r.Status != "" {
+				n.Status = r.Status
+			}
+			if r.Color != "" {
+				n.Color = r.Color
+			}
+			break
+		}
+	}
+}
+
+// isDeprecated reports whether doc has a paragraph starting with
+// "Deprecated: ", the Go convention for deprecated identifiers.
+func isDeprecated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		if strings.HasPrefix(paragraph, "Deprecated: ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,311 @@
+package goanalyzer
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CompatRequest selects the two versions of the project compared by
+// CompareRefs.
+type CompatRequest struct {
+	// Base and Head are git revisions; an empty Head is the working tree.
+	Base string `json:"base"`
+	Head string `json:"head,omitempty"`
+	// Exclude lists globs of files to leave out when Options.Files is not
+	// set and the files of each version are discovered.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// CompatReport lists the changes to the public API between two versions
+// that can break code using it.
+type CompatReport struct {
+	Base    string      `json:"base"`
+	Head    string      `json:"head"`
+	Changes []APIChange `json:"changes"`
+}
+
+// APIChange is a breaking change to one exported function, method or type.
+type APIChange struct {
+	SymbolID string `json:"symbolId"`
+	Kind     string `json:"kind"`
+	// Change is "removed" or "changed".
+	Change string `json:"change"`
+	// Base and Head are the signatures in each version.
+	Base string `json:"base"`
+	Head string `json:"head,omitempty"`
+	// Details describe a changed signature, e.g. "parameter 2 type
+	// changed: int -> string".
+	Details []string `json:"details,omitempty"`
+	// Callers are the node IDs, at the base version, of the project's
+	// functions calling the changed function.
+	Callers []string `json:"callers"`
+}
+
+// CompareRefs reports the breaking changes to the project's exported API
+// between two git revisions, with the callers affected at the base one.
+// Each revision is exported with git archive into a temporary directory and
+// analyzed with opts' Files, FilesFrom, Module and Algorithm; the base is
+// also fully analyzed to find the callers.
+func CompareRefs(ctx context.Context, opts Options, req CompatRequest) (CompatReport, error) {
+	report := CompatReport{Base: req.Base, Head: req.Head}
+	if req.Base == "" {
+		return report, errors.New("no base revision")
+	}
+	baseDir, err := os.MkdirTemp("", "codegraph-base-")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(baseDir)
+	if err := exportRevision(ctx, opts.ProjectRoot, req.Base, baseDir); err != nil {
+		return report, err
+	}
+	headDir := opts.ProjectRoot
+	if req.Head != "" {
+		if headDir, err = os.MkdirTemp("", "codegraph-head-"); err != nil {
+			return report, err
+		}
+		defer os.RemoveAll(headDir)
+		if err := exportRevision(ctx, opts.ProjectRoot, req.Head, headDir); err != nil {
+			return report, err
+		}
+	}
+
+	analyze := func(dir string, api bool) (Graph, error) {
+		o := Options{
+			ProjectRoot: dir,
+			Files:       opts.Files,
+			FilesFrom:   opts.FilesFrom,
+			Module:      opts.Module,
+			Algorithm:   opts.Algorithm,
+			APISurface:  api,
+			Log:         opts.Log,
+		}
+		if len(o.Files) == 0 && o.FilesFrom == "" {
+			var err error
+			if o.Files, err = DiscoverFiles(dir, append(append([]string{}, DefaultExclude...), req.Exclude...)); err != nil {
+				return Graph{}, err
+			}
+		}
+		return Analyze(ctx, o)
+	}
+	baseAPI, err := analyze(baseDir, true)
+	if err != nil {
+		return report, err
+	}
+	headAPI, err := analyze(headDir, true)
+	if err != nil {
+		return report, err
+	}
+	baseGraph, err := analyze(baseDir, false)
+	if err != nil {
+		return report, err
+	}
+	report.Changes = diffAPI(baseAPI, headAPI, baseGraph)
+	return report, nil
+}
+
+// exportRevision writes the project root's tree at rev into dir.
+func exportRevision(ctx context.Context, root, rev, dir string) error {
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--show-toplevel", "--show-prefix").Output()
+	if err != nil {
+		return fmt.Errorf("git rev-parse: %w", gitError(err))
+	}
+	top, prefix, _ := strings.Cut(strings.TrimRight(string(out), "\n"), "\n")
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "-C", top, "archive", "--format=tar", rev+":"+prefix)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git archive %s: %w: %s", rev, err, strings.TrimSpace(stderr.String()))
+	}
+	tr := tar.NewReader(&stdout)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+}
+
+// gitError adds git's message to a failed command's error.
+func gitError(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(exit.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exit.Stderr)))
+	}
+	return err
+}
+
+// diffAPI compares two API surface graphs by SymbolID and attaches the
+// callers found in baseGraph to each change.
+func diffAPI(base, head, baseGraph Graph) []APIChange {
+	heads := make(map[string]Node, len(head.Nodes))
+	for _, n := range head.Nodes {
+		heads[n.SymbolID] = n
+	}
+	changes := []APIChange{}
+	for _, old := range base.Nodes {
+		change := APIChange{SymbolID: old.SymbolID, Kind: old.Kind, Base: old.Signature}
+		cur, ok := heads[old.SymbolID]
+		switch {
+		case !ok:
+			change.Change = "removed"
+		case old.Kind == "type":
+			if old.Signature == cur.Signature {
+				continue
+			}
+			change.Change, change.Head = "changed", cur.Signature
+		default:
+			details := signatureChanges(old.Signature, cur.Signature)
+			if len(details) == 0 {
+				continue
+			}
+			change.Change, change.Head, change.Details = "changed", cur.Signature, details
+		}
+		changes = append(changes, change)
+	}
+
+	symbolOf := make(map[string]string, len(baseGraph.Nodes))
+	for _, n := range baseGraph.Nodes {
+		symbolOf[n.ID] = n.SymbolID
+	}
+	callers := make(map[string]map[string]bool)
+	for _, e := range baseGraph.Edges {
+		sym := symbolOf[e.Target]
+		if sym == "" || symbolOf[e.Source] == "" || e.Kind == "provided" {
+			// Constructors returning a type do not call its methods.
+			continue
+		}
+		if callers[sym] == nil {
+			callers[sym] = make(map[string]bool)
+		}
+		callers[sym][e.Source] = true
+	}
+	for i := range changes {
+		list := []string{}
+		for id := range callers[changes[i].SymbolID] {
+			list = append(list, id)
+		}
+		sort.Strings(list)
+		changes[i].Callers = list
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].SymbolID < changes[j].SymbolID })
+	return changes
+}
+
+// signatureChanges describes how a function signature changed in ways that
+// break callers or implementations; renamed parameters do not count.
+func signatureChanges(old, cur string) []string {
+	if old == cur {
+		return nil
+	}
+	a, okA := parseSignature(old)
+	b, okB := parseSignature(cur)
+	if !okA || !okB {
+		return []string{"signature changed"}
+	}
+	var details []string
+	if a.receiver != b.receiver {
+		details = append(details, fmt.Sprintf("receiver changed: %s -> %s", a.receiver, b.receiver))
+	}
+	if a.typeParams != b.typeParams {
+		details = append(details, fmt.Sprintf("type parameters changed: [%s] -> [%s]", a.typeParams, b.typeParams))
+	}
+	details = append(details, listChanges("parameter", a.params, b.params)...)
+	details = append(details, listChanges("result", a.results, b.results)...)
+	return details
+}
+
+func listChanges(what string, old, cur []string) []string {
+	var details []string
+	for i := 0; i < len(old) || i < len(cur); i++ {
+		switch {
+		case i >= len(cur):
+			details = append(details, fmt.Sprintf("%s %d removed: %s", what, i+1, old[i]))
+		case i >= len(old):
+			details = append(details, fmt.Sprintf("%s %d added: %s", what, i+1, cur[i]))
+		case old[i] != cur[i]:
+			details = append(details, fmt.Sprintf("%s %d type changed: %s -> %s", what, i+1, old[i], cur[i]))
+		}
+	}
+	return details
+}
+
+// signatureShape is a function signature without parameter names.
+type signatureShape struct {
+	receiver, typeParams string
+	params, results      []string
+}
+
+// parseSignature parses a signature as printed in APISurface mode.
+func parseSignature(sig string) (signatureShape, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\n"+sig, parser.SkipObjectResolution)
+	if err != nil || len(f.Decls) != 1 {
+		return signatureShape{}, false
+	}
+	decl, ok := f.Decls[0].(*ast.FuncDecl)
+	if !ok {
+		return signatureShape{}, false
+	}
+	var shape signatureShape
+	if decl.Recv != nil {
+		shape.receiver = strings.Join(fieldTypes(fset, decl.Recv), ", ")
+	}
+	if decl.Type.TypeParams != nil {
+		var tparams []string
+		for _, field := range decl.Type.TypeParams.List {
+			constraint := printSignature(fset, field.Type)
+			for range field.Names {
+				tparams = append(tparams, constraint)
+			}
+		}
+		shape.typeParams = strings.Join(tparams, ", ")
+	}
+	shape.params = fieldTypes(fset, decl.Type.Params)
+	shape.results = fieldTypes(fset, decl.Type.Results)
+	return shape, true
+}
+
+// fieldTypes lists the type of each field, once per name.
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var types []string
+	for _, field := range fields.List {
+		typ := printSignature(fset, field.Type)
+		for i := 0; i < max(len(field.Names), 1); i++ {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
@@ -0,0 +1,80 @@
+package goanalyzer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compilerDiagnostic is a line of the compiler's optimization report, as
+// printed with -gcflags=-m: "./main.go:12:6: can inline f with cost 4 ...".
+type compilerDiagnostic struct {
+	FilePath string // relative to the project root, slash-separated
+	Line     int
+	Message  string
+}
+
+var compilerDiagnosticLine = regexp.MustCompile(`^(.+?\.go):(\d+):\d+: (.*)$`)
+
+// compilerDiagnostics builds the project's packages, discarding the
+// result, with -gcflags set to gcflags, and returns the diagnostics about
+// files under the project root. The build context of BuildInfo, if any,
+// and GOPATH mode apply. Files on disk are compiled, not overlays.
+func (o Options) compilerDiagnostics(ctx context.Context, gcflags string) ([]compilerDiagnostic, error) {
+	absRoot, err := filepath.Abs(o.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"build", "-o", os.DevNull, "-gcflags=" + gcflags}
+	if o.build != nil {
+		args = append(args, o.build.buildFlags()...)
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, "./...")...)
+	cmd.Dir = absRoot
+	cmd.Env = o.packagesEnv()
+	// The report goes to stderr, where build errors go too.
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go build: %w: %s", err, firstLines(stderr.String(), 5))
+	}
+
+	var diags []compilerDiagnostic
+	sc := bufio.NewScanner(&stderr)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		m := compilerDiagnosticLine.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		path := m[1]
+		if filepath.IsAbs(path) {
+			if path, err = filepath.Rel(absRoot, path); err != nil {
+				continue
+			}
+		}
+		path = filepath.ToSlash(filepath.Clean(path))
+		if strings.HasPrefix(path, "../") {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		diags = append(diags, compilerDiagnostic{FilePath: path, Line: line, Message: m[3]})
+	}
+	return diags, sc.Err()
+}
+
+// firstLines returns the first n lines of s, trimmed.
+func firstLines(s string, n int) string {
+	lines := strings.SplitN(strings.TrimSpace(s), "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
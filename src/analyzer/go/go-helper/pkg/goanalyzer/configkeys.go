@@ -0,0 +1,182 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// ConfigKey is a configuration key read or defined by a function.
+type ConfigKey struct {
+	// Source is "env" (os.Getenv and friends), "viper" or "flag" (flag/pflag definitions).
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Line   int    `json:"line"`
+}
+
+const (
+	viperPkg = "github.com/spf13/viper"
+	pflagPkg = "github.com/spf13/pflag"
+)
+
+var envFuncs = map[string]bool{
+	"Getenv": true, "LookupEnv": true, "Setenv": true, "Unsetenv": true,
+}
+
+// flagDefinition matches flag/pflag functions and FlagSet methods that define
+// or look up a flag by name: String, IntVar, StringSliceVarP, Lookup, ...
+var flagDefinition = regexp.MustCompile(`^((Bool|Int|Int8|Int16|Int32|Int64|Uint|Uint8|Uint16|Uint32|Uint64|` +
+	`Float32|Float64|String|Duration|Func|BoolFunc|TextVar|Var|IP|IPMask|IPNet|Count|BytesHex|BytesBase64)` +
+	`(Slice|Array|ToString|ToInt)*(Var)?P?|Lookup)$`)
+
+// configBindings records the package-level variables initialized with a
+// configuration read (var dsn = os.Getenv("DSN"), var port =
+// flag.Int("port", ...)), so that the functions using them are credited
+// with the keys.
+type configBindings struct {
+	byObj  map[types.Object][]ConfigKey
+	byName map[string][]ConfigKey
+}
+
+func newConfigBindings() *configBindings {
+	return &configBindings{byObj: make(map[types.Object][]ConfigKey), byName: make(map[string][]ConfigKey)}
+}
+
+// scanConfigKeys records the package-level variables of file that read a
+// configuration key.
+func scanConfigKeys(file *ast.File, ctx *passContext) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, v := range vs.Values {
+				if i >= len(vs.Names) {
+					break
+				}
+				keys := configKeysIn(v, ctx)
+				if len(keys) == 0 {
+					continue
+				}
+				if ctx.info != nil {
+					if obj := ctx.info.Defs[vs.Names[i]]; obj != nil {
+						ctx.shared.config.byObj[obj] = keys
+					}
+				}
+				ctx.shared.config.byName[vs.Names[i].Name] = keys
+			}
+		}
+	}
+}
+
+// collectConfigKeys returns the environment variables, viper keys and flags
+// a function reads or defines with a constant name, itself or through a
+// package-level variable. Keys read through a variable are reported at the
+// line of its declaration.
+func collectConfigKeys(body *ast.BlockStmt, ctx *passContext) []ConfigKey {
+	keys := configKeysIn(body, ctx)
+	seen := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || seen[id.Name] {
+			return true
+		}
+		var bound []ConfigKey
+		if ctx.info != nil {
+			bound = ctx.shared.config.byObj[ctx.info.Uses[id]]
+		} else {
+			bound = ctx.shared.config.byName[id.Name]
+		}
+		if len(bound) > 0 {
+			seen[id.Name] = true
+			keys = append(keys, bound...)
+		}
+		return true
+	})
+	return keys
+}
+
+// configKeysIn returns the keys read or defined by the calls within n.
+func configKeysIn(n ast.Node, ctx *passContext) []ConfigKey {
+	var keys []ConfigKey
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		target, ok := ctx.resolveCall(call)
+		if !ok {
+			return true
+		}
+		source, argIdx := configKeySource(target, ctx.info != nil)
+		if source == "" || argIdx >= len(call.Args) {
+			return true
+		}
+		key, ok := ctx.constString(call.Args[argIdx])
+		if !ok || key == "" {
+			return true
+		}
+		keys = append(keys, ConfigKey{
+			Source: source,
+			Key:    key,
+			Line:   ctx.fset.Position(call.Pos()).Line,
+		})
+		return true
+	})
+	return keys
+}
+
+// configKeySource classifies a call as an env, viper or flag access and
+// returns the position of its key argument.
+func configKeySource(target callTarget, typed bool) (string, int) {
+	switch {
+	case target.pkgPath == "os" || target.pkgPath == "syscall":
+		if envFuncs[target.name] {
+			return "env", 0
+		}
+	case target.inPackage(viperPkg):
+		if isViperKeyFunc(target.name) {
+			return "viper", 0
+		}
+	case target.pkgPath == "flag" || target.inPackage(pflagPkg):
+		if flagDefinition.MatchString(target.name) {
+			return "flag", flagNameArg(target.name)
+		}
+	case target.pkgPath == "" && !typed && target.recvExpr != nil:
+		// Without types, recognize *viper.Viper and FlagSet methods by the
+		// receiver's name.
+		recv := strings.ToLower(target.recvText())
+		if strings.Contains(recv, "viper") && isViperKeyFunc(target.name) {
+			return "viper", 0
+		}
+		if (strings.Contains(recv, "flag") || recv == "fs") && flagDefinition.MatchString(target.name) {
+			return "flag", flagNameArg(target.name)
+		}
+	}
+	return "", -1
+}
+
+func isViperKeyFunc(name string) bool {
+	switch name {
+	case "IsSet", "Sub", "Set", "SetDefault", "BindEnv", "BindPFlag", "InConfig":
+		return true
+	}
+	return strings.HasPrefix(name, "Get")
+}
+
+// flagNameArg returns the index of the name argument: flag.StringVar(&p, "name", ...)
+// takes a destination first, flag.String("name", ...) does not.
+func flagNameArg(name string) int {
+	if name == "Lookup" {
+		return 0
+	}
+	base := strings.TrimSuffix(name, "P")
+	if strings.HasSuffix(base, "Var") {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package goanalyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestConfigKeysOfPackageVariables(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{"main.go": `package main
+
+import (
+	"flag"
+	"os"
+)
+
+var port = flag.Int("port", 8080, "")
+
+var dsn = os.Getenv("DSN")
+
+func serve() int { return *port }
+
+func connect() string { return dsn + os.Getenv("DSN_OPTIONS") }
+
+func main() {
+	flag.Parse()
+	serve()
+	connect()
+}
+`}, goanalyzer.Options{Passes: []string{"config"}})
+
+	keys := make(map[string][]string)
+	for _, n := range graph.Nodes {
+		if n.Metadata == nil {
+			continue
+		}
+		for _, k := range n.Metadata.ConfigKeys {
+			keys[n.ID] = append(keys[n.ID], k.Source+":"+k.Key)
+		}
+	}
+	for id, want := range map[string][]string{
+		"main.go:serve":   {"flag:port"},
+		"main.go:connect": {"env:DSN_OPTIONS", "env:DSN"},
+		"main.go:main":    nil,
+	} {
+		if got := keys[id]; !slices.Equal(got, want) {
+			t.Errorf("%s reads %v, want %v", id, got, want)
+		}
+	}
+	if got := graph.Summary.ConfigKeys["env:DSN"]; !slices.Equal(got, []string{"main.go:connect"}) {
+		t.Errorf("env:DSN read by %v, want [main.go:connect]", got)
+	}
+}
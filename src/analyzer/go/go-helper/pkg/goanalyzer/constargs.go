@@ -0,0 +1,162 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+)
+
+// maxConstantValue bounds the string constants recorded in
+// ConstantArg.Value, in bytes.
+const maxConstantValue = 64
+
+// ConstantArg is an argument passed as a constant (a literal, a named
+// constant or nil) at one call site of an edge, so that calls can be
+// queried by argument, e.g. every Retry call with attempts = 0.
+type ConstantArg struct {
+	// Line is the line of the call; edges merge repeated calls.
+	Line int `json:"line"`
+	// Position is the index of the argument; Parameter names the callee's
+	// parameter when known.
+	Position  int    `json:"position"`
+	Parameter string `json:"parameter,omitempty"`
+	// Value is the constant in Go syntax ("0", "true", "\"GET\"", "nil").
+	// It is omitted for strings longer than maxConstantValue, and when type
+	// information is missing and the argument is a named constant.
+	Value string `json:"value,omitempty"`
+}
+
+// constantArgs lists the constant arguments of call. With type information
+// named constants count too and parameters are named from the callee's
+// signature; without it only literals, true, false and nil are recognized.
+func constantArgs(call *ast.CallExpr, fset *token.FileSet, info *types.Info) []ConstantArg {
+	var sig *types.Signature
+	if info != nil {
+		sig, _ = info.TypeOf(call.Fun).(*types.Signature)
+	}
+	line := fset.Position(call.Pos()).Line
+	var args []ConstantArg
+	for i, arg := range call.Args {
+		value, ok := constantValue(arg, info)
+		if !ok {
+			continue
+		}
+		a := ConstantArg{Line: line, Position: i, Value: value}
+		if sig != nil && sig.Params().Len() > 0 {
+			p := min(i, sig.Params().Len()-1)
+			if name := sig.Params().At(p).Name(); (p == i || sig.Variadic()) && name != "_" {
+				a.Parameter = name
+			}
+		}
+		args = append(args, a)
+	}
+	return args
+}
+
+// constantValue reports whether arg is constant, and its value when it can
+// be printed.
+func constantValue(arg ast.Expr, info *types.Info) (string, bool) {
+	if info != nil {
+		tv, ok := info.Types[arg]
+		switch {
+		case !ok:
+			return "", false
+		case tv.IsNil():
+			return "nil", true
+		case tv.Value == nil:
+			return "", false
+		case tv.Value.Kind() == constant.String:
+			if s := constant.StringVal(tv.Value); len(s) <= maxConstantValue {
+				return strconv.Quote(s), true
+			}
+			return "", true
+		}
+		return tv.Value.String(), true
+	}
+	switch e := ast.Unparen(arg).(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING && len(e.Value) > maxConstantValue+2 {
+			return "", true
+		}
+		return e.Value, true
+	case *ast.UnaryExpr:
+		if lit, ok := ast.Unparen(e.X).(*ast.BasicLit); ok && (e.Op == token.SUB || e.Op == token.ADD) && lit.Kind != token.STRING {
+			return e.Op.String() + lit.Value, true
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false", "nil":
+			return e.Name, true
+		}
+	}
+	return "", false
+}
+
+// nameArgs names the parameters of args from the callee's node, as found
+// by AST-only analysis.
+func nameArgs(args []ConstantArg, params []Parameter) {
+	for i := range args {
+		if p := args[i].Position; p < len(params) && params[p].Name != "_" {
+			args[i].Parameter = params[p].Name
+		}
+	}
+}
+
+// nilParameters names the parameters of call's callee that are given a
+// literal nil: with type information only pointers and interfaces (a nil
+// logger, a nil *Config), without it any nil argument. Unnamed parameters
+// are given by position ("#2").
+func nilParameters(call *ast.CallExpr, info *types.Info, params []Parameter) []string {
+	var sig *types.Signature
+	if info != nil {
+		if sig, _ = info.TypeOf(call.Fun).(*types.Signature); sig == nil {
+			return nil
+		}
+	}
+	var names []string
+	for i, arg := range call.Args {
+		name := "#" + strconv.Itoa(i)
+		if sig != nil {
+			if tv, ok := info.Types[arg]; !ok || !tv.IsNil() || sig.Params().Len() == 0 {
+				continue
+			}
+			p := sig.Params().At(min(i, sig.Params().Len()-1))
+			switch p.Type().Underlying().(type) {
+			case *types.Pointer, *types.Interface:
+			default:
+				continue
+			}
+			if p.Name() != "" && p.Name() != "_" {
+				name = p.Name()
+			}
+		} else {
+			if id, ok := ast.Unparen(arg).(*ast.Ident); !ok || id.Name != "nil" {
+				continue
+			}
+			if i < len(params) && params[i].Name != "_" {
+				name = params[i].Name
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// addCallShape records the argument count and nil arguments of one more
+// call site on e.
+func (e *Edge) addCallShape(call *ast.CallExpr, info *types.Info, params []Parameter) {
+	e.ArgCounts = append(e.ArgCounts, len(call.Args))
+	if info != nil {
+		if sig, ok := info.TypeOf(call.Fun).(*types.Signature); ok && sig.Variadic() {
+			e.Variadic = true
+		}
+	}
+	for _, name := range nilParameters(call, info, params) {
+		if !slices.Contains(e.NilParameters, name) {
+			e.NilParameters = append(e.NilParameters, name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// corpusEnv enables the corpus runner, which downloads the pinned modules in
+// testdata/corpus.json through the Go module proxy:
+//
+//	CODEGRAPH_CORPUS=1 go test ./pkg/goanalyzer -run TestCorpus -v
+const corpusEnv = "CODEGRAPH_CORPUS"
+
+// corpusEntry pins a real-world module and the graph it is expected to
+// produce. Node and edge counts are inclusive [min, max] ranges so small,
+// intended precision changes only need the range widened, not re-pinned.
+type corpusEntry struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Nodes    [2]int `json:"nodes"`
+	Edges    [2]int `json:"edges"`
+	BudgetMs int64  `json:"budgetMs"`
+}
+
+func TestCorpus(t *testing.T) {
+	if os.Getenv(corpusEnv) == "" {
+		t.Skipf("set %s=1 to analyze the pinned corpus", corpusEnv)
+	}
+	requireTypedAnalysis(t)
+	data, err := os.ReadFile(filepath.Join("testdata", "corpus.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corpus []corpusEntry
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatal(err)
+	}
+
+	// Dependencies of the corpus modules are resolved on demand.
+	t.Setenv("GOFLAGS", "-mod=mod")
+	for _, entry := range corpus {
+		t.Run(entry.Module+"@"+entry.Version, func(t *testing.T) {
+			root := downloadModule(t, entry.Module, entry.Version)
+			files, err := goanalyzer.DiscoverFiles(root, goanalyzer.DefaultExclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			graph, err := goanalyzer.Analyze(context.Background(), goanalyzer.Options{
+				Files:       files,
+				ProjectRoot: root,
+				Module:      entry.Module,
+				Log:         testLog{t},
+			})
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			graph.RestrictToFiles(files)
+			t.Logf("%d nodes, %d edges, %v, stats %+v", len(graph.Nodes), len(graph.Edges), elapsed, *graph.Stats)
+
+			if graph.Stats.Algorithm != goanalyzer.AlgorithmTypes {
+				t.Fatalf("analysis fell back to %s; counts are pinned for type-aware analysis", graph.Stats.Algorithm)
+			}
+			if n := len(graph.Nodes); n < entry.Nodes[0] || n > entry.Nodes[1] {
+				t.Errorf("nodes = %d, want within %v", n, entry.Nodes)
+			}
+			if n := len(graph.Edges); n < entry.Edges[0] || n > entry.Edges[1] {
+				t.Errorf("edges = %d, want within %v", n, entry.Edges)
+			}
+			if budget := time.Duration(entry.BudgetMs) * time.Millisecond; elapsed > budget {
+				t.Errorf("analysis took %v, budget %v", elapsed, budget)
+			}
+		})
+	}
+}
+
+// downloadModule fetches module@version into the module cache and returns a
+// writable copy of it, with a go.mod added for pre-modules repositories.
+func downloadModule(t *testing.T, module, version string) string {
+	t.Helper()
+	out, err := exec.Command("go", "mod", "download", "-json", module+"@"+version).Output()
+	if err != nil {
+		t.Fatalf("go mod download %s@%s: %v", module, version, err)
+	}
+	var info struct{ Dir string }
+	if err := json.Unmarshal(out, &info); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	err = filepath.WalkDir(info.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(info.Dir, path)
+		dst := filepath.Join(root, rel)
+		if d.IsDir() {
+			return os.MkdirAll(dst, 0o755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, content, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goMod := filepath.Join(root, "go.mod")
+	if _, err := os.Stat(goMod); os.IsNotExist(err) {
+		if err := os.WriteFile(goMod, []byte("module "+module+"\n\ngo 1.21\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// testLog routes analysis warnings to the test log.
+type testLog struct{ t *testing.T }
+
+func (l testLog) Write(p []byte) (int, error) {
+	l.t.Log(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,173 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// RemovableParameter is an unused parameter that can be dropped from its
+// function: every call passes a constant or zero value, so removing the
+// argument loses no side effect, and nothing else (an interface, a function
+// value) fixes the signature.
+type RemovableParameter struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	// CallSites are the calls whose argument must be deleted.
+	CallSites []CallSite `json:"callSites"`
+}
+
+// paramUse accumulates what the calls of one function pass to it.
+type paramUse struct {
+	// fixed is set when the signature cannot change: the function is used
+	// as a value, or is a method an interface may require.
+	fixed bool
+	// dynamic marks the parameter positions given a non-constant argument.
+	dynamic map[int]bool
+	sites   []CallSite
+}
+
+// findRemovableParameters runs the "deadparams" pass over the type-checked
+// project: it follows the unused parameters of nodes to the calls of their
+// function and records those that can be removed in Node.Metadata. Exported
+// functions outside package main are left alone, as callers outside the
+// project cannot be seen.
+func findRemovableParameters(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, nodes []Node) {
+	candidates := make(map[*types.Func]*paramUse)
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+	for obj, id := range objToNodeID {
+		fn, ok := obj.(*types.Func)
+		i, found := index[id]
+		if !ok || !found || len(nodes[i].UnusedParameters) == 0 {
+			continue
+		}
+		if fn.Exported() && fn.Pkg().Name() != "main" {
+			continue
+		}
+		candidates[fn] = &paramUse{dynamic: make(map[int]bool)}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	// A method whose name an interface declares may be needed, unchanged,
+	// to implement it.
+	interfaceMethods := make(map[string]bool)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil {
+			return
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			if iface, ok := scope.Lookup(name).Type().Underlying().(*types.Interface); ok {
+				for i := 0; i < iface.NumMethods(); i++ {
+					interfaceMethods[iface.Method(i).Name()] = true
+				}
+			}
+		}
+	})
+	for fn, use := range candidates {
+		if fn.Type().(*types.Signature).Recv() != nil && interfaceMethods[fn.Name()] {
+			use.fixed = true
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			called := make(map[*ast.Ident]bool)
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.CallExpr:
+					var name *ast.Ident
+					switch fun := ast.Unparen(n.Fun).(type) {
+					case *ast.Ident:
+						name = fun
+					case *ast.SelectorExpr:
+						name = fun.Sel
+					}
+					if name == nil {
+						return true
+					}
+					fn, _ := pkg.TypesInfo.Uses[name].(*types.Func)
+					if fn == nil || candidates[fn.Origin()] == nil {
+						return true
+					}
+					fn = fn.Origin()
+					use := candidates[fn]
+					called[name] = true
+					pos := pkg.Fset.Position(n.Pos())
+					use.sites = append(use.sites, CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column})
+					sig := fn.Type().(*types.Signature)
+					if n.Ellipsis != token.NoPos || len(n.Args) != sig.Params().Len() && !sig.Variadic() {
+						// f(xs...) or f(g()) with a multi-valued g.
+						use.fixed = true
+						return true
+					}
+					for ai, arg := range n.Args {
+						if !zeroOrConstant(arg, pkg.TypesInfo) {
+							use.dynamic[min(ai, sig.Params().Len()-1)] = true
+						}
+					}
+				case *ast.Ident:
+					if fn, ok := pkg.TypesInfo.Uses[n].(*types.Func); ok && !called[n] {
+						if use := candidates[fn.Origin()]; use != nil {
+							use.fixed = true // used as a value
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	for fn, use := range candidates {
+		if use.fixed {
+			continue
+		}
+		n := &nodes[index[objToNodeID[fn]]]
+		unused := make(map[string]bool, len(n.UnusedParameters))
+		for _, name := range n.UnusedParameters {
+			unused[name] = true
+		}
+		sig := fn.Type().(*types.Signature)
+		var removable []RemovableParameter
+		for _, p := range n.Parameters {
+			if !unused[p.Name] || use.dynamic[p.Position] || sig.Variadic() && p.Position == sig.Params().Len()-1 {
+				continue
+			}
+			removable = append(removable, RemovableParameter{Name: p.Name, Position: p.Position, CallSites: append([]CallSite{}, use.sites...)})
+		}
+		if len(removable) == 0 {
+			continue
+		}
+		if n.Metadata == nil {
+			n.Metadata = &NodeMetadata{}
+		}
+		n.Metadata.RemovableParameters = removable
+	}
+}
+
+// zeroOrConstant reports whether arg is a constant, nil or an empty
+// composite literal, so that dropping it cannot drop a side effect.
+func zeroOrConstant(arg ast.Expr, info *types.Info) bool {
+	if tv, ok := info.Types[arg]; ok && (tv.Value != nil || tv.IsNil()) {
+		return true
+	}
+	switch e := ast.Unparen(arg).(type) {
+	case *ast.CompositeLit:
+		return len(e.Elts) == 0
+	case *ast.UnaryExpr:
+		lit, ok := ast.Unparen(e.X).(*ast.CompositeLit)
+		return ok && e.Op == token.AND && len(lit.Elts) == 0
+	}
+	return false
+}
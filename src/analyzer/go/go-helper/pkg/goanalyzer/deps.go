@@ -0,0 +1,72 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// sourcePaths names the files of the analyzed packages: project files
+// relative to the project root, and the files of Options.ResolveIntoDeps
+// packages, which live in the module cache, under their import path
+// ("github.com/org/sharedlib/retry/retry.go").
+type sourcePaths struct {
+	root string
+	// deps maps the directory of each dependency package to its import
+	// path.
+	deps map[string]string
+}
+
+func newSourcePaths(absRoot string, deps []*packages.Package) *sourcePaths {
+	s := &sourcePaths{root: absRoot, deps: make(map[string]string)}
+	for _, pkg := range deps {
+		for _, f := range pkg.CompiledGoFiles {
+			s.deps[filepath.Dir(f)] = pkg.PkgPath
+		}
+	}
+	return s
+}
+
+// rel returns the name of the file at absPath used in node IDs and call
+// sites.
+func (s *sourcePaths) rel(absPath string) (string, error) {
+	if path, ok := s.deps[filepath.Dir(absPath)]; ok {
+		return path + "/" + filepath.Base(absPath), nil
+	}
+	return filepath.Rel(s.root, absPath)
+}
+
+// dependencyPackages returns the loaded packages outside the project root,
+// which were loaded for Options.ResolveIntoDeps patterns.
+func dependencyPackages(pkgs []*packages.Package, absRoot string) []*packages.Package {
+	var deps []*packages.Package
+	for _, pkg := range pkgs {
+		if len(pkg.CompiledGoFiles) > 0 && !strings.HasPrefix(pkg.CompiledGoFiles[0], absRoot) {
+			deps = append(deps, pkg)
+		}
+	}
+	return deps
+}
+
+// projectPatterns returns the patterns that load the project's packages:
+// "./..." for the go command, or Options.DriverPatterns.
+func (o Options) projectPatterns() []string {
+	if len(o.DriverPatterns) > 0 {
+		return append([]string(nil), o.DriverPatterns...)
+	}
+	return []string{"./..."}
+}
+
+// loadPatterns returns the go list patterns of the packages to analyze.
+func (o Options) loadPatterns() ([]string, error) {
+	patterns := o.projectPatterns()
+	for _, p := range o.ResolveIntoDeps {
+		if strings.HasPrefix(p, ".") || filepath.IsAbs(p) || strings.HasPrefix(p, "-") {
+			return nil, fmt.Errorf("resolveIntoDeps: %q is not an import path pattern", p)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,177 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// bodyHash hashes the printed body of a function. Printing drops comments
+// and normalizes layout, so only changes to the code itself alter it.
+func bodyHash(fset *token.FileSet, funcDecl *ast.FuncDecl) string {
+	if funcDecl.Body == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, funcDecl.Body); err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// GraphDiff is the change between two analyses of a project, by node.
+type GraphDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	// Changed lists the nodes, by their ID in the new graph, whose body
+	// changed, including moved ones.
+	Changed []string `json:"changed"`
+	// Moved lists the nodes that changed ID, which would otherwise show up
+	// as one removed and one added node.
+	Moved []NodeMove `json:"moved"`
+}
+
+// NodeMove follows a node from the old graph to the new one. Annotations
+// keyed by SymbolID should be re-keyed from FromSymbol to ToSymbol.
+type NodeMove struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	FromSymbol string `json:"fromSymbol,omitempty"`
+	ToSymbol   string `json:"toSymbol,omitempty"`
+	// Reason is how the nodes were matched: MatchSymbol, MatchBody or
+	// MatchSignature.
+	Reason string `json:"reason"`
+	// Renamed is set when the function's name changed.
+	Renamed bool `json:"renamed,omitempty"`
+}
+
+// Reasons for NodeMove.Reason, tried in this order.
+const (
+	// MatchSymbol: same linker symbol, so moved to another file of its
+	// package.
+	MatchSymbol = "symbol"
+	// MatchBody: same kind and body, under another name or package.
+	MatchBody = "body"
+	// MatchSignature: same name, kind and parameter types, with an edited
+	// body, in another file or package.
+	MatchSignature = "signature"
+)
+
+// DiffGraphs compares two graphs of the same project. Nodes present under
+// the same ID are matched first; the remaining ones are matched by
+// SymbolID, then by BodyHash, then by name and signature, each only when
+// the match is unambiguous.
+func DiffGraphs(prev, cur Graph) GraphDiff {
+	diff := GraphDiff{Added: []string{}, Removed: []string{}, Changed: []string{}, Moved: []NodeMove{}}
+	curByID := make(map[string]Node, len(cur.Nodes))
+	for _, n := range cur.Nodes {
+		curByID[n.ID] = n
+	}
+	prevIDs := make(map[string]bool, len(prev.Nodes))
+	var removed []Node
+	for _, old := range prev.Nodes {
+		prevIDs[old.ID] = true
+		n, ok := curByID[old.ID]
+		switch {
+		case !ok:
+			removed = append(removed, old)
+		case old.BodyHash != n.BodyHash:
+			diff.Changed = append(diff.Changed, n.ID)
+		}
+	}
+	var added []Node
+	for _, n := range cur.Nodes {
+		if !prevIDs[n.ID] {
+			added = append(added, n)
+		}
+	}
+
+	for _, m := range []struct {
+		reason string
+		key    func(Node) string
+	}{
+		{MatchSymbol, func(n Node) string { return n.SymbolID }},
+		{MatchBody, func(n Node) string {
+			if n.BodyHash == "" {
+				return ""
+			}
+			return n.Kind + "\t" + n.BodyHash
+		}},
+		{MatchSignature, func(n Node) string { return n.Kind + "\t" + n.Name + "\t" + parameterTypes(n) }},
+	} {
+		removed, added = diff.matchMoves(removed, added, m.reason, m.key)
+	}
+
+	for _, n := range removed {
+		diff.Removed = append(diff.Removed, n.ID)
+	}
+	for _, n := range added {
+		diff.Added = append(diff.Added, n.ID)
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	sort.Slice(diff.Moved, func(i, j int) bool { return diff.Moved[i].From < diff.Moved[j].From })
+	return diff
+}
+
+// matchMoves records as moves the removed and added nodes sharing a key
+// that no other removed or added node has, and returns the unmatched ones.
+func (d *GraphDiff) matchMoves(removed, added []Node, reason string, key func(Node) string) ([]Node, []Node) {
+	count := func(nodes []Node) map[string]int {
+		counts := make(map[string]int)
+		for _, n := range nodes {
+			if k := key(n); k != "" {
+				counts[k]++
+			}
+		}
+		return counts
+	}
+	removedKeys, addedKeys := count(removed), count(added)
+	unique := func(k string) bool { return k != "" && removedKeys[k] == 1 && addedKeys[k] == 1 }
+
+	target := make(map[string]Node)
+	var stillAdded []Node
+	for _, n := range added {
+		if k := key(n); unique(k) {
+			target[k] = n
+		} else {
+			stillAdded = append(stillAdded, n)
+		}
+	}
+	var stillRemoved []Node
+	for _, old := range removed {
+		k := key(old)
+		if !unique(k) {
+			stillRemoved = append(stillRemoved, old)
+			continue
+		}
+		n := target[k]
+		d.Moved = append(d.Moved, NodeMove{
+			From: old.ID, To: n.ID, FromSymbol: old.SymbolID, ToSymbol: n.SymbolID,
+			Reason: reason, Renamed: old.Name != n.Name,
+		})
+		if old.BodyHash != n.BodyHash {
+			d.Changed = append(d.Changed, n.ID)
+		}
+	}
+	return stillRemoved, stillAdded
+}
+
+func parameterTypes(n Node) string {
+	types := make([]string, 0, len(n.Parameters))
+	for _, p := range n.Parameters {
+		t := ""
+		if p.Type != nil {
+			t = *p.Type
+		}
+		types = append(types, t)
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,270 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// digestTop bounds each list of the AISummary.
+const digestTop = 10
+
+// digestReachCandidates bounds the entry points whose reach is computed,
+// taken by decreasing CalleeCount, so that libraries whose every exported
+// function is an entry point stay cheap to digest.
+const digestReachCandidates = 100
+
+// AISummary is a compact digest of the graph, sized to be pasted into a
+// language model prompt about the codebase. Text renders the lists below
+// as a few lines of plain prose.
+type AISummary struct {
+	Text       string            `json:"text"`
+	Functions  int               `json:"functions"`
+	Packages   int               `json:"packages"`
+	Edges      int               `json:"edges"`
+	DeadRatio  float64           `json:"deadRatio"`
+	Entries    []DigestFunction  `json:"entryPoints"`
+	Components []DigestComponent `json:"components"`
+	MostCalled []DigestFunction  `json:"mostCalled"`
+	DeadCode   []DigestPackage   `json:"deadCode"`
+}
+
+// DigestFunction is a function with the count it is ranked by: the
+// functions an entry point reaches, or the callers of a called one.
+type DigestFunction struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// DigestComponent is a set of functions connected by edges in either
+// direction, with its largest packages.
+type DigestComponent struct {
+	Functions int      `json:"functions"`
+	Packages  []string `json:"packages"`
+}
+
+// DigestPackage is the dead code of one package.
+type DigestPackage struct {
+	Package string  `json:"package"`
+	Dead    int     `json:"dead"`
+	Total   int     `json:"total"`
+	Ratio   float64 `json:"ratio"`
+}
+
+// isProjectFunction reports whether n is a function or method of the
+// project, as opposed to a dependency or a synthetic node.
+func isProjectFunction(n Node) bool {
+	return (n.Kind == "function" || n.Kind == "method") && !n.Dependency
+}
+
+func buildAISummary(g *Graph) *AISummary {
+	s := &AISummary{Edges: len(g.Edges), Entries: []DigestFunction{}, Components: []DigestComponent{}, MostCalled: []DigestFunction{}, DeadCode: []DigestPackage{}}
+	index := make(map[string]int, len(g.Nodes))
+	packages := make(map[string]*DigestPackage)
+	dead := 0
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+		if !isProjectFunction(n) {
+			continue
+		}
+		s.Functions++
+		p := packages[n.PackageOrModule]
+		if p == nil {
+			p = &DigestPackage{Package: n.PackageOrModule}
+			packages[n.PackageOrModule] = p
+		}
+		p.Total++
+		if n.Status == "dead" {
+			p.Dead++
+			dead++
+		}
+	}
+	s.Packages = len(packages)
+	if s.Functions > 0 {
+		s.DeadRatio = ratio(dead, s.Functions)
+	}
+
+	callees := make([][]int, len(g.Nodes))
+	parent := make([]int, len(g.Nodes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for _, e := range g.Edges {
+		src, ok := index[e.Source]
+		dst, ok2 := index[e.Target]
+		if !ok || !ok2 {
+			continue
+		}
+		callees[src] = append(callees[src], dst)
+		parent[find(src)] = find(dst)
+	}
+
+	// Entry points, by the project functions they reach.
+	var entries []int
+	for i, n := range g.Nodes {
+		if n.IsEntryPoint && isProjectFunction(n) {
+			entries = append(entries, i)
+		}
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return g.Nodes[entries[a]].CalleeCount > g.Nodes[entries[b]].CalleeCount })
+	if len(entries) > digestReachCandidates {
+		entries = entries[:digestReachCandidates]
+	}
+	for _, entry := range entries {
+		seen := map[int]bool{entry: true}
+		queue := []int{entry}
+		reached := 0
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			for _, c := range callees[i] {
+				if !seen[c] {
+					seen[c] = true
+					queue = append(queue, c)
+					if isProjectFunction(g.Nodes[c]) {
+						reached++
+					}
+				}
+			}
+		}
+		s.Entries = append(s.Entries, DigestFunction{ID: g.Nodes[entry].ID, Count: reached})
+	}
+	s.Entries = topFunctions(s.Entries)
+
+	// Components of more than one project function.
+	members := make(map[int]map[string]int)
+	sizes := make(map[int]int)
+	for i, n := range g.Nodes {
+		if !isProjectFunction(n) {
+			continue
+		}
+		root := find(i)
+		sizes[root]++
+		if members[root] == nil {
+			members[root] = make(map[string]int)
+		}
+		members[root][n.PackageOrModule]++
+	}
+	for root, size := range sizes {
+		if size < 2 {
+			continue
+		}
+		pkgs := make([]string, 0, len(members[root]))
+		for pkg := range members[root] {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Slice(pkgs, func(a, b int) bool {
+			if na, nb := members[root][pkgs[a]], members[root][pkgs[b]]; na != nb {
+				return na > nb
+			}
+			return pkgs[a] < pkgs[b]
+		})
+		if len(pkgs) > 3 {
+			pkgs = pkgs[:3]
+		}
+		s.Components = append(s.Components, DigestComponent{Functions: size, Packages: pkgs})
+	}
+	sort.Slice(s.Components, func(a, b int) bool {
+		ca, cb := s.Components[a], s.Components[b]
+		if ca.Functions != cb.Functions {
+			return ca.Functions > cb.Functions
+		}
+		return ca.Packages[0] < cb.Packages[0]
+	})
+	if len(s.Components) > 5 {
+		s.Components = s.Components[:5]
+	}
+
+	for _, n := range g.Nodes {
+		if isProjectFunction(n) && n.CallerCount > 0 {
+			s.MostCalled = append(s.MostCalled, DigestFunction{ID: n.ID, Count: n.CallerCount})
+		}
+	}
+	s.MostCalled = topFunctions(s.MostCalled)
+
+	for _, p := range packages {
+		if p.Dead > 0 {
+			p.Ratio = ratio(p.Dead, p.Total)
+			s.DeadCode = append(s.DeadCode, *p)
+		}
+	}
+	sort.Slice(s.DeadCode, func(a, b int) bool {
+		pa, pb := s.DeadCode[a], s.DeadCode[b]
+		if pa.Dead != pb.Dead {
+			return pa.Dead > pb.Dead
+		}
+		return pa.Package < pb.Package
+	})
+	if len(s.DeadCode) > digestTop {
+		s.DeadCode = s.DeadCode[:digestTop]
+	}
+
+	s.Text = s.render()
+	return s
+}
+
+// topFunctions sorts by decreasing count, then ID, and keeps digestTop.
+func topFunctions(fns []DigestFunction) []DigestFunction {
+	sort.Slice(fns, func(a, b int) bool {
+		if fns[a].Count != fns[b].Count {
+			return fns[a].Count > fns[b].Count
+		}
+		return fns[a].ID < fns[b].ID
+	})
+	if len(fns) > digestTop {
+		fns = fns[:digestTop]
+	}
+	return fns
+}
+
+// ratio is part/total rounded to three decimals.
+func ratio(part, total int) float64 {
+	return float64(part*1000/total) / 1000
+}
+
+func (s *AISummary) render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d functions in %d packages, %d edges; %.1f%% of the functions are dead.\n", s.Functions, s.Packages, s.Edges, 100*s.DeadRatio)
+	list := func(title string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&b, "%s: %s.\n", title, strings.Join(items, ", "))
+		}
+	}
+	var items []string
+	for _, f := range s.Entries {
+		items = append(items, fmt.Sprintf("%s (reaches %d)", f.ID, f.Count))
+	}
+	list("Main entry points", items)
+	items = nil
+	for _, c := range s.Components {
+		items = append(items, fmt.Sprintf("%d functions in %s", c.Functions, strings.Join(c.Packages, ", ")))
+	}
+	list("Largest connected components", items)
+	items = nil
+	for _, f := range s.MostCalled {
+		items = append(items, fmt.Sprintf("%s (%d callers)", f.ID, f.Count))
+	}
+	list("Most called", items)
+	items = nil
+	for _, p := range s.DeadCode {
+		items = append(items, fmt.Sprintf("%s %d/%d", p.Package, p.Dead, p.Total))
+	}
+	list("Most dead code (dead/total)", items)
+	return b.String()
+}
+
+// refreshAISummary rebuilds the digest if the graph has one, after nodes
+// or edges changed.
+func (g *Graph) refreshAISummary() {
+	if g.AISummary != nil {
+		g.AISummary = buildAISummary(g)
+	}
+}
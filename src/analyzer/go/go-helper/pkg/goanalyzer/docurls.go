@@ -0,0 +1,69 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// defaultDocBaseURL is where Options.DocURLs link to by default.
+const defaultDocBaseURL = "https://pkg.go.dev"
+
+// addDocURLs sets Node.DocURL on exported functions, methods and types, in
+// the form of pkg.go.dev anchors: "<base>/<import path>#Func" or
+// "#Type.Method". Dependency nodes are always linked; the project's own
+// nodes only when its module path starts with a domain, as a module
+// published on a proxy does. Main packages and tests have no symbol docs.
+func addDocURLs(g *Graph, input Options) {
+	base := strings.TrimSuffix(input.DocBaseURL, "/")
+	if base == "" {
+		base = defaultDocBaseURL
+	}
+	first, _, _ := strings.Cut(input.Module, "/")
+	published := strings.Contains(first, ".")
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if !n.Dependency && (!published || isTestFile(n.FilePath)) {
+			continue
+		}
+		if pkgPath, anchor, ok := docAnchor(*n); ok {
+			n.DocURL = base + "/" + pkgPath + "#" + anchor
+		}
+	}
+}
+
+// docAnchor splits the SymbolID of an exported function, method or type
+// into its import path and pkg.go.dev anchor. Import paths may contain dots
+// ("gopkg.in/yaml.v3"), so the symbol is stripped from the end, by name.
+func docAnchor(n Node) (pkgPath, anchor string, ok bool) {
+	if !ast.IsExported(n.Name) || strings.HasPrefix(n.SymbolID, "main.") {
+		return "", "", false
+	}
+	rest, ok := strings.CutSuffix(n.SymbolID, "."+n.Name)
+	if !ok {
+		return "", "", false
+	}
+	switch n.Kind {
+	case "function", "type":
+		return rest, n.Name, rest != ""
+	case "method":
+		var recv string
+		if strings.HasSuffix(rest, ")") {
+			i := strings.LastIndex(rest, ".(*")
+			if i < 0 {
+				return "", "", false
+			}
+			pkgPath, recv = rest[:i], rest[i+len(".(*"):len(rest)-1]
+		} else {
+			i := strings.LastIndex(rest, ".")
+			if i < 0 {
+				return "", "", false
+			}
+			pkgPath, recv = rest[:i], rest[i+1:]
+		}
+		if !ast.IsExported(recv) {
+			return "", "", false
+		}
+		return pkgPath, recv + "." + n.Name, pkgPath != ""
+	}
+	return "", "", false
+}
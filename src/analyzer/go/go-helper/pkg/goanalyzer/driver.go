@@ -0,0 +1,79 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PackagesDriverBazel is the Options.PackagesDriver value that runs the
+// rules_go driver through bazel.
+const PackagesDriverBazel = "bazel"
+
+// bazelDriverScript runs rules_go's packages driver, as rules_go's own
+// tools/gopackagesdriver.sh does. go/packages invokes GOPACKAGESDRIVER as a
+// program, so the command is wrapped in a script.
+const bazelDriverScript = `#!/bin/sh
+exec bazel run --tool_tag=codegraph -- @rules_go//go/tools/gopackagesdriver "$@"
+`
+
+// withPackagesDriver resolves Options.PackagesDriver to the absolute path
+// of the program type-aware loads run as GOPACKAGESDRIVER, writing the
+// bazel script to a temporary directory that cleanup removes.
+func (o Options) withPackagesDriver() (_ Options, cleanup func(), err error) {
+	cleanup = func() {}
+	switch o.PackagesDriver {
+	case "":
+		if len(o.DriverPatterns) > 0 {
+			return o, cleanup, fmt.Errorf("driverPatterns needs packagesDriver")
+		}
+		return o, cleanup, nil
+	case PackagesDriverBazel:
+		dir, err := os.MkdirTemp("", "codegraph-driver-")
+		if err != nil {
+			return o, cleanup, err
+		}
+		o.driver = filepath.Join(dir, "gopackagesdriver.sh")
+		if err := os.WriteFile(o.driver, []byte(bazelDriverScript), 0o755); err != nil {
+			os.RemoveAll(dir)
+			return o, cleanup, err
+		}
+		return o, func() { os.RemoveAll(dir) }, nil
+	}
+	driver, err := filepath.Abs(resolvePath(o.ProjectRoot, o.PackagesDriver))
+	if err != nil {
+		return o, cleanup, err
+	}
+	if _, err := os.Stat(driver); err != nil {
+		return o, cleanup, fmt.Errorf("packagesDriver: %w", err)
+	}
+	o.driver = driver
+	return o, cleanup, nil
+}
+
+// packagesEnv returns the environment of go/packages loads and builds:
+// that of the build context, in GOPATH mode for legacy projects (see
+// withGOPATH), with the toolchain of Options.GoVersion and GOPACKAGESDRIVER
+// set to the packages driver if any, or nil for the current one.
+func (o Options) packagesEnv() []string {
+	var env []string
+	if o.build != nil {
+		env = o.build.env()
+	}
+	if o.gopath != "" {
+		env = o.gopathEnv(env)
+	}
+	if o.GoVersion != "" {
+		if env == nil {
+			env = os.Environ()
+		}
+		env = append(env, o.goToolchainEnv())
+	}
+	if o.driver == "" {
+		return env
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	return append(env, "GOPACKAGESDRIVER="+o.driver)
+}
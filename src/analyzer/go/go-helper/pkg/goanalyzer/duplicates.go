@@ -0,0 +1,100 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// typeQualifier matches the package qualifier of a type name, whether an
+// import path ("*github.com/org/x.Config") or a package name ("*x.Config").
+var typeQualifier = regexp.MustCompile(`\b[A-Za-z_][\w./-]*\.([A-Za-z_]\w*)`)
+
+// conventionalNames are exported function names every package may declare.
+var conventionalNames = regexp.MustCompile(`^(New|Test\w*|Benchmark\w*|Example\w*|Fuzz\w*)$`)
+
+// findDuplicates reports, as advisory "duplicateSymbol" findings, each pair
+// of exported functions in different packages with the same name and the
+// same or nearly the same parameters, as left by copying a package while
+// splitting it. Parameter types are compared without package qualifiers,
+// so that the copy's own types match the original's; nearly the same
+// means one parameter type differs. Conventional names (New, tests) are
+// left out.
+func findDuplicates(g *Graph) []Finding {
+	byName := make(map[string][]*Node)
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Kind != "function" || n.Visibility != "exported" || n.Dependency || conventionalNames.MatchString(n.Name) {
+			continue
+		}
+		byName[n.Name] = append(byName[n.Name], n)
+	}
+	var findings []Finding
+	for name, nodes := range byName {
+		for i, a := range nodes {
+			for _, b := range nodes[i+1:] {
+				if a.PackageOrModule == b.PackageOrModule {
+					continue
+				}
+				differ, ok := parameterDifferences(*a, *b)
+				if !ok {
+					continue
+				}
+				similarity := "the same parameters"
+				if differ > 0 {
+					similarity = "nearly the same parameters"
+				}
+				if a.BodyHash != "" && a.BodyHash == b.BodyHash {
+					similarity += " and body"
+				}
+				first, second := a, b
+				if second.ID < first.ID {
+					first, second = second, first
+				}
+				findings = append(findings, Finding{
+					Kind:    "duplicateSymbol",
+					Rule:    "duplicates",
+					From:    path.Dir(first.FilePath),
+					To:      path.Dir(second.FilePath),
+					Message: fmt.Sprintf("%s is declared in %s and %s with %s; consider consolidating them", name, first.PackageOrModule, second.PackageOrModule, similarity),
+					Edges:   []Edge{},
+					Nodes:   []string{first.ID, second.ID},
+				})
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i].Nodes, findings[j].Nodes
+		return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+	})
+	return findings
+}
+
+// parameterDifferences counts the parameters of a and b whose types differ,
+// ignoring package qualifiers. It reports false when the parameter counts
+// differ or more than one type does, or when a differing parameter is the
+// only one.
+func parameterDifferences(a, b Node) (int, bool) {
+	if len(a.Parameters) != len(b.Parameters) {
+		return 0, false
+	}
+	differ := 0
+	for i, p := range a.Parameters {
+		if unqualifiedType(p.Type) != unqualifiedType(b.Parameters[i].Type) {
+			differ++
+		}
+	}
+	if differ > 1 || differ == 1 && len(a.Parameters) == 1 {
+		return differ, false
+	}
+	return differ, true
+}
+
+func unqualifiedType(t *string) string {
+	if t == nil {
+		return ""
+	}
+	return typeQualifier.ReplaceAllString(strings.ReplaceAll(*t, " ", ""), "$1")
+}
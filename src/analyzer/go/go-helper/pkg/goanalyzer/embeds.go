@@ -0,0 +1,209 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// addEmbedNodes adds a node of Kind "embed" for each //go:embed variable of
+// the project, listing its patterns and the files they match, and an
+// "embed" edge to it from every function reading the variable. Reads in
+// package-level initializers come from the file's __var_init__ node. An
+// embed node no function reaches is dead: its files are shipped but unused.
+func addEmbedNodes(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, nodes *[]Node, edges *[]Edge, debug bool) {
+	embeds := make(map[types.Object]string)
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			relPath = filepath.ToSlash(relPath)
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					doc := vs.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					patterns := embedPatterns(doc)
+					if len(patterns) == 0 || len(vs.Names) != 1 {
+						continue
+					}
+					name := vs.Names[0]
+					obj := pkg.TypesInfo.Defs[name]
+					if obj == nil {
+						continue
+					}
+					n := embedNode(relPath, pkg, name, patterns, pkg.Fset.Position(vs.Pos()).Line, pkg.Fset.Position(vs.End()).Line)
+					embeds[obj] = n.ID
+					*nodes = append(*nodes, n)
+				}
+			}
+		}
+	}
+	if len(embeds) == 0 {
+		return
+	}
+
+	varInits := make(map[string]bool)
+	for _, n := range *nodes {
+		if n.Name == "__var_init__" {
+			varInits[n.ID] = true
+		}
+	}
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			relPath = filepath.ToSlash(relPath)
+			for _, decl := range file.Decls {
+				var sourceID string
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					sourceID = objToNodeID[pkg.TypesInfo.Defs[decl.Name]]
+				case *ast.GenDecl:
+					if decl.Tok == token.VAR {
+						sourceID = relPath + ":__var_init__"
+					}
+				}
+				if sourceID == "" {
+					continue
+				}
+				seen := make(map[string]bool)
+				ast.Inspect(decl, func(n ast.Node) bool {
+					ident, ok := n.(*ast.Ident)
+					if !ok {
+						return true
+					}
+					target, ok := embeds[pkg.TypesInfo.Uses[ident]]
+					if !ok || seen[target] {
+						return true
+					}
+					seen[target] = true
+					if !varInits[sourceID] && strings.HasSuffix(sourceID, ":__var_init__") {
+						varInits[sourceID] = true
+						*nodes = append(*nodes, varInitNode(relPath, pkg.Name))
+					}
+					pos := pkg.Fset.Position(ident.Pos())
+					*edges = append(*edges, Edge{
+						Source:     sourceID,
+						Target:     target,
+						CallSite:   CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column},
+						Kind:       "embed",
+						IsResolved: true,
+						Provenance: newProvenance("embeds", ruleEmbedRead, ident, debug),
+					})
+					return true
+				})
+			}
+		}
+	}
+}
+
+// embedPatterns returns the patterns of the //go:embed directives in doc.
+func embedPatterns(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var patterns []string
+	for _, c := range doc.List {
+		rest, ok := strings.CutPrefix(c.Text, "//go:embed")
+		if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			continue
+		}
+		for _, f := range strings.Fields(rest) {
+			if unquoted, err := strconv.Unquote(f); err == nil {
+				f = unquoted
+			}
+			patterns = append(patterns, f)
+		}
+	}
+	return patterns
+}
+
+func embedNode(relPath string, pkg *packages.Package, name *ast.Ident, patterns []string, start, end int) Node {
+	dir := path.Dir(relPath)
+	pkgDir := filepath.Dir(pkg.Fset.Position(name.Pos()).Filename)
+	var files []string
+	for _, p := range patterns {
+		for _, f := range embeddedFiles(pkgDir, p) {
+			files = append(files, path.Join(dir, f))
+		}
+	}
+	sort.Strings(files)
+	files = slices.Compact(files)
+	visibility := "module"
+	if name.IsExported() {
+		visibility = "exported"
+	}
+	n := Node{
+		ID:               relPath + ":" + name.Name,
+		Name:             name.Name,
+		QualifiedName:    relPath + ":" + name.Name,
+		FilePath:         relPath,
+		StartLine:        start,
+		EndLine:          end,
+		Language:         "go",
+		Kind:             "embed",
+		Visibility:       visibility,
+		Parameters:       []Parameter{},
+		UnusedParameters: []string{},
+		PackageOrModule:  dir,
+		LinesOfCode:      end - start + 1,
+		SymbolID:         pkg.PkgPath + "." + name.Name,
+		EmbedPatterns:    patterns,
+		EmbeddedFiles:    files,
+	}
+	if n.PackageOrModule == "." {
+		n.PackageOrModule = pkg.Name
+	}
+	return n
+}
+
+// embeddedFiles returns the files a //go:embed pattern matches, relative
+// to the package directory. As in the go command, the files of a matched
+// directory are included recursively, except those whose name starts with
+// "." or "_" unless the pattern starts with "all:".
+func embeddedFiles(pkgDir, pattern string) []string {
+	pattern, all := strings.CutPrefix(pattern, "all:")
+	matches, _ := filepath.Glob(filepath.Join(pkgDir, filepath.FromSlash(pattern)))
+	var files []string
+	for _, m := range matches {
+		filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			hidden := strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")
+			if p != m && hidden && !all {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() {
+				if rel, err := filepath.Rel(pkgDir, p); err == nil {
+					files = append(files, filepath.ToSlash(rel))
+				}
+			}
+			return nil
+		})
+	}
+	return files
+}
@@ -0,0 +1,134 @@
+package goanalyzer
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// EndpointDependency is what serving one route touches downstream: the
+// tables, services and queues used by its handler, its middleware and the
+// functions they reach.
+type EndpointDependency struct {
+	Kind    string `json:"kind"`
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+	// Functions counts the project functions serving the route.
+	Functions int `json:"functions"`
+	// Tables are the SQL tables queried, as "OP table" ("SELECT users").
+	Tables []string `json:"tables"`
+	// Services are the hosts of outbound HTTP requests, or the constant
+	// URL prefix when it has no host.
+	Services []string `json:"services"`
+	// Queues are the topics published to or consumed from, as
+	// "publish topic" or "consume topic".
+	Queues []string `json:"queues"`
+}
+
+// withEndpointDependencies turns on what EndpointDependencies needs.
+func (o Options) withEndpointDependencies() Options {
+	if !o.EndpointDependencies {
+		return o
+	}
+	o.Routes = true
+	passes := slices.Clone(o.Passes)
+	for _, p := range []string{passHTTP, passSQL, passQueues} {
+		if !slices.Contains(passes, p) {
+			passes = append(passes, p)
+		}
+	}
+	o.Passes = passes
+	return o
+}
+
+// buildEndpointDependencies joins Graph.Routes with the metadata of the
+// "http", "sql" and "queues" passes over the functions each route reaches.
+// Mock dispatch edges are not followed.
+func buildEndpointDependencies(g *Graph) []EndpointDependency {
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	callees := make([][]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		src, ok := index[e.Source]
+		dst, ok2 := index[e.Target]
+		if ok && ok2 && !e.IsMock {
+			callees[src] = append(callees[src], dst)
+		}
+	}
+
+	deps := []EndpointDependency{}
+	for _, r := range g.Routes {
+		d := EndpointDependency{Kind: r.Kind, Method: r.Method, Path: r.Path, Handler: r.Handler}
+		seen := make(map[int]bool)
+		var queue []int
+		for _, id := range append([]string{r.Handler}, r.Middleware...) {
+			if i, ok := index[id]; ok && !seen[i] {
+				seen[i] = true
+				queue = append(queue, i)
+			}
+		}
+		tables, services, queues := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			n := g.Nodes[i]
+			if isProjectFunction(n) {
+				d.Functions++
+			}
+			if md := n.Metadata; md != nil {
+				for _, q := range md.SQLQueries {
+					for _, t := range q.Tables {
+						tables[q.Op+" "+t] = true
+					}
+				}
+				for _, hc := range md.HTTPCalls {
+					if s := serviceOf(hc.URL); s != "" {
+						services[s] = true
+					}
+				}
+				for _, q := range md.QueueOps {
+					queues[q.Op+" "+q.Topic] = true
+				}
+			}
+			for _, c := range callees[i] {
+				if !seen[c] {
+					seen[c] = true
+					queue = append(queue, c)
+				}
+			}
+		}
+		d.Tables, d.Services, d.Queues = sortedKeys(tables), sortedKeys(services), sortedKeys(queues)
+		deps = append(deps, d)
+	}
+	return deps
+}
+
+// serviceOf names the service an outbound URL points to: its host, or the
+// URL itself when it has none ("/internal/" built on a base URL).
+func serviceOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return strings.TrimSpace(rawURL)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// refreshEndpointDependencies rebuilds the endpoint dependencies if the
+// graph has them, after nodes, edges or routes changed.
+func (g *Graph) refreshEndpointDependencies() {
+	if g.EndpointDependencies != nil {
+		g.EndpointDependencies = buildEndpointDependencies(g)
+	}
+}
@@ -0,0 +1,73 @@
+package goanalyzer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Escape is a value the compiler allocates on the heap rather than the
+// stack.
+type Escape struct {
+	Line int `json:"line"`
+	// Kind is "escapes" for an expression that escapes to the heap, or
+	// "moved" for a variable moved to the heap.
+	Kind string `json:"kind"`
+	// What is the expression or variable, as the compiler prints it.
+	What string `json:"what"`
+}
+
+// annotateEscapes attaches the "escapes to heap" and "moved to heap"
+// diagnostics of go build -gcflags=-m=2 to the innermost function of the
+// graph declared around their line. The explanations -m=2 indents below
+// each diagnostic are left out.
+func annotateEscapes(g *Graph, diags []compilerDiagnostic) {
+	enclosing := enclosingFunction(g)
+	seen := make(map[*Node]map[Escape]bool)
+	for _, d := range diags {
+		msg := strings.TrimSuffix(d.Message, ":")
+		var e Escape
+		if what, ok := strings.CutSuffix(msg, " escapes to heap"); ok {
+			e = Escape{Line: d.Line, Kind: "escapes", What: what}
+		} else if what, ok := strings.CutPrefix(msg, "moved to heap: "); ok {
+			e = Escape{Line: d.Line, Kind: "moved", What: what}
+		} else {
+			continue
+		}
+		owner := enclosing(d.FilePath, d.Line)
+		if owner == nil {
+			continue
+		}
+		if seen[owner] == nil {
+			seen[owner] = make(map[Escape]bool)
+		}
+		// Generic functions are reported once per instantiation.
+		if !seen[owner][e] {
+			seen[owner][e] = true
+			owner.Escapes = append(owner.Escapes, e)
+		}
+	}
+	for n := range seen {
+		sort.SliceStable(n.Escapes, func(i, j int) bool { return n.Escapes[i].Line < n.Escapes[j].Line })
+	}
+}
+
+// enclosingFunction returns a lookup of the innermost project function of
+// g declared around a line of a file, or nil.
+func enclosingFunction(g *Graph) func(file string, line int) *Node {
+	byFile := make(map[string][]int)
+	for i, n := range g.Nodes {
+		if (n.Kind == "function" || n.Kind == "method") && !n.Dependency && n.FilePath != "" {
+			byFile[n.FilePath] = append(byFile[n.FilePath], i)
+		}
+	}
+	return func(file string, line int) *Node {
+		var owner *Node
+		for _, i := range byFile[file] {
+			n := &g.Nodes[i]
+			if n.StartLine <= line && line <= n.EndLine && (owner == nil || n.EndLine-n.StartLine < owner.EndLine-owner.StartLine) {
+				owner = n
+			}
+		}
+		return owner
+	}
+}
@@ -0,0 +1,94 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultUsageExamples is the number of usage examples kept per function
+// when Options.MaxUsageExamples is unset.
+const defaultUsageExamples = 3
+
+// UsageExample is a call to an exported function found in the project.
+type UsageExample struct {
+	// Caller is the node ID of the calling function.
+	Caller   string `json:"caller"`
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	// Snippet is the calling line, trimmed and shortened like
+	// Provenance.Snippet.
+	Snippet string `json:"snippet"`
+}
+
+// collectUsageExamples records, for the "examples" pass, up to
+// Options.MaxUsageExamples call sites of each exported function and method
+// of an exported type of the project in its Metadata. Calls from other
+// packages come first, as they show the function used as an API.
+func collectUsageExamples(g *Graph, input Options) {
+	limit := input.MaxUsageExamples
+	if limit <= 0 {
+		limit = defaultUsageExamples
+	}
+	nodes := make(map[string]*Node, len(g.Nodes))
+	for i := range g.Nodes {
+		nodes[g.Nodes[i].ID] = &g.Nodes[i]
+	}
+	byTarget := make(map[string][]UsageExample)
+	for _, e := range g.Edges {
+		target, caller := nodes[e.Target], nodes[e.Source]
+		if target == nil || caller == nil || e.Source == e.Target || e.CallSite.Line <= 0 || target.Dependency {
+			continue
+		}
+		// Exported functions and methods of exported types, outside main
+		// packages, are those with documentation anchors.
+		if _, _, ok := docAnchor(*target); !ok || target.Kind == "type" {
+			continue
+		}
+		byTarget[e.Target] = append(byTarget[e.Target], UsageExample{Caller: e.Source, FilePath: e.CallSite.FilePath, Line: e.CallSite.Line})
+	}
+
+	absRoot, _ := filepath.Abs(input.ProjectRoot)
+	overlay := input.absOverlays()
+	lines := make(map[string][][]byte)
+	snippet := func(file string, line int) string {
+		src, ok := lines[file]
+		if !ok {
+			data, err := readSource(overlay, filepath.Join(absRoot, filepath.FromSlash(file)))
+			if err == nil {
+				src = bytes.Split(data, []byte("\n"))
+			}
+			lines[file] = src
+		}
+		if line > len(src) {
+			return ""
+		}
+		return shortSnippet(strings.TrimSpace(string(src[line-1])))
+	}
+
+	for id, examples := range byTarget {
+		target := nodes[id]
+		external := func(ex UsageExample) bool { return nodes[ex.Caller].PackageOrModule != target.PackageOrModule }
+		sort.Slice(examples, func(a, b int) bool {
+			ea, eb := examples[a], examples[b]
+			if xa, xb := external(ea), external(eb); xa != xb {
+				return xa
+			}
+			if ea.FilePath != eb.FilePath {
+				return ea.FilePath < eb.FilePath
+			}
+			return ea.Line < eb.Line
+		})
+		if len(examples) > limit {
+			examples = examples[:limit]
+		}
+		for i := range examples {
+			examples[i].Snippet = snippet(examples[i].FilePath, examples[i].Line)
+		}
+		if target.Metadata == nil {
+			target.Metadata = &NodeMetadata{}
+		}
+		target.Metadata.UsageExamples = examples
+	}
+}
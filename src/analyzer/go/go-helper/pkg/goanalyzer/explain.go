@@ -0,0 +1,247 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExplainRequest asks the analyzer to explain one edge, or one node's
+// status, in Graph.Explanation.
+type ExplainRequest struct {
+	Edge *EdgeRef `json:"edge,omitempty"`
+	Node string   `json:"node,omitempty"`
+}
+
+// EdgeRef names an edge by its endpoints' node IDs.
+type EdgeRef struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Explanation is a human-readable account of how the analyzer arrived at
+// an edge or a node's status.
+type Explanation struct {
+	Subject string `json:"subject"`
+	// Conclusion is a one-line answer; Trace lists the decisions behind it.
+	Conclusion string   `json:"conclusion"`
+	Trace      []string `json:"trace"`
+}
+
+// explainer collects, while calls are resolved, the decisions taken for the
+// function under explanation. It is carried in Stats; nil when no
+// explanation was requested.
+type explainer struct {
+	req    ExplainRequest
+	source string // node ID whose resolution is traced
+	events []explainEvent
+}
+
+type explainEvent struct {
+	text    string
+	targets []string // node IDs the decision produced edges to
+}
+
+func newExplainer(req *ExplainRequest) *explainer {
+	if req == nil {
+		return nil
+	}
+	x := &explainer{req: *req}
+	if req.Edge != nil {
+		x.source = req.Edge.Source
+	}
+	return x
+}
+
+// watching reports whether decisions taken in sourceID are being traced.
+func (x *explainer) watching(sourceID string) bool {
+	return x != nil && x.source != "" && x.source == sourceID
+}
+
+// record adds a decision at pos that produced edges to targets (none if
+// the call was left unresolved).
+func (x *explainer) record(pos token.Position, targets []string, format string, args ...any) {
+	text := fmt.Sprintf(format, args...)
+	if pos.Line > 0 {
+		text = fmt.Sprintf("line %d: %s", pos.Line, text)
+	}
+	x.events = append(x.events, explainEvent{text: text, targets: targets})
+}
+
+// explainTarget describes the node a resolved object maps to.
+func explainTarget(obj types.Object, targetID string) string {
+	if targetID == "" {
+		return obj.String() + ", which is outside the project"
+	}
+	return obj.String() + " (" + targetID + ")"
+}
+
+// explain fills in the requested explanation once the graph is final.
+func (x *explainer) explain(g *Graph) *Explanation {
+	if x == nil {
+		return nil
+	}
+	nodes := make(map[string]*Node, len(g.Nodes))
+	for i := range g.Nodes {
+		nodes[g.Nodes[i].ID] = &g.Nodes[i]
+	}
+	if x.req.Edge != nil {
+		return x.explainEdge(g, nodes, *x.req.Edge)
+	}
+	return explainNode(g, nodes, x.req.Node)
+}
+
+func (x *explainer) explainEdge(g *Graph, nodes map[string]*Node, ref EdgeRef) *Explanation {
+	ex := &Explanation{Subject: ref.Source + " -> " + ref.Target, Trace: []string{}}
+	if nodes[ref.Source] == nil {
+		ex.Conclusion = "No edge: the source is not a node of the graph."
+		return ex
+	}
+	if nodes[ref.Target] == nil {
+		ex.Conclusion = "No edge: the target is not a node of the graph."
+	}
+
+	var found []Edge
+	for _, e := range g.Edges {
+		if e.Source == ref.Source && e.Target == ref.Target {
+			found = append(found, e)
+		}
+	}
+	for _, e := range found {
+		line := fmt.Sprintf("edge of kind %q from phase %q, rule %q", e.Kind, e.Provenance.Phase, e.Provenance.Rule)
+		if e.CallSite.FilePath != "" {
+			line += fmt.Sprintf(", at %s:%d", e.CallSite.FilePath, e.CallSite.Line)
+		}
+		ex.Trace = append(ex.Trace, line)
+	}
+
+	// Decisions that produced this edge, or every decision if none did.
+	var related []string
+	for _, ev := range x.events {
+		for _, t := range ev.targets {
+			if t == ref.Target {
+				related = append(related, ev.text)
+				break
+			}
+		}
+	}
+	if len(found) == 0 || len(related) == 0 {
+		for _, ev := range x.events {
+			related = append(related, ev.text)
+		}
+	}
+	ex.Trace = append(ex.Trace, related...)
+
+	switch {
+	case ex.Conclusion != "":
+	case len(found) > 0:
+		ex.Conclusion = fmt.Sprintf("Edge exists (%d found).", len(found))
+	case len(x.events) == 0:
+		ex.Conclusion = "No edge: no call or function reference in the source was examined."
+	default:
+		ex.Conclusion = "No edge: none of the calls or references in the source resolves to the target."
+	}
+	return ex
+}
+
+// explainNode accounts for a node's entry-point flag, its callers and the
+// shortest path reaching it from an entry point, which together decide
+// whether it is live.
+func explainNode(g *Graph, nodes map[string]*Node, id string) *Explanation {
+	ex := &Explanation{Subject: id, Trace: []string{}}
+	n := nodes[id]
+	if n == nil {
+		ex.Conclusion = "Not a node of the graph."
+		return ex
+	}
+	if n.IsEntryPoint {
+		ex.Trace = append(ex.Trace, "entry point: "+entryReason(*n))
+	}
+	switch n.KeptBy {
+	case "":
+	case keptByDirective:
+		ex.Trace = append(ex.Trace, "kept live by a "+directiveKeep+" directive")
+	default:
+		ex.Trace = append(ex.Trace, "kept live by the keep pattern "+strconv.Quote(n.KeptBy))
+	}
+
+	callers := make(map[string][]Edge)
+	for _, e := range g.Edges {
+		callers[e.Target] = append(callers[e.Target], e)
+	}
+	for _, e := range callers[id] {
+		ex.Trace = append(ex.Trace, fmt.Sprintf("called by %s (%s, %s/%s)", e.Source, e.Kind, e.Provenance.Phase, e.Provenance.Rule))
+	}
+
+	path := pathFromEntry(nodes, callers, id)
+	switch {
+	case n.IsEntryPoint:
+		ex.Conclusion = "Live: it is an entry point."
+	case n.KeptBy != "":
+		ex.Conclusion = "Live: it is kept regardless of its callers."
+	case n.Status == "test-only" && path != nil:
+		ex.Trace = append(ex.Trace, "reached via "+strings.Join(path, " -> "))
+		ex.Conclusion = "Test-only: reachable only from tests, such as " + path[0] + "."
+	case n.Status == "test-only":
+		ex.Conclusion = "Test-only: reachable only from tests, whose functions are not in the output."
+	case path != nil:
+		ex.Trace = append(ex.Trace, "reached via "+strings.Join(path, " -> "))
+		ex.Conclusion = "Live: reachable from " + path[0] + "."
+	case len(callers[id]) > 0:
+		ex.Conclusion = "Dead: it has callers, but none is reachable from an entry point or kept function."
+	default:
+		ex.Conclusion = "Dead: nothing calls or references it, and it is not an entry point."
+	}
+	return ex
+}
+
+func entryReason(n Node) string {
+	switch {
+	case n.RegisteredBy == "plugin":
+		return "exported by a plugin package, looked up by name at run time"
+	case n.RegisteredBy == "go-plugin":
+		return "served over RPC by go-plugin's plugin.Serve"
+	case n.RegisteredBy != "":
+		return "registered per the registry rule " + strconv.Quote(n.RegisteredBy)
+	case n.Kind == "init":
+		return "package-level initializer"
+	case n.Name == "main":
+		return "main function of package main"
+	case n.Name == "init":
+		return "init function"
+	case strings.HasPrefix(n.Name, "Test") || strings.HasPrefix(n.Name, "Benchmark") || strings.HasPrefix(n.Name, "Example"):
+		return "test, benchmark or example function"
+	default:
+		return "marked with " + directiveEntrypoint
+	}
+}
+
+// pathFromEntry walks callers breadth-first from id and returns the
+// shortest call path from an entry point or kept node to id, or nil.
+func pathFromEntry(nodes map[string]*Node, callers map[string][]Edge, id string) []string {
+	next := map[string]string{id: ""}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if n := nodes[cur]; n != nil && (n.IsEntryPoint || n.KeptBy != "") && cur != id {
+			path := []string{cur}
+			for p := next[cur]; p != ""; p = next[p] {
+				path = append(path, p)
+			}
+			return path
+		}
+		in := callers[cur]
+		sort.Slice(in, func(i, j int) bool { return in[i].Source < in[j].Source })
+		for _, e := range in {
+			if _, seen := next[e.Source]; !seen {
+				next[e.Source] = cur
+				queue = append(queue, e.Source)
+			}
+		}
+	}
+	return nil
+}
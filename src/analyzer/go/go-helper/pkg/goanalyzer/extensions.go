@@ -0,0 +1,142 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Extension is a user-provided enrichment program run after the core graph is
+// built. It receives the Graph as JSON on stdin and writes an
+// ExtensionResult as JSON to stdout.
+type Extension struct {
+	// Name keys the extension's annotations on nodes; defaults to Command.
+	Name      string   `json:"name,omitempty"`
+	Command   string   `json:"command"`
+	Args      []string `json:"args,omitempty"`
+	TimeoutMs int      `json:"timeoutMs,omitempty"`
+}
+
+// ExtensionResult is what an extension returns: additional nodes and edges to
+// merge, and free-form annotations keyed by existing node ID.
+type ExtensionResult struct {
+	Nodes       []Node                     `json:"nodes"`
+	Edges       []Edge                     `json:"edges"`
+	Annotations map[string]json.RawMessage `json:"annotations"`
+}
+
+const defaultExtensionTimeout = 60 * time.Second
+
+// runExtensions runs each configured extension in order, merging its result
+// into output before the next one runs. A failing extension is reported and
+// skipped.
+func runExtensions(ctx context.Context, output *Graph, input Options) {
+	for _, ext := range input.Extensions {
+		name := ext.Name
+		if name == "" {
+			name = ext.Command
+		}
+		result, err := runExtension(ctx, ext, output, input)
+		if err != nil {
+			input.warnf("extension %s failed: %v", name, err)
+			continue
+		}
+		mergeExtensionResult(output, name, result)
+	}
+}
+
+func runExtension(ctx context.Context, ext Extension, output *Graph, input Options) (*ExtensionResult, error) {
+	if ext.Command == "" {
+		return nil, fmt.Errorf("no command configured")
+	}
+	timeout := defaultExtensionTimeout
+	if ext.TimeoutMs > 0 {
+		timeout = time.Duration(ext.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, ext.Command, ext.Args...)
+	cmd.Dir = input.ProjectRoot
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = input.Log
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %v", timeout)
+		}
+		return nil, err
+	}
+
+	var result ExtensionResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	return &result, nil
+}
+
+// mergeExtensionResult adds nodes whose IDs are new, edges that don't already
+// exist (same source, target and kind), and attaches annotations to the nodes
+// they name under the extension's name.
+func mergeExtensionResult(output *Graph, name string, result *ExtensionResult) {
+	nodeIdx := make(map[string]int, len(output.Nodes))
+	for i, n := range output.Nodes {
+		nodeIdx[n.ID] = i
+	}
+	for _, n := range result.Nodes {
+		if n.ID == "" {
+			continue
+		}
+		if _, exists := nodeIdx[n.ID]; exists {
+			continue
+		}
+		if n.Language == "" {
+			n.Language = "go"
+		}
+		if n.Parameters == nil {
+			n.Parameters = []Parameter{}
+		}
+		if n.UnusedParameters == nil {
+			n.UnusedParameters = []string{}
+		}
+		if n.Status == "" {
+			n.Status, n.Color = "dead", "red"
+		}
+		nodeIdx[n.ID] = len(output.Nodes)
+		output.Nodes = append(output.Nodes, n)
+	}
+
+	type edgeKey struct{ source, target, kind string }
+	edges := make(map[edgeKey]bool, len(output.Edges))
+	for _, e := range output.Edges {
+		edges[edgeKey{e.Source, e.Target, e.Kind}] = true
+	}
+	for _, e := range result.Edges {
+		key := edgeKey{e.Source, e.Target, e.Kind}
+		if e.Source == "" || e.Target == "" || edges[key] {
+			continue
+		}
+		edges[key] = true
+		output.Edges = append(output.Edges, e)
+	}
+
+	for id, data := range result.Annotations {
+		i, ok := nodeIdx[id]
+		if !ok {
+			continue
+		}
+		if output.Nodes[i].Extensions == nil {
+			output.Nodes[i].Extensions = make(map[string]json.RawMessage)
+		}
+		output.Nodes[i].Extensions[name] = data
+	}
+}
@@ -0,0 +1,86 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// fanoutNodes collects the interface methods with more implementations than
+// Options.MaxFanout. Calls to such a method get a single edge to a synthetic
+// "interface:<symbol>" node, which has one edge to each implementation, so
+// that n call sites cost n + impls edges instead of n × impls.
+type fanoutNodes struct {
+	limit int
+	byID  map[string]*fanoutNode
+	order []string
+}
+
+type fanoutNode struct {
+	// method is "Service.Process".
+	method string
+	impls  []string
+	// site is the first call site, where the diagnostic is reported.
+	site CallSite
+}
+
+// newFanoutNodes returns nil when limit is not positive, which leaves every
+// fan-out alone.
+func newFanoutNodes(limit int) *fanoutNodes {
+	if limit <= 0 {
+		return nil
+	}
+	return &fanoutNodes{limit: limit, byID: make(map[string]*fanoutNode)}
+}
+
+// over reports whether an interface call dispatching to n implementations
+// goes through a synthetic node.
+func (f *fanoutNodes) over(n int) bool {
+	return f != nil && n > f.limit
+}
+
+// add records a call to method dispatching to impls (node IDs) and returns
+// the ID of the synthetic node to link the call to.
+func (f *fanoutNodes) add(method *types.Func, impls []string, site CallSite) string {
+	id := "interface:" + funcSymbol(method)
+	if _, ok := f.byID[id]; !ok {
+		name := method.Name()
+		if recv := method.Type().(*types.Signature).Recv(); recv != nil {
+			if named, ok := recv.Type().(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+		f.byID[id] = &fanoutNode{method: name, impls: impls, site: site}
+		f.order = append(f.order, id)
+	}
+	return id
+}
+
+// emit adds the synthetic nodes, their edges to the implementations and a
+// diagnostic for each.
+func (f *fanoutNodes) emit(nodes *[]Node, edges *[]Edge, diagnostics *[]Diagnostic) {
+	if f == nil {
+		return
+	}
+	for _, id := range f.order {
+		fn := f.byID[id]
+		node := externalNode(id, fmt.Sprintf("%s (%d impls)", fn.method, len(fn.impls)), symbolPackage(id[len("interface:"):]))
+		node.Kind = "interface"
+		*nodes = append(*nodes, node)
+		for _, impl := range fn.impls {
+			*edges = append(*edges, Edge{
+				Source:     id,
+				Target:     impl,
+				Kind:       "interface",
+				IsResolved: true,
+				Provenance: Provenance{Phase: "calls", Rule: ruleFanoutImpl},
+			})
+		}
+		*diagnostics = append(*diagnostics, Diagnostic{
+			Kind:     "interfaceFanout",
+			FilePath: fn.site.FilePath,
+			Line:     fn.site.Line,
+			Column:   fn.site.Column,
+			Message:  fmt.Sprintf("%s has %d implementations, more than maxFanout %d; its calls go through %s", fn.method, len(fn.impls), f.limit, id),
+		})
+	}
+}
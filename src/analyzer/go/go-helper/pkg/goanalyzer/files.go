@@ -0,0 +1,283 @@
+package goanalyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultExclude mirrors the CodeGraph CLI's default exclude patterns for Go.
+var DefaultExclude = []string{
+	"node_modules/**",
+	"dist/**",
+	"build/**",
+	"vendor/**",
+	".git/**",
+	"**/*_test.go",
+}
+
+// DiscoverFiles returns the .go files under root, relative to it and
+// slash-separated, skipping those matching any exclude glob. Globs follow the
+// CLI's syntax: "*" matches within a path segment, "**" across segments.
+func DiscoverFiles(root string, exclude []string) ([]string, error) {
+	excluded := globMatcher(exclude)
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			// A directory is skipped when everything below it would be.
+			if rel != "." && excluded(rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(rel, ".go") && !excluded(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// FilterFiles returns the files that match none of the exclude globs.
+func FilterFiles(files, exclude []string) []string {
+	excluded := globMatcher(exclude)
+	var kept []string
+	for _, f := range files {
+		if !excluded(filepath.ToSlash(f)) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// ExpandFiles resolves Files to the list of project-relative, slash-separated
+// paths the analysis uses: entries of the FilesFrom manifest are added, and
+// glob entries ("internal/**/*.go") are replaced by the .go files matching
+// them. The result has no globs and no FilesFrom, so expanding it again
+// changes nothing.
+func (o Options) ExpandFiles() (Options, error) {
+	entries := o.Files
+	if o.FilesFrom != "" {
+		manifest, err := readManifest(resolvePath(o.ProjectRoot, o.FilesFrom))
+		if err != nil {
+			return o, fmt.Errorf("filesFrom: %w", err)
+		}
+		entries = append(append([]string(nil), entries...), manifest...)
+		o.FilesFrom = ""
+	}
+
+	absRoot, _ := filepath.Abs(o.ProjectRoot)
+	var files, globs []string
+	for _, f := range entries {
+		if filepath.IsAbs(f) {
+			if rel, err := filepath.Rel(absRoot, f); err == nil {
+				f = rel
+			}
+		}
+		if f = cleanRel(f); strings.ContainsAny(f, "*?") {
+			globs = append(globs, f)
+		} else {
+			files = append(files, f)
+		}
+	}
+	if len(globs) > 0 {
+		all, err := DiscoverFiles(o.ProjectRoot, []string{".git/**"})
+		if err != nil {
+			return o, err
+		}
+		match := globMatcher(globs)
+		for _, f := range all {
+			if match(f) {
+				files = append(files, f)
+			}
+		}
+	}
+	seen := make(map[string]bool, len(files))
+	o.Files = files[:0]
+	for _, f := range files {
+		if !seen[f] {
+			seen[f] = true
+			o.Files = append(o.Files, f)
+		}
+	}
+	return o, nil
+}
+
+// readManifest reads a file list: one path or glob per line, ignoring blank
+// lines and "#" comments.
+func readManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// Manifests can list hundreds of thousands of files: read them a line
+	// at a time rather than whole.
+	var entries []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// RestrictToFiles drops nodes declared outside files, and edges touching
+// them. Analyze applies it to type-aware results, which cover every package
+// under the root, when Options.Files is set. Synthetic nodes without a file
+// and Dependency nodes are kept, and the summary, per-kind stats, findings,
+// references, new dead functions, hashes, policy, search index, hierarchy
+// and bundles are updated.
+func (g *Graph) RestrictToFiles(files []string) {
+	allowed := make(map[string]bool, len(files))
+	for _, f := range files {
+		allowed[f] = true
+	}
+	g.retain(func(n Node) bool { return n.FilePath == "" || n.Dependency || allowed[n.FilePath] })
+}
+
+// retain drops the nodes keep rejects, edges, findings, references and
+// routes touching them and their new dead functions, and updates the derived data of the graph.
+func (g *Graph) retain(keep func(Node) bool) {
+	kept := make(map[string]bool, len(g.Nodes))
+	nodes := g.Nodes[:0]
+	for _, n := range g.Nodes {
+		if keep(n) {
+			kept[n.ID] = true
+			nodes = append(nodes, n)
+		}
+	}
+	g.Nodes = nodes
+	edges := g.Edges[:0]
+	for _, e := range g.Edges {
+		if kept[e.Source] && kept[e.Target] {
+			edges = append(edges, e)
+		}
+	}
+	g.Edges = edges
+	findings := g.Findings[:0]
+	for _, f := range g.Findings {
+		if len(f.Nodes) > 0 {
+			// A finding about declarations needs all of them.
+			if !slices.ContainsFunc(f.Nodes, func(id string) bool { return !kept[id] }) {
+				findings = append(findings, f)
+			}
+			continue
+		}
+		offending := f.Edges[:0]
+		for _, e := range f.Edges {
+			if kept[e.Source] && kept[e.Target] {
+				offending = append(offending, e)
+			}
+		}
+		if f.Edges = offending; len(f.Edges) > 0 {
+			findings = append(findings, f)
+		}
+	}
+	g.Findings = findings
+	g.countCalls()
+	if g.References != nil {
+		refs := g.References[:0]
+		for _, r := range g.References {
+			if kept[r.Target] {
+				if !kept[r.Source] {
+					r.Source = ""
+				}
+				refs = append(refs, r)
+			}
+		}
+		g.References = refs
+	}
+	if g.Routes != nil {
+		routes := g.Routes[:0]
+		for _, r := range g.Routes {
+			if kept[r.Handler] {
+				routes = append(routes, r)
+			}
+		}
+		g.Routes = routes
+	}
+	for i := range g.LinkerVars {
+		v := &g.LinkerVars[i]
+		v.ReadBy = slices.DeleteFunc(v.ReadBy, func(id string) bool { return !kept[id] })
+	}
+	if g.DeadCode != nil {
+		added := g.DeadCode.New[:0]
+		for _, id := range g.DeadCode.New {
+			if kept[id] {
+				added = append(added, id)
+			}
+		}
+		g.DeadCode.New = added
+	}
+	if g.Summary != nil {
+		g.Summary = buildSummary(g)
+	}
+	if g.Stats != nil {
+		g.Stats.finish(g)
+	}
+	if g.GraphHash != "" {
+		g.computeHashes()
+	}
+	g.Policy.evaluate(g)
+	g.refreshSearchIndex()
+	g.refreshHierarchy()
+	g.refreshBundles()
+	g.refreshAISummary()
+	g.refreshEndpointDependencies()
+}
+
+func globMatcher(globs []string) func(string) bool {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		patterns = append(patterns, globRegexp(glob))
+	}
+	return func(rel string) bool {
+		for _, re := range patterns {
+			if re.MatchString(rel) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// globRegexp translates a glob into an anchored regular expression.
+// "dir/**" also matches "dir/" itself so whole directories can be pruned.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
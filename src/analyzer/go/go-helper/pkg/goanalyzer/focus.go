@@ -0,0 +1,302 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Delta is the change to a previous graph after one function was re-analyzed
+// by Reanalyze.
+type Delta struct {
+	NodeID string `json:"nodeId"`
+	// Node is the function as now declared, or nil if it no longer exists
+	// (deleted, renamed or moved to another receiver).
+	Node *Node `json:"node"`
+	// SignatureChanged reports that the parameter list differs from the
+	// previous graph. IncomingEdges then holds the edges into the function,
+	// whose call sites may no longer match it.
+	SignatureChanged bool   `json:"signatureChanged,omitempty"`
+	IncomingEdges    []Edge `json:"incomingEdges,omitempty"`
+	AddedEdges       []Edge `json:"addedEdges"`
+	RemovedEdges     []Edge `json:"removedEdges"`
+	// Diagnostics reports a syntax error in the function's file; the node
+	// and its edges are then approximate.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// Reanalyze re-parses only the file declaring nodeID in prev and recomputes
+// the function's outgoing call edges, for low-latency feedback while the
+// function is being edited. Calls are resolved by name against the nodes of
+// prev, as in AST-only analysis. Edges to targets prev already reached keep
+// their kind, which may come from type information, and edges that cannot be
+// derived from syntax alone (interface dispatch, function values, synthetic
+// edges) are left in place. When the function is gone its incoming edges are
+// removed too. Only function and method nodes can be re-analyzed. opts
+// supplies ProjectRoot, Module, Overlays, PathMappings and Passes.
+func Reanalyze(opts Options, prev Graph, nodeID string) (Delta, error) {
+	opts = opts.MapPaths()
+	var old *Node
+	for i := range prev.Nodes {
+		if prev.Nodes[i].ID == nodeID {
+			old = &prev.Nodes[i]
+			break
+		}
+	}
+	if old == nil {
+		return Delta{}, fmt.Errorf("node %q not found in the previous graph", nodeID)
+	}
+	if old.FilePath == "" || !strings.HasSuffix(old.FilePath, ".go") {
+		return Delta{}, fmt.Errorf("node %q is not declared in a Go file", nodeID)
+	}
+	if old.Kind != "function" && old.Kind != "method" {
+		// Synthetic nodes (__var_init__, embeds, routes) are not declared
+		// by a function, so re-parsing the file would not find them.
+		return Delta{}, fmt.Errorf("node %q (kind %q) is not a function or method", nodeID, old.Kind)
+	}
+
+	relPath := old.FilePath
+	absPath := resolvePath(opts.ProjectRoot, relPath)
+	src, ok := opts.relOverlays()[cleanRel(relPath)]
+	if !ok {
+		var err error
+		if src, err = os.ReadFile(absPath); err != nil {
+			return Delta{}, err
+		}
+	}
+
+	delta := Delta{NodeID: nodeID}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, absPath, src, parser.ParseComments)
+	broken := err != nil
+	if broken {
+		delta.Diagnostics = []Diagnostic{diagnosticFromScanErr(relPath, err)}
+		if f = recoverFuncDecls(fset, absPath, src, f, old.PackageOrModule); f == nil {
+			return delta, fmt.Errorf("%s: %w", relPath, err)
+		}
+	}
+
+	pkgName := f.Name.Name
+	pkgPath := opts.Module
+	if dir := filepath.ToSlash(filepath.Dir(relPath)); dir != "." {
+		pkgPath = opts.Module + "/" + dir
+	}
+	shared := newPassShared(enabledPasses(opts))
+	shared.scanFile(f, newPassContext(f, fset, nil, shared))
+	fileNodes := extractNodes(f, fset, relPath, pkgName, pkgPath)
+	annotateFileNodes(f, fset, fileNodes, shared)
+	if broken {
+		for i := range fileNodes {
+			fileNodes[i].Approximate = true
+		}
+		clampBrokenExtents(fileNodes, src)
+	}
+
+	// Resolve by name against the rest of the previous graph and the
+	// file as it is now.
+	funcMap := make(map[string]*Node, 2*(len(prev.Nodes)+len(fileNodes)))
+	for i := range prev.Nodes {
+		if n := &prev.Nodes[i]; n.FilePath != relPath {
+			funcMap[n.ID] = n
+			funcMap[n.Name] = n
+		}
+	}
+	for i := range fileNodes {
+		n := &fileNodes[i]
+		funcMap[n.ID] = n
+		funcMap[n.Name] = n
+		if n.ID == nodeID {
+			updated := *n
+			// Runtime overlays and extension output are not recomputed.
+			updated.Status, updated.Color = old.Status, old.Color
+			updated.IsEntryPoint = updated.IsEntryPoint || old.IsEntryPoint
+			updated.RegisteredBy = old.RegisteredBy
+			if updated.KeptBy == "" {
+				updated.KeptBy = keepMatcher(opts.Keep)(updated)
+			}
+			updated.Profile, updated.ObservedAtRuntime = old.Profile, old.ObservedAtRuntime
+			updated.Inlining, updated.Escapes = old.Inlining, old.Escapes
+			updated.LintIssues = old.LintIssues
+			updated.DocURL = old.DocURL
+			updated.Extensions = old.Extensions
+			updated.StatusOverride, updated.Keep = old.StatusOverride, old.Keep
+			delta.Node = &updated
+		}
+	}
+
+	var outgoing []Edge
+	if delta.Node != nil {
+		for _, e := range extractEdges(f, fset, relPath, pkgName, funcMap, opts.Debug, &Stats{}) {
+			if e.Source == nodeID {
+				outgoing = append(outgoing, e)
+			}
+		}
+	}
+	if prev.Stats == nil || prev.Stats.Algorithm != AlgorithmAST {
+		outgoing = mergeCallSites(outgoing)
+	}
+	delta.diffOutgoing(prev.Edges, outgoing)
+
+	if delta.Node == nil {
+		for _, e := range prev.Edges {
+			if e.Target == nodeID && e.Source != nodeID {
+				delta.RemovedEdges = append(delta.RemovedEdges, e)
+			}
+		}
+	} else if !sameSignature(*old, *delta.Node, prev.Stats) {
+		delta.SignatureChanged = true
+		for _, e := range prev.Edges {
+			if e.Target == nodeID {
+				delta.IncomingEdges = append(delta.IncomingEdges, e)
+			}
+		}
+	}
+
+	if delta.AddedEdges == nil {
+		delta.AddedEdges = []Edge{}
+	}
+	if delta.RemovedEdges == nil {
+		delta.RemovedEdges = []Edge{}
+	}
+	return delta, nil
+}
+
+// mergeCallSites folds repeated calls to the same target into the first
+// one, summing weights, as the type-aware path does.
+func mergeCallSites(edges []Edge) []Edge {
+	var merged []Edge
+	seen := make(map[string]int)
+	for _, e := range edges {
+		if idx, ok := seen[e.Target]; ok {
+			merged[idx].Weight += e.Weight
+			if contextRank(e.CallContext) > contextRank(merged[idx].CallContext) {
+				merged[idx].CallContext = e.CallContext
+			}
+			continue
+		}
+		seen[e.Target] = len(merged)
+		merged = append(merged, e)
+	}
+	return merged
+}
+
+// diffOutgoing compares the function's recomputed outgoing edges with those
+// of the previous graph and records the difference.
+func (d *Delta) diffOutgoing(prevEdges, outgoing []Edge) {
+	var previous []Edge
+	kinds := make(map[string]Edge)
+	for _, e := range prevEdges {
+		if e.Source != d.NodeID {
+			continue
+		}
+		if _, ok := kinds[e.Target]; !ok && isSyntacticEdge(e.Kind) {
+			kinds[e.Target] = e
+		}
+		if d.Node == nil || isSyntacticEdge(e.Kind) {
+			previous = append(previous, e)
+		}
+	}
+
+	matched := make([]bool, len(previous))
+outer:
+	for _, e := range outgoing {
+		if old, ok := kinds[e.Target]; ok {
+			e.Kind = old.Kind
+			e.Profile, e.ObservedAtRuntime = old.Profile, old.ObservedAtRuntime
+		}
+		for i, old := range previous {
+			if !matched[i] && sameEdge(old, e) {
+				matched[i] = true
+				continue outer
+			}
+		}
+		d.AddedEdges = append(d.AddedEdges, e)
+	}
+	for i, old := range previous {
+		if !matched[i] {
+			d.RemovedEdges = append(d.RemovedEdges, old)
+		}
+	}
+}
+
+// isSyntacticEdge reports whether edges of kind are found by name resolution
+// of call expressions, and so can be recomputed without type information.
+func isSyntacticEdge(kind string) bool {
+	return kind == "direct" || kind == "method"
+}
+
+func sameEdge(a, b Edge) bool {
+	return a.Source == b.Source && a.Target == b.Target && a.Kind == b.Kind &&
+		a.CallSite == b.CallSite && a.IsResolved == b.IsResolved &&
+		a.Weight == b.Weight && a.CallContext == b.CallContext
+}
+
+// sameSignature compares parameter lists. Parameter types are only compared
+// when the previous graph was built without type information, since the
+// type-aware path spells them differently.
+func sameSignature(a, b Node, stats *Stats) bool {
+	if a.Kind != b.Kind || len(a.Parameters) != len(b.Parameters) {
+		return false
+	}
+	compareTypes := stats != nil && stats.Algorithm == AlgorithmAST
+	for i, p := range a.Parameters {
+		q := b.Parameters[i]
+		if p.Name != q.Name {
+			return false
+		}
+		if compareTypes && p.Type != nil && q.Type != nil && *p.Type != *q.Type {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyDelta updates the graph with the result of Reanalyze.
+func (g *Graph) ApplyDelta(d Delta) {
+	removed := make(map[int]bool)
+	for _, r := range d.RemovedEdges {
+		for i, e := range g.Edges {
+			if !removed[i] && sameEdge(e, r) {
+				removed[i] = true
+				break
+			}
+		}
+	}
+	edges := make([]Edge, 0, len(g.Edges)-len(removed)+len(d.AddedEdges))
+	for i, e := range g.Edges {
+		if !removed[i] {
+			edges = append(edges, e)
+		}
+	}
+	g.Edges = append(edges, d.AddedEdges...)
+
+	nodes := g.Nodes[:0]
+	for _, n := range g.Nodes {
+		if n.ID != d.NodeID {
+			nodes = append(nodes, n)
+		} else if d.Node != nil {
+			nodes = append(nodes, *d.Node)
+		}
+	}
+	g.Nodes = nodes
+	g.countCalls()
+	markLiveness(g)
+	if g.Summary != nil {
+		g.Summary = buildSummary(g)
+	}
+	if g.Stats != nil {
+		g.Stats.finish(g)
+	}
+	if g.GraphHash != "" {
+		g.computeHashes()
+	}
+	g.Policy.evaluate(g)
+	g.refreshSearchIndex()
+	g.refreshHierarchy()
+	g.refreshBundles()
+	g.refreshAISummary()
+	g.refreshEndpointDependencies()
+}
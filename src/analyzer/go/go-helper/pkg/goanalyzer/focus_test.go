@@ -0,0 +1,36 @@
+package goanalyzer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestReanalyzeRejectsSyntheticNodes(t *testing.T) {
+	const src = `package main
+
+var handler = build()
+
+func build() func() { return helper }
+
+func helper() {}
+
+func main() { handler() }
+`
+	graph := analyzeTyped(t, map[string]string{"main.go": src}, goanalyzer.Options{})
+
+	// The overlay stands in for the file on disk.
+	opts := goanalyzer.Options{ProjectRoot: t.TempDir(), Module: "example.com/app", Overlays: map[string]string{"main.go": src}}
+	_, err := goanalyzer.Reanalyze(opts, graph, "main.go:__var_init__")
+	if err == nil || !strings.Contains(err.Error(), "not a function or method") {
+		t.Errorf("Reanalyze(__var_init__) = %v, want an error", err)
+	}
+	delta, err := goanalyzer.Reanalyze(opts, graph, "main.go:build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.Node == nil {
+		t.Error("main.go:build reported deleted")
+	}
+}
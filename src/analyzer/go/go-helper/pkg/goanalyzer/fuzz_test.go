@@ -0,0 +1,93 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// allPasses enables every optional metadata pass.
+var allPasses = []string{"spans", "logs", "metrics", "http", "config", "annotations", "layers", "cli", "sql", "queues", "allocs", "examples"}
+
+// FuzzAnalyzeSources feeds arbitrary, usually unparsable, source to the
+// AST-only path, as happens when the user's code is mid-edit.
+//
+//	go test ./pkg/goanalyzer -run '^$' -fuzz FuzzAnalyzeSources
+func FuzzAnalyzeSources(f *testing.F) {
+	for _, src := range []string{
+		"",
+		"package",
+		"package main\nfunc main() {",
+		"package main\nfunc main() { helper( }\nfunc helper() {}",
+		"package main\nfunc (s *Server) Handle() { s.next.Handle() }",
+		"package main\nfunc (*) M() {}\nfunc () N() {}",
+		"package main\nfunc (a, b T) M() {}",
+		"package main\nfunc (r *R[T, U]) M() { r.M() }",
+		"package main\nfunc F[T any](x T) T { return F[T](x) }",
+		"package main\nfunc f() { for { if x { go f(); defer f() } } }",
+		"package main\nimport \"os\"\nfunc f() { os.Getenv(\"A\" + \"B\"); panic(err) }",
+		"package main\nfunc f() {\n\t// TODO(: x\n\t/* FIXME */\n}",
+		"package main\nvar c = prometheus.NewCounter(prometheus.CounterOpts{Name: })",
+	} {
+		f.Add(src)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		goanalyzer.AnalyzeSources(map[string]string{"main.go": src, "other.go": "package main\nfunc helper() {}"}, goanalyzer.Options{
+			Module:        "example.com/fuzz",
+			Passes:        allPasses,
+			ExternalEdges: true,
+		})
+	})
+}
+
+// FuzzOptions decodes arbitrary configuration JSON as the helper does, as
+// sent by a buggy orchestrator (bad file lists, wrong types), and runs the
+// AST-only path on a small project with it.
+//
+//	go test ./pkg/goanalyzer -run '^$' -fuzz FuzzOptions
+func FuzzOptions(f *testing.F) {
+	for _, in := range []string{
+		`{}`,
+		`{"files":["main.go"],"module":"example.com/fuzz"}`,
+		`{"files":["missing.go","","/","..","main.go","main.go"]}`,
+		`{"files":"main.go"}`,
+		`{"files":[1,2]}`,
+		`{"passes":["spans","nope",""],"externalEdges":true}`,
+		`{"executed":["main.go:main","main.go:main -> main.go:helper"," -> "]}`,
+		`{"module":"\u0000","files":["main.go"]}`,
+		`{"algorithm":"bogus"}`,
+		`[`,
+	} {
+		f.Add(in)
+	}
+	root := f.TempDir()
+	writeFile(f, root, "main.go", "package main\n\nimport \"net/http\"\n\nfunc main() { helper(); http.Get(\"https://example.com/x\") }\n\nfunc helper() {}\n")
+
+	f.Fuzz(func(t *testing.T, in string) {
+		opts, err := goanalyzer.DecodeOptions(strings.NewReader(in))
+		if err != nil {
+			return
+		}
+		// Stay inside the temporary project: don't read or write files
+		// elsewhere, or remap the root, and don't run commands.
+		opts.ProjectRoot, opts.PathMappings = root, nil
+		opts.Algorithm = goanalyzer.AlgorithmAST
+		opts.Pprof, opts.ExecutionLog, opts.Extensions = "", "", nil
+		opts.Baseline, opts.UpdateBaseline = "", false
+		opts.FilesFrom, opts.CodeOwners, opts.LintReports, opts.ExternalGraphs = "", "", nil, nil
+		opts.Inlining, opts.Escapes = false, false
+		opts.SinceRef, opts.PackagesDriver, opts.GoVersion, opts.BuildInfo = "", "", "", ""
+		goanalyzer.Analyze(context.Background(), opts)
+	})
+}
+
+func writeFile(tb testing.TB, dir, name, content string) {
+	tb.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// largeTypes are the types declared in generated files ("Code generated
+// ... DO NOT EDIT.") with more project methods than
+// Options.MaxGeneratedMethods, such as protobuf messages. The constructors
+// phase links them to a single synthetic "generated:<pkg>.<Type>" node
+// instead of to each method, and only that node links to the methods, so
+// that their method sets are computed once rather than per constructor and
+// interface they satisfy.
+type largeTypes struct {
+	byType map[*types.Named]*largeType
+	order  []*types.Named
+	stats  *Stats
+}
+
+type largeType struct {
+	id      string
+	methods []string
+	used    bool
+}
+
+// findLargeGeneratedTypes returns the large generated types among
+// concreteTypes, or nil when limit is not positive.
+func findLargeGeneratedTypes(pkgs []*packages.Package, concreteTypes []*types.Named, objToNodeID map[types.Object]string, limit int, stats *Stats) *largeTypes {
+	if limit <= 0 {
+		return nil
+	}
+	generated := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if ast.IsGenerated(file) {
+				generated[pkg.Fset.File(file.Pos()).Name()] = true
+			}
+		}
+	}
+	if len(generated) == 0 {
+		return nil
+	}
+	l := &largeTypes{byType: make(map[*types.Named]*largeType), stats: stats}
+	for _, named := range concreteTypes {
+		obj := named.Obj()
+		// go/packages loads every package into the same FileSet.
+		if !generated[pkgs[0].Fset.File(obj.Pos()).Name()] {
+			continue
+		}
+		var methods []string
+		mset := types.NewMethodSet(types.NewPointer(named))
+		for i := 0; i < mset.Len(); i++ {
+			if id, ok := objToNodeID[mset.At(i).Obj()]; ok {
+				methods = append(methods, id)
+			}
+		}
+		if len(methods) <= limit {
+			continue
+		}
+		l.byType[named] = &largeType{id: "generated:" + obj.Pkg().Path() + "." + obj.Name(), methods: methods}
+		l.order = append(l.order, named)
+	}
+	return l
+}
+
+// summarize adds the single edge from sourceID to the node of named, if
+// named is large, and reports whether it did.
+func (l *largeTypes) summarize(sourceID string, named *types.Named, prov Provenance, edges *[]Edge) bool {
+	if l == nil {
+		return false
+	}
+	lt, ok := l.byType[named]
+	if !ok {
+		return false
+	}
+	lt.used = true
+	l.stats.GeneratedMethodEdgesSkipped += len(lt.methods) - 1
+	*edges = append(*edges, Edge{
+		Source:     sourceID,
+		Target:     lt.id,
+		Kind:       "provided",
+		Provenance: prov,
+	})
+	return true
+}
+
+// emit adds the nodes of the large types that were linked to, with their
+// edges to the methods.
+func (l *largeTypes) emit(nodes *[]Node, edges *[]Edge) {
+	if l == nil {
+		return
+	}
+	for _, named := range l.order {
+		lt := l.byType[named]
+		if !lt.used {
+			continue
+		}
+		l.stats.GeneratedTypesSummarized++
+		node := externalNode(lt.id, fmt.Sprintf("%s (%d methods)", named.Obj().Name(), len(lt.methods)), named.Obj().Pkg().Path())
+		node.Kind = "generated"
+		*nodes = append(*nodes, node)
+		for _, method := range lt.methods {
+			*edges = append(*edges, Edge{
+				Source:     lt.id,
+				Target:     method,
+				Kind:       "provided",
+				Provenance: Provenance{Phase: "constructors", Rule: ruleGeneratedMethod},
+			})
+		}
+	}
+}
@@ -0,0 +1,2457 @@
+// Package goanalyzer builds CodeGraph's function-level call graph for Go
+// projects: one node per function or method, one edge per resolved call.
+//
+// Primary mode: type-aware analysis using golang.org/x/tools/go/packages
+// with interface dispatch resolution.
+// Fallback mode: AST-only analysis (no type info, no interface dispatch).
+//
+// Options and Graph are also the JSON wire format of the go-helper binary,
+// which reads Options from stdin and writes the Graph to stdout.
+package goanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ---------- JSON types ----------
+
+// Options configures an analysis run.
+type Options struct {
+	// Files are the project-relative files to analyze; entries may be globs
+	// ("internal/**/*.go"). FilesFrom is a manifest file (relative to
+	// ProjectRoot) with more entries, one per line. See ExpandFiles.
+	Files       []string `json:"files"`
+	FilesFrom   string   `json:"filesFrom,omitempty"`
+	ProjectRoot string   `json:"projectRoot"`
+	Module      string   `json:"module"`
+	// PathMode is how the output spells file paths: "relative" to
+	// ProjectRoot (the default), "modulePath" (under Module) or "absolute".
+	// PathPrefixes then replaces the longest matching prefix of each path,
+	// e.g. {"/workspace/": "/home/me/src/"} when the consumer of the graph
+	// sees the project at another mount point.
+	PathMode     string            `json:"pathMode,omitempty"`
+	PathPrefixes map[string]string `json:"pathPrefixes,omitempty"`
+	// PathMappings relate directories as the orchestrator sees them to
+	// where the helper sees them, when it runs in a container or over SSH:
+	// absolute paths of the options (ProjectRoot, Files, Overlays, ...) are
+	// translated into the helper's view, and absolute output paths back.
+	PathMappings []PathMapping `json:"pathMappings,omitempty"`
+	// Pprof is an optional CPU or heap profile path (relative to ProjectRoot)
+	// whose samples are joined onto nodes and edges.
+	Pprof string `json:"pprof,omitempty"`
+	// LintReports are files holding the JSON output of go vet -json or
+	// staticcheck -f json (relative to ProjectRoot); their issues are
+	// attached to the functions they are in (see applyLintReports).
+	LintReports []string `json:"lintReports,omitempty"`
+	// Executed lists functions observed at runtime (node IDs or linker symbols,
+	// or "caller -> callee" pairs); ExecutionLog is a file of the same entries,
+	// one per line, or a Go coverage profile.
+	Executed     []string `json:"executed,omitempty"`
+	ExecutionLog string   `json:"executionLog,omitempty"`
+	// Passes enables optional metadata passes by name (see passes.go).
+	Passes []string `json:"passes,omitempty"`
+	// MaxUsageExamples bounds the call sites the "examples" pass keeps per
+	// function (default 3).
+	MaxUsageExamples int `json:"maxUsageExamples,omitempty"`
+	// ExternalEdges adds synthetic nodes for external dependencies found by
+	// the passes (outbound HTTP endpoints) and edges to them.
+	ExternalEdges bool `json:"externalEdges,omitempty"`
+	// Extensions are enrichment programs run on the finished graph (see extensions.go).
+	Extensions []Extension `json:"extensions,omitempty"`
+	// Overlays maps files (relative to ProjectRoot, or absolute) to unsaved
+	// editor contents that replace what is on disk. Overlaid .go files that
+	// are not on disk yet are analyzed too.
+	Overlays map[string]string `json:"overlays,omitempty"`
+	// Algorithm selects how calls are resolved: AlgorithmTypes (default) or
+	// AlgorithmAST.
+	Algorithm string `json:"algorithm,omitempty"`
+	// DependencyRules declares allowed call directions between packages;
+	// calls breaking them are reported in Graph.Findings (see rules.go).
+	DependencyRules []DependencyRule `json:"dependencyRules,omitempty"`
+	// Duplicates reports exported functions declared in several packages
+	// with the same name and nearly the same parameters in Graph.Findings
+	// (see findDuplicates).
+	Duplicates bool `json:"duplicates,omitempty"`
+	// ParameterLists reports functions with long parameter lists, and
+	// parameter groups repeated across the functions of a package, in
+	// Graph.Findings (see findParameterLists).
+	ParameterLists *ParameterListOptions `json:"parameterLists,omitempty"`
+	// CodeOwners is a CODEOWNERS file (relative to ProjectRoot) whose owners
+	// are recorded on nodes and aggregated into Summary.TeamDependencies.
+	CodeOwners string `json:"codeOwners,omitempty"`
+	// KeepUnresolved records calls from the analyzed packages to functions of
+	// Module outside them (another shard's subtree) as unresolved edges whose
+	// Target is the callee's SymbolID, so that Merge can link shards. Type-aware
+	// analysis only.
+	KeepUnresolved bool `json:"keepUnresolved,omitempty"`
+	// Debug records the source expression behind each edge in its
+	// Provenance.
+	Debug bool `json:"debug,omitempty"`
+	// Explain requests an account of one edge or node in Graph.Explanation.
+	Explain *ExplainRequest `json:"explain,omitempty"`
+	// RegistryRules make entry points of functions registered with
+	// frameworks by call or struct tag (see registry.go).
+	RegistryRules []RegistryRule `json:"registryRules,omitempty"`
+	// Keep lists globs of node IDs or SymbolIDs of functions to treat as live
+	// even if no entry point reaches them, like the //codegraph:keep
+	// directive (see liveness.go).
+	Keep []string `json:"keep,omitempty"`
+	// Baseline is a JSON file (relative to ProjectRoot) of acknowledged dead
+	// functions; Graph.DeadCode then reports the others as new (see
+	// baseline.go). A missing file acknowledges nothing.
+	Baseline string `json:"baseline,omitempty"`
+	// UpdateBaseline rewrites Baseline with the current dead functions.
+	UpdateBaseline bool `json:"updateBaseline,omitempty"`
+	// ColorRules map computed attributes of nodes to their Status and
+	// Color, replacing the defaults of markLiveness (see ColorRule).
+	ColorRules []ColorRule `json:"colorRules,omitempty"`
+	// FailOn sets CI thresholds, evaluated in Graph.Policy.
+	FailOn *FailOn `json:"failOn,omitempty"`
+	// Reports names report files (ReportHTML, ...) for the go-helper command
+	// to write into ReportDir (relative to ProjectRoot, default ProjectRoot)
+	// once the graph is final; see WriteReports.
+	Reports   []string `json:"reports,omitempty"`
+	ReportDir string   `json:"reportDir,omitempty"`
+	// MaxNodesPerChunk has the go-helper command split the graph into
+	// numbered files of at most this many nodes, next to its output file or
+	// else in ReportDir, and print a ChunkManifest in its place; see
+	// WriteChunks.
+	MaxNodesPerChunk int `json:"maxNodesPerChunk,omitempty"`
+	// SearchIndex adds a trigram index over node names to the output.
+	SearchIndex bool `json:"searchIndex,omitempty"`
+	// Hierarchy adds the module → package → file tree to the output.
+	Hierarchy bool `json:"hierarchy,omitempty"`
+	// Bundles adds package-to-package aggregates of the call edges to the
+	// output, next to the raw edges.
+	Bundles bool `json:"bundles,omitempty"`
+	// AISummary adds a compact digest of the graph to the output, to paste
+	// into language model prompts.
+	AISummary bool `json:"aiSummary,omitempty"`
+	// APISurface lists only the exported functions, methods and types of
+	// the packages, with signatures and doc summaries and no edges (see
+	// analyzeAPISurface). The other output options do not apply.
+	APISurface bool `json:"apiSurface,omitempty"`
+	// Roots and MaxDepth trim the output to the nodes within MaxDepth calls
+	// of Roots (node IDs); see Graph.Prune. Pruning applies when either is
+	// set.
+	Roots    []string `json:"roots,omitempty"`
+	MaxDepth int      `json:"maxDepth,omitempty"`
+	// SinceRef restricts the output of a type-aware analysis to the
+	// packages with .go files changed since this git revision (committed,
+	// in the working tree or untracked) and the packages importing them
+	// directly. The whole project is still analyzed, so calls and statuses
+	// are as in a full run, but only the affected packages get nodes and
+	// edges.
+	SinceRef string `json:"sinceRef,omitempty"`
+	// Slice trims the output, after Roots and MaxDepth, to the functions
+	// matching a pattern and their neighbors; see Graph.Slice.
+	Slice *SliceOptions `json:"slice,omitempty"`
+	// MinLoc, Visibility and Kinds drop from the output the nodes with
+	// fewer LinesOfCode, another Visibility or another Kind, and their
+	// edges, after pruning; statuses are those computed over the whole
+	// graph. Synthetic nodes ("external", "interface", ...) are filtered
+	// like the others.
+	MinLoc     int      `json:"minLoc,omitempty"`
+	Visibility []string `json:"visibility,omitempty"`
+	Kinds      []string `json:"kinds,omitempty"`
+	// BuildMatrix lists GOOS/GOARCH/build-tag combinations to load the
+	// packages under, uniting the results, so that code built only for
+	// other platforms or tags is not reported dead (see buildmatrix.go).
+	// Type-aware analysis only.
+	BuildMatrix []BuildContext `json:"buildMatrix,omitempty"`
+	// BuildInfo is a file describing how the shipped binary is built (see
+	// readBuildInfo): a JSON BuildInfo, or a Dockerfile, Makefile or build
+	// script running go build. Its GOOS, GOARCH and tags replace those of
+	// the development environment, so that the analysis matches the
+	// binary, and the variables its -ldflags set with -X are listed in
+	// Graph.LinkerVars. It cannot be combined with BuildMatrix. Type-aware
+	// analysis only.
+	BuildInfo string `json:"buildInfo,omitempty"`
+	// GoVersion selects the Go toolchain packages are loaded and built with
+	// ("1.22" or "1.22.3"), through GOTOOLCHAIN; a toolchain or go
+	// directive in go.mod requiring a newer one still wins.
+	GoVersion string `json:"goVersion,omitempty"`
+	// DocURLs sets Node.DocURL on exported symbols, linking to DocBaseURL
+	// (default https://pkg.go.dev, or a self-hosted pkgsite); see
+	// addDocURLs.
+	DocURLs    bool   `json:"docUrls,omitempty"`
+	DocBaseURL string `json:"docBaseUrl,omitempty"`
+	// PackagesDriver makes type-aware analysis load packages through a
+	// GOPACKAGESDRIVER program (relative to ProjectRoot), for build systems
+	// such as Bazel or Please whose repositories the go command cannot
+	// load. "bazel" runs the rules_go driver. DriverPatterns replace the
+	// "./..." pattern the driver is asked for, e.g. ["//..."].
+	PackagesDriver string   `json:"packagesDriver,omitempty"`
+	DriverPatterns []string `json:"driverPatterns,omitempty"`
+	// ResolveIntoDeps lists import path patterns ("github.com/org/lib/...")
+	// of dependencies to analyze like the project's own packages, from their
+	// source in the module cache, instead of only as call targets outside
+	// it. Their nodes are marked Dependency. Type-aware analysis only.
+	ResolveIntoDeps []string `json:"resolveIntoDeps,omitempty"`
+	// ExternalGraphs are output files (relative to ProjectRoot) of earlier
+	// runs on library repositories. Their functions join the graph under
+	// their import path, marked Dependency, and the project's calls into
+	// them resolve to their nodes (see stitch.go). Type-aware analysis only.
+	ExternalGraphs []string `json:"externalGraphs,omitempty"`
+	// References lists every use of the graph's functions, calls or not and
+	// including those in tests, in Graph.References. Type-aware analysis
+	// only.
+	References bool `json:"references,omitempty"`
+	// MaxFanout, if positive, caps the edges of an interface call: calls to
+	// a method with more implementations get one edge to a synthetic
+	// "interface:<symbol>" node linked to the implementations, with a
+	// diagnostic (see fanout.go). Type-aware analysis only.
+	MaxFanout int `json:"maxFanout,omitempty"`
+	// MaxGeneratedMethods, if positive, links constructors to the types of
+	// generated files with more methods than that (protobuf messages)
+	// through one synthetic "generated:<pkg>.<Type>" node, instead of to
+	// each method (see generated.go). Type-aware analysis only.
+	MaxGeneratedMethods int `json:"maxGeneratedMethods,omitempty"`
+	// Budgets bounds the time of package loading and call resolution, for
+	// a partial graph rather than none on pathological repositories (see
+	// budget.go).
+	Budgets *Budgets `json:"budgets,omitempty"`
+	// AnyDispatch decides what a function returning an empty interface
+	// (any, interface{} or a named one), which every project type
+	// implements, makes callable: nothing (AnyDispatchIgnore, the default),
+	// the methods of every project type when there are at most
+	// AnyDispatchLimit (default 20) of them (AnyDispatchFanOut, reported in
+	// Graph.Diagnostics otherwise), or a synthetic "external:any" node
+	// (AnyDispatchUnresolved). Type-aware analysis only.
+	AnyDispatch      string `json:"anyDispatch,omitempty"`
+	AnyDispatchLimit int    `json:"anyDispatchLimit,omitempty"`
+	// TrivialWrappers handles getters, setters and functions that only
+	// delegate to another call (see isTrivial): TrivialTag marks them
+	// IsTrivial, TrivialCollapse removes them and links their callers to
+	// their callees. Empty leaves them alone.
+	TrivialWrappers string `json:"trivialWrappers,omitempty"`
+	// Tests adds the functions of the project's _test.go files, and their
+	// calls and references, to the graph (see addTests). Functions reached
+	// only from test entry points then get the status "test-only" rather
+	// than look alive. With Files, as the go-helper command sets by
+	// default, test functions are left out of the output unless their
+	// files are listed, but the statuses stand. Type-aware analysis only.
+	Tests bool `json:"tests,omitempty"`
+	// MockImplementations handles the mocks generated by mockgen or
+	// mockery, and other types that look like mocks (see findMockTypes),
+	// which implement project interfaces: MockExclude leaves them out of
+	// interface dispatch, so they do not keep production code reachable,
+	// and MockTag marks the dispatch edges into them IsMock. Empty treats
+	// them like any type. Type-aware analysis only.
+	MockImplementations string `json:"mockImplementations,omitempty"`
+	// Routes lists the HTTP routes and gRPC methods the project registers,
+	// with their handlers and middleware, in Graph.Routes (see
+	// extractRoutes). Type-aware analysis only.
+	Routes bool `json:"routes,omitempty"`
+	// Embeds adds a node of Kind "embed" for each //go:embed variable, with
+	// the patterns and files it embeds, and "embed" edges from the
+	// functions reading it (see addEmbedNodes). Type-aware analysis only.
+	Embeds bool `json:"embeds,omitempty"`
+	// Migrations links the migrations registered with goose, golang-migrate
+	// or atlas, Go functions and migration files alike, to a synthetic
+	// "migrations" entry node (see linkMigrations), so that they are not
+	// reported dead. With Embeds, embedded migration directories are
+	// linked too. Type-aware analysis only.
+	Migrations bool `json:"migrations,omitempty"`
+	// Inlining records on each function whether the compiler inlines it
+	// (see annotateInlining), to read profiles against the graph: an
+	// inlined function has no frames of its own. It builds the project
+	// with go build -gcflags=-m=2, or guesses from function sizes if that
+	// fails.
+	Inlining bool `json:"inlining,omitempty"`
+	// Escapes records on each function the values the compiler's escape
+	// analysis moves to the heap (see annotateEscapes), from the same
+	// build as Inlining.
+	Escapes bool `json:"escapes,omitempty"`
+	// InitGraph adds the initialization order of the project's packages,
+	// their package-level variables and init functions, with the variables
+	// each step depends on, as Graph.InitGraph (see buildInitGraph).
+	// Type-aware analysis only.
+	InitGraph bool `json:"initGraph,omitempty"`
+	// InterfaceGaps reports, as Graph.InterfaceGaps, the project types that
+	// implement a project interface but for one or two methods, and the
+	// project interfaces no project type implements (see
+	// findInterfaceGaps). Type-aware analysis only.
+	InterfaceGaps bool `json:"interfaceGaps,omitempty"`
+	// EndpointDependencies lists, per route, the SQL tables, outbound HTTP
+	// services and queue topics it touches, in Graph.EndpointDependencies
+	// (see buildEndpointDependencies). It implies Routes and the "http",
+	// "sql" and "queues" passes. Type-aware analysis only.
+	EndpointDependencies bool `json:"endpointDependencies,omitempty"`
+	// Cache, if set, reuses the packages loaded by earlier runs on an
+	// unchanged project (see LoadCache).
+	Cache *LoadCache `json:"-"`
+	// Log receives warnings (package load errors, unreadable profiles or
+	// traces, failed extensions). Nil discards them.
+	Log io.Writer `json:"-"`
+
+	// build is the BuildMatrix or BuildInfo context being analyzed.
+	build *BuildContext
+	// buildInfo is read from BuildInfo by withBuildInfo.
+	buildInfo *BuildInfo
+	// driver is the GOPACKAGESDRIVER program set by withPackagesDriver.
+	driver string
+	// gopath is the GOPATH of a project in GOPATH layout (see withGOPATH).
+	gopath string
+	// pathsMapped is set by MapPaths.
+	pathsMapped bool
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	Type     *string `json:"type"`
+	IsUsed   bool    `json:"isUsed"`
+	Position int     `json:"position"`
+}
+
+type Node struct {
+	ID               string      `json:"id"`
+	Name             string      `json:"name"`
+	QualifiedName    string      `json:"qualifiedName"`
+	FilePath         string      `json:"filePath"`
+	StartLine        int         `json:"startLine"`
+	EndLine          int         `json:"endLine"`
+	Language         string      `json:"language"`
+	Kind             string      `json:"kind"`
+	Visibility       string      `json:"visibility"`
+	IsEntryPoint     bool        `json:"isEntryPoint"`
+	Parameters       []Parameter `json:"parameters"`
+	UnusedParameters []string    `json:"unusedParameters"`
+	PackageOrModule  string      `json:"packageOrModule"`
+	LinesOfCode      int         `json:"linesOfCode"`
+	Status           string      `json:"status"`
+	Color            string      `json:"color"`
+
+	// Sloc, CommentLines and BlankLines split LinesOfCode into lines with
+	// code, lines with only comments and empty lines.
+	Sloc         int `json:"sloc"`
+	CommentLines int `json:"commentLines"`
+	BlankLines   int `json:"blankLines"`
+
+	// Profile holds samples joined from Options.Pprof, if any matched this node.
+	Profile *ProfileStats `json:"profile,omitempty"`
+	// ObservedAtRuntime is set only when an execution trace was supplied.
+	ObservedAtRuntime *bool `json:"observedAtRuntime,omitempty"`
+	// Metadata holds findings of the optional passes enabled in Options.Passes.
+	Metadata *NodeMetadata `json:"metadata,omitempty"`
+	// Extensions holds annotations returned by Options.Extensions, keyed by extension name.
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+
+	// Owners are the CODEOWNERS owners of the node's file.
+	Owners []string `json:"owners,omitempty"`
+
+	// RegisteredBy names the Options.RegistryRules rule that made the
+	// function an entry point, or "plugin" / "go-plugin" for functions
+	// exported by a plugin (see plugin.go).
+	RegisteredBy string `json:"registeredBy,omitempty"`
+
+	// Dependency marks functions from outside the project, analyzed for
+	// Options.ResolveIntoDeps or read from Options.ExternalGraphs; their
+	// FilePath starts with the import path.
+	Dependency bool `json:"dependency,omitempty"`
+
+	// ReachableFrom lists the binaries, by the PackageOrModule of their main
+	// package, that reach the function.
+	ReachableFrom []string `json:"reachableFrom,omitempty"`
+
+	// BuildContexts names the Options.BuildMatrix contexts the function
+	// exists in, when it does not exist in all of them.
+	BuildContexts []string `json:"buildContexts,omitempty"`
+
+	// KeptBy is set on functions kept live regardless of their callers:
+	// "directive" for a //codegraph:keep comment, otherwise the Options.Keep
+	// pattern that matched.
+	KeptBy string `json:"keptBy,omitempty"`
+
+	// Approximate marks nodes from files with syntax errors, whose extent and
+	// edges come from a best-effort parse (see Graph.Diagnostics).
+	Approximate bool `json:"approximate,omitempty"`
+
+	// DocURL links exported symbols to their documentation, with
+	// Options.DocURLs.
+	DocURL string `json:"docUrl,omitempty"`
+
+	// SymbolID is the linker symbol name ("pkg/path.(*T).Method", "main.main"),
+	// which identifies the function independently of the project root. It
+	// joins runtime data such as profiles onto nodes and links graphs from
+	// separate runs in Merge.
+	SymbolID string `json:"symbolId,omitempty"`
+	// BodyHash fingerprints the function body, ignoring comments and
+	// formatting, so that DiffGraphs can follow a function that was moved
+	// or renamed.
+	BodyHash string `json:"bodyHash,omitempty"`
+	// IsTrivial marks getters, setters and pure delegations, with
+	// Options.TrivialWrappers "tag".
+	IsTrivial bool `json:"isTrivial,omitempty"`
+	// CallerCount and CalleeCount are the numbers of distinct functions
+	// with an edge into and out of the node, in the output's edges.
+	CallerCount int `json:"callerCount"`
+	CalleeCount int `json:"calleeCount"`
+	// StatusOverride and Keep are never set by analysis: consumers of the
+	// graph set them to record a user's curation, such as a function marked
+	// as intentionally unused, and Graph.CarryCuration and Reanalyze keep
+	// them across re-analysis.
+	StatusOverride string `json:"statusOverride,omitempty"`
+	Keep           bool   `json:"keep,omitempty"`
+	// trivial is IsTrivial as detected, whatever the option.
+	trivial bool
+
+	// Inlining is set with Options.Inlining.
+	Inlining *Inlining `json:"inlining,omitempty"`
+	// Escapes is set with Options.Escapes.
+	Escapes []Escape `json:"escapes,omitempty"`
+	// LintIssues are the issues of Options.LintReports in the function.
+	LintIssues []LintIssue `json:"lintIssues,omitempty"`
+
+	// EmbedPatterns and EmbeddedFiles are the //go:embed patterns of a node
+	// of Kind "embed" and the project files they match.
+	EmbedPatterns []string `json:"embedPatterns,omitempty"`
+	EmbeddedFiles []string `json:"embeddedFiles,omitempty"`
+
+	// Deprecated marks functions whose doc comment has a "Deprecated: "
+	// paragraph.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Signature and Doc (the first sentence of the doc comment) are set in
+	// Options.APISurface mode, which also adds nodes of Kind "type".
+	Signature string `json:"signature,omitempty"`
+	Doc       string `json:"doc,omitempty"`
+}
+
+type CallSite struct {
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+type Edge struct {
+	Source     string   `json:"source"`
+	Target     string   `json:"target"`
+	CallSite   CallSite `json:"callSite"`
+	Kind       string   `json:"kind"`
+	IsResolved bool     `json:"isResolved"`
+	// Weight is a static call-frequency estimate summed over all call sites
+	// of this source→target pair (see estimateCallWeights).
+	Weight      float64 `json:"weight,omitempty"`
+	CallContext string  `json:"callContext,omitempty"`
+	// ConstantArgs lists the arguments passed as constants, per call site.
+	ConstantArgs []ConstantArg `json:"constantArgs,omitempty"`
+	// ArgCounts is the number of arguments written at each call site, in
+	// order; Variadic is set when the callee is variadic (type-aware
+	// analysis only). NilParameters names the callee's parameters given a
+	// literal nil at some call site (see nilParameters).
+	ArgCounts     []int    `json:"argCounts,omitempty"`
+	Variadic      bool     `json:"variadic,omitempty"`
+	NilParameters []string `json:"nilParameters,omitempty"`
+
+	// Profile holds samples where this caller/callee pair appears in Options.Pprof.
+	Profile           *ProfileStats `json:"profile,omitempty"`
+	ObservedAtRuntime *bool         `json:"observedAtRuntime,omitempty"`
+
+	// Provenance tells which phase and rule produced the edge.
+	Provenance Provenance `json:"provenance"`
+
+	// BuildContexts is Node.BuildContexts for the call.
+	BuildContexts []string `json:"buildContexts,omitempty"`
+
+	// Route and Middleware describe a "chain" edge, from a registration
+	// call to the handler at the end of a middleware chain (see
+	// middleware.go): the route string passed to the registration, if
+	// any, and the middleware wrapping the handler, outermost first, by
+	// node ID or, outside the project, by qualified name.
+	Route      string   `json:"route,omitempty"`
+	Middleware []string `json:"middleware,omitempty"`
+
+	// IsMock marks interface dispatch into a mock type, with
+	// Options.MockImplementations "tag".
+	IsMock bool `json:"isMock,omitempty"`
+}
+
+// Graph is the result of an analysis run.
+type Graph struct {
+	Nodes   []Node   `json:"nodes"`
+	Edges   []Edge   `json:"edges"`
+	Summary *Summary `json:"summary,omitempty"`
+	Stats   *Stats   `json:"stats,omitempty"`
+	// Diagnostics lists files whose analysis was degraded, such as files
+	// that do not parse.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	// Findings lists problems found in the graph itself, such as calls that
+	// break Options.DependencyRules.
+	Findings []Finding `json:"findings,omitempty"`
+	// GraphHash and PackageHashes (keyed by PackageOrModule) fingerprint
+	// the call graph, so a change to it can be detected without a full diff
+	// (see computeHashes).
+	GraphHash     string            `json:"graphHash,omitempty"`
+	PackageHashes map[string]string `json:"packageHashes,omitempty"`
+	// Explanation answers Options.Explain.
+	Explanation *Explanation `json:"explanation,omitempty"`
+	// DeadCode compares the dead functions with Options.Baseline.
+	DeadCode *DeadCodeReport `json:"deadCode,omitempty"`
+	// Policy evaluates Options.FailOn.
+	Policy *Policy `json:"policy,omitempty"`
+	// ColorRules are the Options.ColorRules the nodes are styled with,
+	// kept so that liveness recomputed on the graph applies them too.
+	ColorRules []ColorRule `json:"colorRules,omitempty"`
+	// SearchIndex is set with Options.SearchIndex.
+	SearchIndex *SearchIndex `json:"searchIndex,omitempty"`
+	// Hierarchy is set with Options.Hierarchy.
+	Hierarchy *Container `json:"hierarchy,omitempty"`
+	// Bundles is set with Options.Bundles.
+	Bundles []Bundle `json:"bundles,omitempty"`
+	// AISummary is set with Options.AISummary.
+	AISummary *AISummary `json:"aiSummary,omitempty"`
+	// References is set with Options.References.
+	References []Reference `json:"references,omitempty"`
+	// Routes is set with Options.Routes.
+	Routes []Route `json:"routes,omitempty"`
+	// InitGraph is set with Options.InitGraph.
+	InitGraph *InitGraph `json:"initGraph,omitempty"`
+	// InterfaceGaps is set with Options.InterfaceGaps.
+	InterfaceGaps *InterfaceGaps `json:"interfaceGaps,omitempty"`
+	// LinkerVars lists the variables set by the -X flags of
+	// Options.BuildInfo.
+	LinkerVars []LinkerVar `json:"linkerVars,omitempty"`
+	// EndpointDependencies is set with Options.EndpointDependencies.
+	EndpointDependencies []EndpointDependency `json:"endpointDependencies,omitempty"`
+}
+
+// builtins that should be skipped
+var goBuiltins = map[string]bool{
+	"make": true, "len": true, "cap": true, "append": true, "copy": true,
+	"delete": true, "close": true, "new": true, "panic": true, "recover": true,
+	"print": true, "println": true, "complex": true, "real": true, "imag": true,
+	"clear": true, "min": true, "max": true,
+}
+
+// SchemaVersion is the version of the Options/Graph JSON format. It changes
+// when a field is removed or its meaning changes, not when fields are added.
+const SchemaVersion = "1"
+
+// Call resolution algorithms for Options.Algorithm.
+const (
+	// AlgorithmTypes resolves calls with go/types, including interface
+	// dispatch, and falls back to AlgorithmAST when packages cannot be loaded.
+	AlgorithmTypes = "types"
+	// AlgorithmAST resolves calls by name from the syntax tree alone.
+	AlgorithmAST = "ast"
+)
+
+// Analyze runs the analysis selected by opts.Algorithm over the module at
+// opts.ProjectRoot (by default type-aware, falling back to AST-only analysis
+// of opts.Files when packages cannot be loaded), then applies the configured
+// overlays, passes and extensions. When opts.Files (or FilesFrom) lists
+// files, only their functions are emitted, though type-aware analysis
+// resolves calls across the whole module. Cancelling ctx stops package
+// loading and running extensions.
+func Analyze(ctx context.Context, opts Options) (Graph, error) {
+	opts, err := opts.MapPaths().ExpandFiles()
+	if err != nil {
+		return Graph{}, err
+	}
+	opts = opts.withOverlayFiles().withEndpointDependencies()
+	if opts, err = opts.withBuildInfo(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkPathMode(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkGoVersion(); err != nil {
+		return Graph{}, err
+	}
+	opts, cleanup, err := opts.withPackagesDriver()
+	if err != nil {
+		return Graph{}, err
+	}
+	defer cleanup()
+	opts = opts.withGOPATH()
+	stats := &Stats{explain: newExplainer(opts.Explain)}
+	if opts.APISurface {
+		graph := analyzeAPISurface(opts, opts.relOverlays(), stats)
+		if opts.DocURLs {
+			addDocURLs(&graph, opts)
+		}
+		stats.finish(&graph)
+		graph.Stats = stats
+		opts.mapFilePaths(&graph)
+		return graph, nil
+	}
+	if err := opts.checkAnyDispatch(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkTrivialWrappers(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkMockImplementations(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkSlice(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkParameterLists(); err != nil {
+		return Graph{}, err
+	}
+	if err := opts.checkSinceRef(ctx); err != nil {
+		return Graph{}, err
+	}
+	var graph Graph
+	var toolchain *Diagnostic
+	switch opts.Algorithm {
+	case "", AlgorithmTypes:
+		if err = opts.checkToolchain(ctx); err != nil {
+			d := opts.toolchainDiagnostic(err)
+			toolchain = &d
+		} else if len(opts.BuildMatrix) > 0 {
+			graph, err = analyzeBuildMatrix(ctx, opts, stats)
+		} else {
+			graph, err = analyzeWithTypes(ctx, opts, stats)
+		}
+	case AlgorithmAST:
+		graph = analyzeFilesASTOnly(opts, opts.relOverlays(), stats)
+	default:
+		return Graph{}, fmt.Errorf("unknown algorithm %q (want %q or %q)", opts.Algorithm, AlgorithmTypes, AlgorithmAST)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return Graph{}, ctx.Err()
+		}
+		opts.warnf("type-aware analysis unavailable, using AST fallback: %v", err)
+		graph = analyzeFilesASTOnly(opts, opts.relOverlays(), stats)
+	}
+	if toolchain != nil {
+		graph.Diagnostics = append(graph.Diagnostics, *toolchain)
+	}
+	graph.Diagnostics = append(graph.Diagnostics, stats.truncated...)
+
+	if opts.Tests && stats.Algorithm == AlgorithmTypes && len(stats.truncated) == 0 {
+		t := time.Now()
+		if err := addTests(ctx, opts, &graph); err != nil {
+			opts.warnf("tests skipped: %v", err)
+		}
+		stats.phase("tests", t)
+	}
+
+	t := time.Now()
+	postProcess(ctx, &graph, opts)
+	if err := ctx.Err(); err != nil {
+		return Graph{}, err
+	}
+	if stats.Algorithm == AlgorithmTypes && len(opts.Files) > 0 {
+		// Types were resolved project-wide; emit only the listed files,
+		// keeping the liveness computed over the whole project.
+		graph.RestrictToFiles(opts.Files)
+	}
+	if stats.sinceDirs != nil {
+		graph.restrictToDirs(stats.sinceDirs)
+	}
+	opts.prune(&graph)
+	opts.slice(&graph)
+	opts.applyTrivialWrappers(&graph)
+	opts.filterNodes(&graph)
+	stats.phase("postprocess", t)
+	if opts.References && stats.Algorithm == AlgorithmTypes && len(stats.truncated) == 0 {
+		t = time.Now()
+		if err := collectReferences(ctx, opts, &graph); err != nil {
+			opts.warnf("references skipped: %v", err)
+		}
+		stats.phase("references", t)
+	}
+	graph.countCalls()
+	stats.finish(&graph)
+	graph.Stats = stats
+	graph.Explanation = stats.explain.explain(&graph)
+	graph.Policy = newPolicy(opts.FailOn)
+	graph.Policy.evaluate(&graph)
+	if opts.SearchIndex {
+		graph.SearchIndex = buildSearchIndex(&graph)
+	}
+	if opts.Hierarchy {
+		graph.Hierarchy = buildHierarchy(&graph, opts.Module)
+	}
+	if opts.Bundles {
+		graph.Bundles = buildBundles(&graph)
+	}
+	if opts.AISummary {
+		graph.AISummary = buildAISummary(&graph)
+	}
+	if opts.EndpointDependencies {
+		graph.EndpointDependencies = buildEndpointDependencies(&graph)
+	}
+	opts.mapFilePaths(&graph)
+	return graph, nil
+}
+
+// prune applies Roots and MaxDepth to g.
+func (o Options) prune(g *Graph) {
+	if len(o.Roots) == 0 && o.MaxDepth == 0 {
+		return
+	}
+	if unknown := g.Prune(o.Roots, o.MaxDepth); len(unknown) > 0 {
+		o.warnf("unknown roots: %s", strings.Join(unknown, ", "))
+	}
+}
+
+// filterNodes applies MinLoc, Visibility and Kinds to g.
+func (o Options) filterNodes(g *Graph) {
+	if o.MinLoc <= 0 && len(o.Visibility) == 0 && len(o.Kinds) == 0 {
+		return
+	}
+	g.retain(func(n Node) bool {
+		return n.LinesOfCode >= o.MinLoc &&
+			(len(o.Visibility) == 0 || slices.Contains(o.Visibility, n.Visibility)) &&
+			(len(o.Kinds) == 0 || slices.Contains(o.Kinds, n.Kind))
+	})
+}
+
+// warnf writes a warning to Options.Log, as a line prefixed "Warning: ".
+func (o Options) warnf(format string, args ...any) {
+	if o.Log != nil {
+		fmt.Fprintf(o.Log, "Warning: "+format+"\n", args...)
+	}
+}
+
+// postProcess applies the steps that follow either analysis path: synthetic
+// external nodes, runtime overlays, ownership, extensions, rule checks, the
+// summary and the hashes.
+func postProcess(ctx context.Context, output *Graph, input Options) {
+	if input.ExternalEdges {
+		addExternalHTTPEdges(output)
+	}
+
+	if input.Pprof != "" {
+		if err := applyProfile(output, resolvePath(input.ProjectRoot, input.Pprof)); err != nil {
+			input.warnf("pprof overlay skipped: %v", err)
+		}
+	}
+
+	if len(input.LintReports) > 0 {
+		applyLintReports(output, input)
+	}
+
+	if input.Inlining || input.Escapes {
+		diags, err := input.compilerDiagnostics(ctx, "-m=2")
+		if input.Inlining {
+			annotateInlining(output, diags, err, input)
+		}
+		if input.Escapes {
+			if err != nil {
+				input.warnf("escape analysis skipped: %v", err)
+			} else {
+				annotateEscapes(output, diags)
+			}
+		}
+	}
+
+	if len(input.Executed) > 0 || input.ExecutionLog != "" {
+		trace, err := loadExecutionTrace(input)
+		if err != nil {
+			input.warnf("execution trace overlay skipped: %v", err)
+		} else {
+			applyExecutionTrace(output, trace)
+		}
+	}
+
+	if input.DocURLs {
+		addDocURLs(output, input)
+	}
+
+	if input.CodeOwners != "" {
+		rules, err := loadCodeOwners(resolvePath(input.ProjectRoot, input.CodeOwners))
+		if err != nil {
+			input.warnf("CODEOWNERS skipped: %v", err)
+		} else {
+			applyCodeOwners(output, rules)
+		}
+	}
+
+	if enabledPasses(input)[passLayers] {
+		inferLayers(output)
+	}
+	if enabledPasses(input)[passExamples] {
+		collectUsageExamples(output, input)
+	}
+
+	if len(input.Extensions) > 0 {
+		runExtensions(ctx, output, input)
+	}
+
+	applyKeep(output, input.Keep)
+	output.ColorRules = input.ColorRules
+	markLiveness(output)
+	if enabledPasses(input)[passCLI] {
+		attachCLISurfaces(output)
+	}
+
+	if input.Baseline != "" {
+		if err := applyBaseline(output, input); err != nil {
+			input.warnf("dead-code baseline skipped: %v", err)
+		}
+	}
+
+	if len(input.DependencyRules) > 0 {
+		output.Findings = checkDependencyRules(output, input.DependencyRules)
+	}
+	if input.Duplicates {
+		output.Findings = append(output.Findings, findDuplicates(output)...)
+	}
+	if input.ParameterLists != nil {
+		output.Findings = append(output.Findings, findParameterLists(output, *input.ParameterLists)...)
+	}
+
+	output.Summary = buildSummary(output)
+	output.computeHashes()
+}
+
+// varInitNode is the synthetic entry point of a file's package-level
+// initializers, with an edge to each function they reference.
+func varInitNode(relPath, pkgName string) Node {
+	n := Node{
+		ID:               relPath + ":__var_init__",
+		Name:             "__var_init__",
+		QualifiedName:    relPath + ":__var_init__",
+		FilePath:         relPath,
+		StartLine:        1,
+		EndLine:          1,
+		Language:         "go",
+		Kind:             "init",
+		Visibility:       "module",
+		IsEntryPoint:     true,
+		Parameters:       []Parameter{},
+		UnusedParameters: []string{},
+		PackageOrModule:  filepath.Dir(relPath),
+		LinesOfCode:      1,
+		Sloc:             1,
+		Status:           "entry",
+		Color:            "blue",
+	}
+	if n.PackageOrModule == "." {
+		n.PackageOrModule = pkgName
+	}
+	return n
+}
+
+// ===================================================================
+// Type-aware analysis (primary path)
+// ===================================================================
+
+func analyzeWithTypes(ctx context.Context, input Options, stats *Stats) (Graph, error) {
+	t := time.Now()
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedSyntax |
+			packages.NeedTypes |
+			packages.NeedTypesInfo,
+		Dir:     input.ProjectRoot,
+		Overlay: input.absOverlays(),
+		Env:     input.packagesEnv(),
+	}
+	if input.build != nil {
+		cfg.BuildFlags = input.build.buildFlags()
+	}
+
+	patterns, err := input.loadPatterns()
+	if err != nil {
+		return Graph{}, err
+	}
+	var budgets Budgets
+	if input.Budgets != nil {
+		budgets = *input.Budgets
+	}
+	if budgets.LoadMs > 0 {
+		var cancel context.CancelFunc
+		cfg.Context, cancel = context.WithTimeout(ctx, budget(budgets.LoadMs))
+		defer cancel()
+	}
+	absRoot, _ := filepath.Abs(input.ProjectRoot)
+	if input.SinceRef != "" {
+		// The whole project is still loaded, so that liveness is computed
+		// from every entry point; Analyze emits only the affected packages.
+		affected, err := input.affectedDirs(ctx, cfg, absRoot)
+		if err != nil {
+			return Graph{}, err
+		}
+		if len(affected) == 0 {
+			input.warnf("no Go package changed since %s", input.SinceRef)
+			return Graph{Nodes: []Node{}, Edges: []Edge{}}, nil
+		}
+		stats.sinceDirs = affected
+	}
+	pkgs, cached, err := input.Cache.load(cfg, patterns, absRoot)
+	stats.LoadCached = cached
+	t = stats.phase("load", t)
+	loaded := t
+	if err != nil {
+		if ctx.Err() == nil && cfg.Context.Err() != nil {
+			stats.overBudget("load", budgets.LoadMs, "calls were resolved by name instead")
+		}
+		return Graph{}, err
+	}
+
+	// go/packages always type-checks what it can, so a package with errors
+	// still yields partial syntax and type information. Log its errors but
+	// continue, skipping type errors that are fallout of a syntax error.
+	broken := findBrokenFiles(pkgs, absRoot)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			if e.Kind == packages.TypeError && isBrokenFileError(e, broken) {
+				continue
+			}
+			input.warnf("package %s: %v", pkg.PkgPath, e)
+		}
+	}
+
+	projectPkgs := filterProjectPackages(pkgs, absRoot)
+	if len(projectPkgs) == 0 {
+		return Graph{}, fmt.Errorf("no project packages found under %s", absRoot)
+	}
+	var depPkgs []*packages.Package
+	if len(input.ResolveIntoDeps) > 0 {
+		// Dependency packages are analyzed like the project's own.
+		depPkgs = dependencyPackages(pkgs, absRoot)
+		projectPkgs = append(projectPkgs, depPkgs...)
+	}
+	paths := newSourcePaths(absRoot, depPkgs)
+	stats.Algorithm = AlgorithmTypes
+	stats.PackagesLoaded = len(pkgs)
+	projectPaths := make(map[string]bool, len(projectPkgs))
+	for _, pkg := range projectPkgs {
+		stats.FilesParsed += len(pkg.Syntax)
+		projectPaths[pkg.PkgPath] = true
+	}
+
+	// Phase 1: Extract nodes from all project packages
+	objToNodeID := make(map[types.Object]string)
+	var allNodes []Node
+	passShared := newPassShared(enabledPasses(input))
+	for _, pkg := range projectPkgs {
+		for _, file := range pkg.Syntax {
+			passShared.scanFile(file, newPassContext(file, pkg.Fset, pkg.TypesInfo, passShared))
+		}
+	}
+
+	for _, pkg := range projectPkgs {
+		dependency := slices.Contains(depPkgs, pkg)
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := paths.rel(absPath)
+			if err != nil {
+				continue
+			}
+			passCtx := newPassContext(file, pkg.Fset, pkg.TypesInfo, passShared)
+
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+
+				obj := pkg.TypesInfo.Defs[funcDecl.Name]
+				if obj == nil {
+					continue
+				}
+				funcObj, ok := obj.(*types.Func)
+				if !ok {
+					continue
+				}
+
+				node := buildNodeTyped(funcDecl, file.Comments, pkg.Fset, pkg.TypesInfo, relPath, pkg.Name, funcObj)
+				node.Dependency = dependency
+				collectMetadata(&node, funcDecl, passCtx)
+				allNodes = append(allNodes, node)
+				objToNodeID[funcObj] = node.ID
+			}
+		}
+	}
+
+	t = stats.phase("nodes", t)
+
+	// Phase 2: Collect all concrete named types for interface dispatch
+	var concreteTypes []*types.Named
+	for _, pkg := range projectPkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			tn, ok := obj.(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			if types.IsInterface(named) {
+				continue
+			}
+			concreteTypes = append(concreteTypes, named)
+		}
+	}
+	var mocks map[*types.Named]bool
+	if input.MockImplementations != "" {
+		mocks = findMockTypes(projectPkgs, concreteTypes)
+	}
+	if input.MockImplementations == MockExclude {
+		concreteTypes = slices.DeleteFunc(concreteTypes, func(named *types.Named) bool { return mocks[named] })
+	}
+	implementedBy := newImplementers(concreteTypes)
+	large := findLargeGeneratedTypes(projectPkgs, concreteTypes, objToNodeID, input.MaxGeneratedMethods, stats)
+
+	t = stats.phase("types", t)
+
+	// allEdges collects edges from all phases (2b var-init + 3 call resolution)
+	var allEdges []Edge
+
+	// Phase 2b: Scan package-level var/const declarations for function references.
+	// This handles DI patterns like: var Module = fx.Options(fx.Provide(constructor))
+	// where constructor references are invisible to function-body scanning.
+	for _, pkg := range projectPkgs {
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := paths.rel(absPath)
+			if err != nil {
+				continue
+			}
+
+			var varInitTargets []string // node IDs referenced in var/const inits
+			seen := make(map[string]bool)
+			provenance := make(map[string]Provenance)
+
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				if genDecl.Tok != token.VAR && genDecl.Tok != token.CONST {
+					continue
+				}
+
+				for _, spec := range genDecl.Specs {
+					valSpec, ok := spec.(*ast.ValueSpec)
+					if !ok {
+						continue
+					}
+
+					for _, valExpr := range valSpec.Values {
+						ast.Inspect(valExpr, func(n ast.Node) bool {
+							switch node := n.(type) {
+							case *ast.Ident:
+								if goBuiltins[node.Name] {
+									return true
+								}
+								obj := pkg.TypesInfo.Uses[node]
+								if obj == nil {
+									return true
+								}
+								funcObj, ok := obj.(*types.Func)
+								if !ok {
+									return true
+								}
+								targetID, ok := objToNodeID[funcObj]
+								if !ok {
+									return true
+								}
+								if !seen[targetID] {
+									seen[targetID] = true
+									varInitTargets = append(varInitTargets, targetID)
+									provenance[targetID] = newProvenance("varinit", ruleVarInitRef, valExpr, input.Debug)
+								}
+
+							case *ast.SelectorExpr:
+								// pkg.Func or x.Method references
+								selObj := pkg.TypesInfo.Uses[node.Sel]
+								if selObj == nil {
+									return true
+								}
+								funcObj, ok := selObj.(*types.Func)
+								if !ok {
+									return true
+								}
+								targetID, ok := objToNodeID[funcObj]
+								if !ok {
+									return true
+								}
+								if !seen[targetID] {
+									seen[targetID] = true
+									varInitTargets = append(varInitTargets, targetID)
+									provenance[targetID] = newProvenance("varinit", ruleVarInitRef, valExpr, input.Debug)
+								}
+								return false // don't recurse into X
+							}
+							return true
+						})
+					}
+				}
+			}
+
+			if len(varInitTargets) > 0 {
+				// Create synthetic __var_init__ node for this file
+				syntheticID := relPath + ":__var_init__"
+				syntheticNode := varInitNode(relPath, pkg.Name)
+				allNodes = append(allNodes, syntheticNode)
+
+				// Create edges from synthetic node to each referenced function
+				for _, targetID := range varInitTargets {
+					if stats.explain.watching(syntheticID) {
+						stats.explain.record(token.Position{}, []string{targetID}, "a package-level initializer references %s", targetID)
+					}
+					allEdges = append(allEdges, Edge{
+						Source: syntheticID,
+						Target: targetID,
+						CallSite: CallSite{
+							FilePath: relPath,
+							Line:     1,
+							Column:   1,
+						},
+						Kind:       "varinit",
+						IsResolved: true,
+						Provenance: provenance[targetID],
+					})
+				}
+
+			}
+		}
+	}
+
+	t = stats.phase("varinit", t)
+
+	// Phase 2c: For every function (constructor) that returns a named type,
+	// create edges from the function to all methods on the returned type.
+	// This models the Go constructor pattern: if NewFoo() returns *Foo or FooInterface,
+	// and NewFoo is reachable, then methods on the returned type are callable.
+	// For interface return types, fan out to all concrete implementations' methods.
+	// Empty interfaces, which every type implements, follow Options.AnyDispatch.
+	var anyDiagnostics []Diagnostic
+	needAnyNode := false
+	for obj, nodeID := range objToNodeID {
+		funcObj, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig, ok := funcObj.Type().(*types.Signature)
+		if !ok {
+			continue
+		}
+		// Skip methods — only process standalone functions (constructors)
+		if sig.Recv() != nil {
+			continue
+		}
+		results := sig.Results()
+		anyDone := false
+		for ri := 0; ri < results.Len(); ri++ {
+			returnType := results.At(ri).Type()
+			// Unwrap pointer
+			if ptr, ok := returnType.(*types.Pointer); ok {
+				returnType = ptr.Elem()
+			}
+			prov := Provenance{Phase: "constructors"}
+			if input.Debug {
+				prov.Snippet = shortSnippet(types.ObjectString(funcObj, types.RelativeTo(funcObj.Pkg())))
+			}
+			if isEmptyInterface(returnType) {
+				if anyDone {
+					continue
+				}
+				anyDone = true
+				prov.Rule = ruleAnyReturn
+				pos := projectPkgs[0].Fset.Position(funcObj.Pos())
+				relPath, _ := paths.rel(pos.Filename)
+				diag, useAnyNode := anyDispatch(input, nodeID, funcObj, pos, filepath.ToSlash(relPath), concreteTypes, large, objToNodeID, prov, &allEdges)
+				if diag != nil {
+					anyDiagnostics = append(anyDiagnostics, *diag)
+				}
+				needAnyNode = needAnyNode || useAnyNode
+				continue
+			}
+			named, ok := returnType.(*types.Named)
+			if !ok {
+				continue
+			}
+
+			before := len(allEdges)
+			if iface, isIface := named.Underlying().(*types.Interface); isIface {
+				// Return type is an interface — fan out to all concrete implementations
+				prov.Rule = ruleInterfaceReturn
+				addMethodEdgesForInterface(nodeID, iface, implementedBy, large, objToNodeID, prov, &allEdges)
+			} else {
+				// Return type is a concrete type — add direct method edges
+				prov.Rule = ruleConcreteReturn
+				addMethodEdgesForType(nodeID, named, large, objToNodeID, prov, &allEdges)
+			}
+			if stats.explain.watching(nodeID) {
+				var targets []string
+				for _, e := range allEdges[before:] {
+					targets = append(targets, e.Target)
+				}
+				stats.explain.record(token.Position{}, targets, "constructor returns %s, so %d project methods of it (or of its implementations) are assumed callable: %s",
+					results.At(ri).Type(), len(targets), strings.Join(targets, ", "))
+			}
+		}
+	}
+
+	sort.Slice(anyDiagnostics, func(i, j int) bool {
+		a, b := anyDiagnostics[i], anyDiagnostics[j]
+		return a.FilePath < b.FilePath || a.FilePath == b.FilePath && a.Line < b.Line
+	})
+	if needAnyNode {
+		allNodes = append(allNodes, externalNode(anyNodeID, "any", "any"))
+	}
+	large.emit(&allNodes, &allEdges)
+
+	t = stats.phase("constructors", t)
+
+	if len(input.RegistryRules) > 0 {
+		applyRegistryRules(input.RegistryRules, projectPkgs, paths, objToNodeID, allNodes, &allEdges, input.Debug)
+		t = stats.phase("registry", t)
+	}
+
+	allNodes = append(allNodes, detectPlugins(projectPkgs, paths, objToNodeID, allNodes, &allEdges, input.Debug)...)
+	t = stats.phase("plugins", t)
+
+	if input.Embeds {
+		addEmbedNodes(projectPkgs, paths, objToNodeID, &allNodes, &allEdges, input.Debug)
+		t = stats.phase("embeds", t)
+	}
+
+	if input.Migrations {
+		linkMigrations(projectPkgs, paths, objToNodeID, &allNodes, &allEdges, input.Debug)
+		t = stats.phase("migrations", t)
+	}
+
+	// Routes are always extracted for the chain edges of their middleware.
+	routes := extractRoutes(projectPkgs, paths, objToNodeID, &allEdges, input.Debug)
+	if !input.Routes {
+		routes = nil
+	}
+	t = stats.phase("routes", t)
+	var initGraph *InitGraph
+	if input.InitGraph {
+		initGraph = buildInitGraph(projectPkgs, paths, objToNodeID)
+		t = stats.phase("initgraph", t)
+	}
+	var interfaceGaps *InterfaceGaps
+	if input.InterfaceGaps {
+		interfaceGaps = findInterfaceGaps(projectPkgs, paths, implementedBy)
+		t = stats.phase("interfacegaps", t)
+	}
+	var linkerVars []LinkerVar
+	if input.buildInfo != nil {
+		linkerVars = findLinkerVars(input.buildInfo.linkerVars(), projectPkgs, paths, objToNodeID, input.warnf)
+	}
+
+	// Cache for interface method → concrete implementations
+	ifaceImplCache := make(map[*types.Func][]*types.Func)
+
+	external := loadExternalGraphs(input)
+	var outOfScope func(*types.Func) bool
+	if input.KeepUnresolved || external != nil {
+		outOfScope = func(fn *types.Func) bool {
+			path := fn.Pkg().Path()
+			inModule := input.KeepUnresolved && (path == input.Module || strings.HasPrefix(path, input.Module+"/"))
+			return inModule || external.has(funcSymbol(fn))
+		}
+	}
+
+	// Phase 3: Resolve calls with type information
+	fanouts := newFanoutNodes(input.MaxFanout)
+
+	resolved, overBudget := 0, false
+resolve:
+	for _, pkg := range projectPkgs {
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := paths.rel(absPath)
+			if err != nil {
+				continue
+			}
+
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+
+				sourceObj := pkg.TypesInfo.Defs[funcDecl.Name]
+				if sourceObj == nil {
+					continue
+				}
+				sourceID := objToNodeID[sourceObj]
+				if sourceID == "" {
+					continue
+				}
+				if budgets.ResolveMs > 0 && time.Since(loaded) > budget(budgets.ResolveMs) {
+					overBudget = true
+					break resolve
+				}
+				resolved++
+
+				edges := resolveCallsTyped(funcDecl, pkg, relPath, sourceID,
+					objToNodeID, implementedBy, ifaceImplCache, projectPaths, outOfScope, fanouts, input.Debug, stats)
+				allEdges = append(allEdges, edges...)
+			}
+		}
+	}
+	if input.MockImplementations == MockTag {
+		tagMockEdges(allEdges, mocks, objToNodeID)
+	}
+	diagnostics := recoverBrokenFiles(broken, absRoot, cfg.Overlay, &allNodes, &allEdges, objToNodeID, passShared, input.Debug, stats)
+	external.stitch(&allNodes, &allEdges)
+	fanouts.emit(&allNodes, &allEdges, &diagnostics)
+	t = stats.phase("calls", t)
+	if overBudget {
+		stats.overBudget("resolve", budgets.ResolveMs, fmt.Sprintf("calls were resolved in only %d functions", resolved))
+	}
+
+	if passShared.passes[passDeadParams] && len(stats.truncated) == 0 {
+		findRemovableParameters(projectPkgs, paths, objToNodeID, allNodes)
+		stats.phase("deadparams", t)
+	}
+
+	if allNodes == nil {
+		allNodes = []Node{}
+	}
+	if allEdges == nil {
+		allEdges = []Edge{}
+	}
+
+	diagnostics = append(diagnostics, anyDiagnostics...)
+
+	return Graph{Nodes: allNodes, Edges: allEdges, Diagnostics: diagnostics, Routes: routes, InitGraph: initGraph, InterfaceGaps: interfaceGaps, LinkerVars: linkerVars}, nil
+}
+
+// filterProjectPackages keeps only packages whose files reside under the project root.
+func filterProjectPackages(pkgs []*packages.Package, absRoot string) []*packages.Package {
+	var result []*packages.Package
+	for _, pkg := range pkgs {
+		files := pkg.CompiledGoFiles
+		if len(files) == 0 {
+			files = pkg.GoFiles
+		}
+		for _, f := range files {
+			if strings.HasPrefix(f, absRoot) {
+				result = append(result, pkg)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// buildNodeTyped creates a Node using typed function information. comments
+// are those of the declaring file.
+func buildNodeTyped(funcDecl *ast.FuncDecl, comments []*ast.CommentGroup, fset *token.FileSet, info *types.Info, relPath, pkgName string, funcObj *types.Func) Node {
+	name := funcDecl.Name.Name
+	kind := "function"
+	var receiver string
+
+	sig := funcObj.Type().(*types.Signature)
+	if sig.Recv() != nil {
+		kind = "method"
+		receiver = getReceiverTypeName(funcDecl.Recv.List[0].Type)
+	}
+
+	qualified := name
+	if receiver != "" {
+		qualified = receiver + "." + name
+	}
+
+	nodeID := relPath + ":" + qualified
+
+	visibility := "module"
+	if ast.IsExported(name) {
+		visibility = "exported"
+	}
+
+	isEntry := false
+	if name == "main" && pkgName == "main" {
+		isEntry = true
+	}
+	if name == "init" {
+		isEntry = true
+	}
+	if strings.HasPrefix(name, "Test") || strings.HasPrefix(name, "Benchmark") || strings.HasPrefix(name, "Example") {
+		isEntry = true
+	}
+	directiveEntry, keep := funcDirectives(funcDecl)
+	isEntry = isEntry || directiveEntry
+	keptBy := ""
+	if keep {
+		keptBy = keptByDirective
+	}
+
+	startPos := fset.Position(funcDecl.Pos())
+	endPos := fset.Position(funcDecl.End())
+
+	params, unusedParams := checkParametersTyped(funcDecl, sig, info)
+	sloc, commentLines, blankLines := countLines(fset, funcDecl, comments)
+
+	pkg := filepath.Dir(relPath)
+	if pkg == "." {
+		pkg = pkgName
+	}
+
+	return Node{
+		ID:               nodeID,
+		Name:             name,
+		QualifiedName:    relPath + ":" + qualified,
+		FilePath:         relPath,
+		StartLine:        startPos.Line,
+		EndLine:          endPos.Line,
+		Language:         "go",
+		Kind:             kind,
+		Visibility:       visibility,
+		IsEntryPoint:     isEntry,
+		Parameters:       params,
+		UnusedParameters: unusedParams,
+		PackageOrModule:  pkg,
+		LinesOfCode:      endPos.Line - startPos.Line + 1,
+		Sloc:             sloc,
+		CommentLines:     commentLines,
+		BlankLines:       blankLines,
+		Status:           "dead",
+		Color:            "red",
+		KeptBy:           keptBy,
+		Deprecated:       isDeprecated(funcDecl.Doc),
+		SymbolID:         linkerSymbol(funcObj.Pkg().Path(), pkgName, receiver, isPointerReceiver(funcDecl), name),
+		BodyHash:         bodyHash(fset, funcDecl),
+		trivial:          isTrivial(funcDecl),
+	}
+}
+
+// checkParametersTyped extracts parameters using the type-checked signature.
+// A parameter is used if an identifier in the body, including in nested
+// closures, refers to it; shadowing variables and fields of the same name
+// do not count.
+func checkParametersTyped(funcDecl *ast.FuncDecl, sig *types.Signature, info *types.Info) ([]Parameter, []string) {
+	sigParams := sig.Params()
+	if sigParams.Len() == 0 {
+		return []Parameter{}, []string{}
+	}
+
+	used := make(map[types.Object]bool)
+	if funcDecl.Body != nil {
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if obj := info.Uses[ident]; obj != nil {
+					used[obj] = true
+				}
+			}
+			return true
+		})
+	}
+
+	var params []Parameter
+	var unused []string
+
+	for i := 0; i < sigParams.Len(); i++ {
+		v := sigParams.At(i)
+		pName := v.Name()
+		typeStr := simplifyType(v.Type().String())
+
+		isUsed := true
+		if pName == "" || pName == "_" {
+			pName = "_"
+		} else if funcDecl.Body == nil {
+			// interface method — assume used
+		} else {
+			isUsed = used[v]
+		}
+
+		params = append(params, Parameter{
+			Name:     pName,
+			Type:     &typeStr,
+			IsUsed:   isUsed,
+			Position: i,
+		})
+
+		if !isUsed && pName != "_" {
+			unused = append(unused, pName)
+		}
+	}
+
+	if unused == nil {
+		unused = []string{}
+	}
+
+	return params, unused
+}
+
+// simplifyType strips full package paths from a type string.
+// "github.com/foo/bar.Handler" → "bar.Handler"
+// "*github.com/foo/bar.Handler" → "*bar.Handler"
+func simplifyType(s string) string {
+	var result strings.Builder
+	i := 0
+	for i < len(s) {
+		j := i
+		for j < len(s) {
+			c := s[j]
+			if c == '/' {
+				// Package path separator — skip the segment before it
+				i = j + 1
+				break
+			}
+			if c == ' ' || c == '[' || c == ']' || c == '(' || c == ')' || c == ',' || c == '*' {
+				// Stop character — copy segment including this char
+				result.WriteString(s[i : j+1])
+				i = j + 1
+				break
+			}
+			j++
+		}
+		if j >= len(s) {
+			result.WriteString(s[i:])
+			break
+		}
+	}
+	return result.String()
+}
+
+// resolveCallsTyped walks a function body and resolves calls AND function/method
+// value references using type information. This handles patterns like:
+//   - Direct calls: foo(), x.Method(), pkg.Func()
+//   - Interface dispatch: ifaceVar.Method() → all concrete implementations
+//   - Method value refs: withProfile(ctrl.handleGetMe) → edge to handleGetMe
+//   - Function value refs: register(myHandler) → edge to myHandler
+//
+// Calls to functions outside the project selected by outOfScope (when not
+// nil) become unresolved edges targeting the callee's linker symbol.
+func resolveCallsTyped(
+	funcDecl *ast.FuncDecl,
+	pkg *packages.Package,
+	relPath, sourceID string,
+	objToNodeID map[types.Object]string,
+	implementedBy *implementers,
+	ifaceImplCache map[*types.Func][]*types.Func,
+	projectPaths map[string]bool,
+	outOfScope func(*types.Func) bool,
+	fanouts *fanoutNodes,
+	debug bool,
+	stats *Stats,
+) []Edge {
+	var edges []Edge
+	seen := make(map[string]int) // deduplicate edges by "source->target", value is index into edges
+	weights := estimateCallWeights(funcDecl.Body)
+
+	addEdge := func(target string, at ast.Node, kind, rule string) {
+		w := weights[at]
+		call, _ := at.(*ast.CallExpr)
+		var args []ConstantArg
+		if call != nil {
+			args = constantArgs(call, pkg.Fset, pkg.TypesInfo)
+		}
+		key := sourceID + "->" + target
+		if idx, ok := seen[key]; ok {
+			// Repeated call sites accumulate into the first edge's weight
+			edges[idx].Weight += w.weight
+			if contextRank(w.context) > contextRank(edges[idx].CallContext) {
+				edges[idx].CallContext = w.context
+			}
+			edges[idx].ConstantArgs = append(edges[idx].ConstantArgs, args...)
+			if call != nil {
+				edges[idx].addCallShape(call, pkg.TypesInfo, nil)
+			}
+			return
+		}
+		seen[key] = len(edges)
+		pos := pkg.Fset.Position(at.Pos())
+		edges = append(edges, Edge{
+			Source: sourceID,
+			Target: target,
+			CallSite: CallSite{
+				FilePath: relPath,
+				Line:     pos.Line,
+				Column:   pos.Column,
+			},
+			Kind:         kind,
+			IsResolved:   true,
+			Weight:       w.weight,
+			CallContext:  w.context,
+			ConstantArgs: args,
+			Provenance:   newProvenance("calls", rule, at, debug),
+		})
+		if call != nil {
+			edges[len(edges)-1].addCallShape(call, pkg.TypesInfo, nil)
+		}
+	}
+
+	addOutOfScope := func(fn *types.Func, at ast.Node, kind string) {
+		if outOfScope == nil || fn.Pkg() == nil || !outOfScope(fn) {
+			return
+		}
+		n := len(edges)
+		addEdge(funcSymbol(fn), at, kind, ruleOutOfScope)
+		if len(edges) > n {
+			edges[n].IsResolved = false
+		}
+	}
+
+	// note records a resolution decision when this function is being explained.
+	explaining := stats.explain.watching(sourceID)
+	note := func(at ast.Node, targets []string, format string, args ...any) {
+		if explaining {
+			stats.explain.record(pkg.Fset.Position(at.Pos()), targets, format, args...)
+		}
+	}
+
+	asserted := assertedVars(funcDecl.Body, pkg.TypesInfo)
+
+	// Track which SelectorExprs are call targets (handled in the call path)
+	callFuncs := make(map[ast.Node]bool)
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		if ce, ok := n.(*ast.CallExpr); ok {
+			callFuncs[ce.Fun] = true
+		}
+		return true
+	})
+
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			// Handle function/method calls
+			switch fn := node.Fun.(type) {
+			case *ast.Ident:
+				// Plain function call: foo()
+				if goBuiltins[fn.Name] {
+					return true
+				}
+				obj := pkg.TypesInfo.Uses[fn]
+				if obj == nil {
+					return true
+				}
+				funcObj, ok := obj.(*types.Func)
+				if !ok {
+					if _, isVar := obj.(*types.Var); isVar {
+						stats.UnresolvedCalls++ // call through a function value
+						note(node, nil, "%s(...) calls through the function value %s; its callee is unknown statically", fn.Name, obj)
+					}
+					return true
+				}
+				targetID, ok := objToNodeID[funcObj]
+				note(node, []string{targetID}, "%s(...) calls %s", fn.Name, explainTarget(funcObj, targetID))
+				if !ok {
+					stats.ExternalCalls++
+					addOutOfScope(funcObj, node, "direct")
+					return true
+				}
+				if targetID == sourceID {
+					return true
+				}
+				addEdge(targetID, node, "direct", ruleCall)
+
+			case *ast.SelectorExpr:
+				// x.Method() or pkg.Func()
+				if goBuiltins[fn.Sel.Name] {
+					return true
+				}
+
+				// Check if this is a package-qualified call (pkg.Func)
+				if ident, ok := fn.X.(*ast.Ident); ok {
+					xObj := pkg.TypesInfo.Uses[ident]
+					if _, isPkg := xObj.(*types.PkgName); isPkg {
+						selObj := pkg.TypesInfo.Uses[fn.Sel]
+						if selObj == nil {
+							return true
+						}
+						funcObj, ok := selObj.(*types.Func)
+						if !ok {
+							if _, isVar := selObj.(*types.Var); isVar {
+								stats.UnresolvedCalls++
+								note(node, nil, "%s(...) calls through the package variable %s; its callee is unknown statically", types.ExprString(fn), selObj)
+							}
+							return true
+						}
+						targetID, ok := objToNodeID[funcObj]
+						note(node, []string{targetID}, "%s(...) calls %s", types.ExprString(fn), explainTarget(funcObj, targetID))
+						if !ok {
+							stats.ExternalCalls++
+							addOutOfScope(funcObj, node, "direct")
+							return true
+						}
+						if targetID == sourceID {
+							return true
+						}
+						addEdge(targetID, node, "direct", rulePackageCall)
+						return true
+					}
+				}
+
+				// Method call: x.Method()
+				selection, ok := pkg.TypesInfo.Selections[fn]
+				if !ok {
+					note(node, nil, "%s(...) has no type-checked selection (the expression did not type-check)", types.ExprString(fn))
+					return true
+				}
+
+				methodObj, ok := selection.Obj().(*types.Func)
+				if !ok {
+					stats.UnresolvedCalls++ // call through a func-typed field
+					note(node, nil, "%s(...) calls through the field %s; its callee is unknown statically", types.ExprString(fn), selection.Obj())
+					return true
+				}
+
+				// Receiver narrowed by a type assertion or type switch
+				if concrete := assertedTypes(fn.X, pkg.TypesInfo, asserted); len(concrete) > 0 {
+					var targets []string
+					for _, t := range concrete {
+						obj, _, _ := types.LookupFieldOrMethod(t, true, methodObj.Pkg(), methodObj.Name())
+						m, ok := obj.(*types.Func)
+						if !ok {
+							continue
+						}
+						targetID, ok := objToNodeID[m.Origin()]
+						if !ok {
+							stats.ExternalCalls++
+							continue
+						}
+						targets = append(targets, targetID)
+						if targetID != sourceID {
+							addEdge(targetID, node, "assert", ruleTypeAssertion)
+						}
+					}
+					note(node, targets, "%s(...) calls method %s of the asserted types %s", types.ExprString(fn), methodObj.Name(), types.TypeString(concrete[0], nil))
+					return true
+				}
+
+				// Check if receiver is an interface type
+				recvType := selection.Recv()
+				if ptr, ok := recvType.(*types.Pointer); ok {
+					recvType = ptr.Elem()
+				}
+
+				if iface, isIface := recvType.Underlying().(*types.Interface); isIface {
+					// Interface method call — fan out to all concrete implementations
+					impls := resolveIfaceImpls(methodObj, iface, implementedBy, objToNodeID, ifaceImplCache)
+					if methodObj.Pkg() != nil && !projectPaths[methodObj.Pkg().Path()] && len(impls) == 0 {
+						stats.ExternalCalls++ // e.g. io.Writer.Write on a value from outside
+					} else {
+						stats.interfaceCall(len(impls))
+					}
+					if explaining {
+						var targets, matched []string
+						for _, impl := range impls {
+							targets = append(targets, objToNodeID[impl])
+							matched = append(matched, explainTarget(impl, objToNodeID[impl]))
+						}
+						note(node, targets, "%s(...) selects method %s of interface %s; of %d concrete project types, %d implement it: %s",
+							types.ExprString(fn), methodObj.Name(), selection.Recv(), len(implementedBy.concrete), len(impls), strings.Join(matched, ", "))
+					}
+					if fanouts.over(len(impls)) {
+						ids := make([]string, len(impls))
+						for i, impl := range impls {
+							ids[i] = objToNodeID[impl]
+						}
+						pos := pkg.Fset.Position(node.Pos())
+						id := fanouts.add(methodObj, ids, CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column})
+						note(node, []string{id}, "%d implementations exceed maxFanout, so the call goes through %s", len(impls), id)
+						addEdge(id, node, "interface", ruleInterfaceFanout)
+						return true
+					}
+					for _, impl := range impls {
+						targetID, ok := objToNodeID[impl]
+						if !ok || targetID == sourceID {
+							continue
+						}
+						addEdge(targetID, node, "interface", ruleInterfaceDispatch)
+					}
+				} else {
+					// Concrete method call
+					targetID, ok := objToNodeID[methodObj]
+					note(node, []string{targetID}, "%s(...) selects method %s on concrete type %s", types.ExprString(fn), explainTarget(methodObj, targetID), selection.Recv())
+					if !ok {
+						stats.ExternalCalls++
+						addOutOfScope(methodObj, node, "method")
+						return true
+					}
+					if targetID == sourceID {
+						return true
+					}
+					addEdge(targetID, node, "method", ruleMethodCall)
+				}
+
+			case *ast.FuncLit:
+				// Immediately invoked closure; its body is walked in place.
+
+			default:
+				// Generic instantiations, returned funcs, map/slice elements, ...
+				if tv, ok := pkg.TypesInfo.Types[node.Fun]; !ok || !tv.IsType() {
+					stats.UnresolvedCalls++
+					note(node, nil, "%s(...) calls a computed function value; its callee is unknown statically", types.ExprString(node.Fun))
+				}
+			}
+
+		case *ast.SelectorExpr:
+			// Method/function value reference (not a call): ctrl.handleGetMe
+			// This handles patterns like: withProfile(ctrl.handleGetMe)
+			// where handleGetMe is passed as a value, not invoked.
+			if callFuncs[node] {
+				return true // Already handled as a call target above
+			}
+
+			selection, ok := pkg.TypesInfo.Selections[node]
+			if !ok {
+				return true
+			}
+
+			// Only track method values (MethodVal), not field access
+			if selection.Kind() != types.MethodVal {
+				return true
+			}
+
+			methodObj, ok := selection.Obj().(*types.Func)
+			if !ok {
+				return true
+			}
+
+			targetID, ok := objToNodeID[methodObj]
+			if !ok || targetID == sourceID {
+				return true
+			}
+			note(node, []string{targetID}, "%s is used as a method value of %s", types.ExprString(node), explainTarget(methodObj, targetID))
+			addEdge(targetID, node, "funcref", ruleMethodValue)
+
+		case *ast.Ident:
+			// Function value reference (not a call): passed as argument
+			// e.g., register(myHandler) where myHandler is a package-level func
+			if callFuncs[node] {
+				return true // Already handled as a call target above
+			}
+			if goBuiltins[node.Name] {
+				return true
+			}
+
+			obj := pkg.TypesInfo.Uses[node]
+			if obj == nil {
+				return true
+			}
+			funcObj, ok := obj.(*types.Func)
+			if !ok {
+				return true
+			}
+			targetID, ok := objToNodeID[funcObj]
+			if !ok || targetID == sourceID {
+				return true
+			}
+			note(node, []string{targetID}, "%s is used as a function value of %s", node.Name, explainTarget(funcObj, targetID))
+			addEdge(targetID, node, "funcref", ruleFuncValue)
+		}
+
+		return true
+	})
+
+	return edges
+}
+
+// addMethodEdgesForType creates edges from sourceID to all methods on a concrete named type.
+// Large generated types get a single edge instead (see largeTypes).
+func addMethodEdgesForType(sourceID string, named *types.Named, large *largeTypes, objToNodeID map[types.Object]string, prov Provenance, edges *[]Edge) {
+	if large.summarize(sourceID, named, prov, edges) {
+		return
+	}
+	mset := types.NewMethodSet(types.NewPointer(named))
+	for mi := 0; mi < mset.Len(); mi++ {
+		methodFunc, ok := mset.At(mi).Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		methodID, exists := objToNodeID[methodFunc]
+		if !exists || methodID == sourceID {
+			continue
+		}
+		*edges = append(*edges, Edge{
+			Source:     sourceID,
+			Target:     methodID,
+			CallSite:   CallSite{},
+			Kind:       "provided",
+			Provenance: prov,
+		})
+	}
+}
+
+// addMethodEdgesForInterface creates edges from sourceID to all methods on all concrete types
+// that implement the given interface. This handles constructors that return interface types.
+func addMethodEdgesForInterface(
+	sourceID string,
+	iface *types.Interface,
+	implementedBy *implementers,
+	large *largeTypes,
+	objToNodeID map[types.Object]string,
+	prov Provenance,
+	edges *[]Edge,
+) {
+	for _, ct := range implementedBy.of(iface) {
+		addMethodEdgesForType(sourceID, ct, large, objToNodeID, prov, edges)
+	}
+}
+
+// resolveIfaceImpls finds all concrete method implementations for an interface method.
+func resolveIfaceImpls(
+	ifaceMethod *types.Func,
+	iface *types.Interface,
+	implementedBy *implementers,
+	objToNodeID map[types.Object]string,
+	cache map[*types.Func][]*types.Func,
+) []*types.Func {
+	if impls, cached := cache[ifaceMethod]; cached {
+		return impls
+	}
+
+	var impls []*types.Func
+	for _, ct := range implementedBy.of(iface) {
+		method, _, _ := types.LookupFieldOrMethod(ct, true, ifaceMethod.Pkg(), ifaceMethod.Name())
+		if fn, ok := method.(*types.Func); ok {
+			if _, inProject := objToNodeID[fn]; inProject {
+				impls = append(impls, fn)
+			}
+		}
+	}
+	cache[ifaceMethod] = impls
+	return impls
+}
+
+// ===================================================================
+// AST-only analysis (fallback when type-aware analysis is unavailable)
+// ===================================================================
+
+// AnalyzeSources runs the AST-only analysis over in-memory sources keyed by
+// project-relative file name, without touching the file system. opts.Files
+// is replaced by the source names.
+func AnalyzeSources(sources map[string]string, input Options) Graph {
+	contents := make(map[string][]byte, len(sources))
+	input.Files = make([]string, 0, len(sources))
+	for name, src := range sources {
+		contents[name] = []byte(src)
+		input.Files = append(input.Files, name)
+	}
+	sort.Strings(input.Files)
+
+	stats := &Stats{explain: newExplainer(input.Explain)}
+	if input.APISurface {
+		output := analyzeAPISurface(input, contents, stats)
+		stats.finish(&output)
+		output.Stats = stats
+		return output
+	}
+	output := analyzeFilesASTOnly(input, contents, stats)
+	t := time.Now()
+	postProcess(context.Background(), &output, input)
+	input.prune(&output)
+	input.slice(&output)
+	input.applyTrivialWrappers(&output)
+	input.filterNodes(&output)
+	output.countCalls()
+	stats.phase("postprocess", t)
+	stats.finish(&output)
+	output.Stats = stats
+	output.Explanation = stats.explain.explain(&output)
+	output.Policy = newPolicy(input.FailOn)
+	output.Policy.evaluate(&output)
+	if input.SearchIndex {
+		output.SearchIndex = buildSearchIndex(&output)
+	}
+	if input.Hierarchy {
+		output.Hierarchy = buildHierarchy(&output, input.Module)
+	}
+	if input.Bundles {
+		output.Bundles = buildBundles(&output)
+	}
+	if input.AISummary {
+		output.AISummary = buildAISummary(&output)
+	}
+	return output
+}
+
+// analyzeFilesASTOnly parses input.Files and extracts nodes and edges without
+// type information. Files present in sources (keyed as in input.Files, or
+// relative and slash-separated) are parsed from memory instead of being read
+// from disk.
+func analyzeFilesASTOnly(input Options, sources map[string][]byte, stats *Stats) Graph {
+	t := time.Now()
+	fset := token.NewFileSet()
+	var allNodes []Node
+	var allEdges []Edge
+	funcMap := make(map[string]*Node)
+
+	type parsedFile struct {
+		path string
+		file *ast.File
+	}
+	type brokenSource struct {
+		path, absPath string
+		src           []byte
+		partial       *ast.File
+	}
+	var parsed []parsedFile
+	var broken []brokenSource
+	var diagnostics []Diagnostic
+	dirPkgNames := make(map[string]string)
+	for _, filePath := range input.Files {
+		absPath := filepath.Join(input.ProjectRoot, filePath)
+		src, ok := sources[filePath]
+		if !ok {
+			src, ok = sources[cleanRel(filePath)]
+		}
+		if !ok {
+			var err error
+			if src, err = os.ReadFile(absPath); err != nil {
+				continue
+			}
+		}
+		f, err := parser.ParseFile(fset, absPath, src, parser.ParseComments)
+		if err != nil {
+			// Keep what parsed; the rest is recovered once package names are known.
+			diagnostics = append(diagnostics, diagnosticFromScanErr(filePath, err))
+			broken = append(broken, brokenSource{filePath, absPath, src, f})
+			if hasPackageName(f) && len(f.Decls) > 0 {
+				parsed = append(parsed, parsedFile{filePath, f})
+			}
+			continue
+		}
+		parsed = append(parsed, parsedFile{filePath, f})
+		dirPkgNames[filepath.Dir(filePath)] = f.Name.Name
+	}
+	stats.FilesParsed = len(parsed)
+
+	brokenSrc := make(map[string][]byte, len(broken))
+	for _, b := range broken {
+		brokenSrc[b.path] = b.src
+		if rec := recoverFuncDecls(fset, b.absPath, b.src, b.partial, dirPkgNames[filepath.Dir(b.path)]); rec != nil {
+			parsed = append(parsed, parsedFile{b.path, rec})
+		}
+	}
+
+	stats.Algorithm = AlgorithmAST
+	t = stats.phase("parse", t)
+
+	passShared := newPassShared(enabledPasses(input))
+	for _, pf := range parsed {
+		passShared.scanFile(pf.file, newPassContext(pf.file, fset, nil, passShared))
+	}
+
+	for _, pf := range parsed {
+		filePath, f := pf.path, pf.file
+		pkgName := f.Name.Name
+		pkgPath := input.Module
+		if dir := filepath.ToSlash(filepath.Dir(filePath)); dir != "." {
+			pkgPath = input.Module + "/" + dir
+		}
+		nodes := extractNodes(f, fset, filePath, pkgName, pkgPath)
+		annotateFileNodes(f, fset, nodes, passShared)
+		if src, ok := brokenSrc[filePath]; ok {
+			for i := range nodes {
+				nodes[i].Approximate = true
+			}
+			clampBrokenExtents(nodes, src)
+		}
+
+		for i := range nodes {
+			allNodes = append(allNodes, nodes[i])
+			funcMap[nodes[i].ID] = &allNodes[len(allNodes)-1]
+			funcMap[nodes[i].Name] = &allNodes[len(allNodes)-1]
+		}
+	}
+	t = stats.phase("nodes", t)
+
+	for _, pf := range parsed {
+		filePath, f := pf.path, pf.file
+		pkgName := f.Name.Name
+		edges := extractEdges(f, fset, filePath, pkgName, funcMap, input.Debug, stats)
+		allEdges = append(allEdges, edges...)
+	}
+	stats.phase("calls", t)
+
+	if allNodes == nil {
+		allNodes = []Node{}
+	}
+	if allEdges == nil {
+		allEdges = []Edge{}
+	}
+
+	return Graph{Nodes: allNodes, Edges: allEdges, Diagnostics: diagnostics}
+}
+
+func extractNodes(f *ast.File, fset *token.FileSet, filePath, pkgName, pkgPath string) []Node {
+	var nodes []Node
+
+	for _, decl := range f.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		name := funcDecl.Name.Name
+		kind := "function"
+		var receiver string
+
+		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+			kind = "method"
+			receiver = getReceiverTypeName(funcDecl.Recv.List[0].Type)
+		}
+
+		qualified := name
+		if receiver != "" {
+			qualified = receiver + "." + name
+		}
+
+		nodeID := filePath + ":" + qualified
+
+		visibility := "module"
+		if ast.IsExported(name) {
+			visibility = "exported"
+		}
+
+		isEntry := false
+		if name == "main" && pkgName == "main" {
+			isEntry = true
+		}
+		if name == "init" {
+			isEntry = true
+		}
+		if strings.HasPrefix(name, "Test") || strings.HasPrefix(name, "Benchmark") || strings.HasPrefix(name, "Example") {
+			isEntry = true
+		}
+		directiveEntry, keep := funcDirectives(funcDecl)
+		isEntry = isEntry || directiveEntry
+		keptBy := ""
+		if keep {
+			keptBy = keptByDirective
+		}
+
+		startPos := fset.Position(funcDecl.Pos())
+		endPos := fset.Position(funcDecl.End())
+
+		params, unusedParams := checkParameters(funcDecl)
+		sloc, commentLines, blankLines := countLines(fset, funcDecl, f.Comments)
+
+		pkg := filepath.Dir(filePath)
+		if pkg == "." {
+			pkg = pkgName
+		}
+
+		nodes = append(nodes, Node{
+			ID:               nodeID,
+			Name:             name,
+			QualifiedName:    filePath + ":" + qualified,
+			FilePath:         filePath,
+			StartLine:        startPos.Line,
+			EndLine:          endPos.Line,
+			Language:         "go",
+			Kind:             kind,
+			Visibility:       visibility,
+			IsEntryPoint:     isEntry,
+			Parameters:       params,
+			UnusedParameters: unusedParams,
+			PackageOrModule:  pkg,
+			LinesOfCode:      endPos.Line - startPos.Line + 1,
+			Sloc:             sloc,
+			CommentLines:     commentLines,
+			BlankLines:       blankLines,
+			Status:           "dead",
+			Color:            "red",
+			KeptBy:           keptBy,
+			Deprecated:       isDeprecated(funcDecl.Doc),
+			SymbolID:         linkerSymbol(pkgPath, pkgName, receiver, isPointerReceiver(funcDecl), name),
+			BodyHash:         bodyHash(fset, funcDecl),
+			trivial:          isTrivial(funcDecl),
+		})
+	}
+
+	return nodes
+}
+
+func checkParameters(funcDecl *ast.FuncDecl) ([]Parameter, []string) {
+	if funcDecl.Type.Params == nil {
+		return []Parameter{}, []string{}
+	}
+
+	usedNames := make(map[string]bool)
+	if funcDecl.Body != nil {
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				usedNames[ident.Name] = true
+			}
+			return true
+		})
+	}
+
+	var params []Parameter
+	var unused []string
+	pos := 0
+
+	for _, field := range funcDecl.Type.Params.List {
+		typeStr := formatFieldType(field)
+
+		if len(field.Names) == 0 {
+			params = append(params, Parameter{
+				Name:     "_",
+				Type:     &typeStr,
+				IsUsed:   true,
+				Position: pos,
+			})
+			pos++
+			continue
+		}
+
+		for _, name := range field.Names {
+			pName := name.Name
+			isUsed := true
+
+			if pName == "_" {
+				// Intentionally unused
+			} else if funcDecl.Body == nil {
+				// No body, assume used (interface method)
+			} else {
+				isUsed = usedNames[pName]
+			}
+
+			params = append(params, Parameter{
+				Name:     pName,
+				Type:     &typeStr,
+				IsUsed:   isUsed,
+				Position: pos,
+			})
+
+			if !isUsed && pName != "_" {
+				unused = append(unused, pName)
+			}
+			pos++
+		}
+	}
+
+	if unused == nil {
+		unused = []string{}
+	}
+
+	return params, unused
+}
+
+func extractEdges(f *ast.File, fset *token.FileSet, filePath, pkgName string, funcMap map[string]*Node, debug bool, stats *Stats) []Edge {
+	var edges []Edge
+
+	imported := make(map[string]bool, len(f.Imports))
+	for _, imp := range f.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+		if imp.Name != nil {
+			imported[imp.Name.Name] = true
+		} else {
+			imported[defaultImportName(path)] = true
+		}
+	}
+
+	for _, decl := range f.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+
+		name := funcDecl.Name.Name
+		var receiver string
+		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+			receiver = getReceiverTypeName(funcDecl.Recv.List[0].Type)
+		}
+
+		qualified := name
+		if receiver != "" {
+			qualified = receiver + "." + name
+		}
+		sourceID := filePath + ":" + qualified
+		weights := estimateCallWeights(funcDecl.Body)
+		explaining := stats.explain.watching(sourceID)
+
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			callExpr, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			targetName := getCallTargetName(callExpr)
+			if targetName == "" || goBuiltins[targetName] {
+				return true
+			}
+
+			kind := "direct"
+
+			var targetID, rule string
+			var params []Parameter
+
+			fullID := filePath + ":" + targetName
+			if node, exists := funcMap[fullID]; exists {
+				targetID, rule, params = node.ID, ruleSameFileName, node.Parameters
+			} else if node, exists := funcMap[targetName]; exists {
+				targetID, rule, params = node.ID, ruleProjectName, node.Parameters
+			}
+
+			if strings.Contains(targetName, ".") {
+				kind = "method"
+			}
+
+			if targetID == "" {
+				if qual, _, ok := strings.Cut(targetName, "."); ok && imported[qual] {
+					stats.ExternalCalls++
+				} else if _, isType := types.Universe.Lookup(targetName).(*types.TypeName); !isType {
+					stats.UnresolvedCalls++
+				}
+			}
+			if explaining {
+				how := "matches no project function by name (without type information it may be a method or a function value)"
+				switch qual, _, _ := strings.Cut(targetName, "."); {
+				case targetID == "" && imported[qual]:
+					how = "calls into the imported package " + qual
+				case rule == ruleSameFileName:
+					how = "matches " + targetID + " by name in the same file"
+				case rule == ruleProjectName:
+					how = "matches " + targetID + " by name elsewhere in the project"
+				}
+				stats.explain.record(fset.Position(callExpr.Pos()), []string{targetID}, "%s(...) %s", targetName, how)
+			}
+
+			if targetID != "" && targetID != sourceID {
+				pos := fset.Position(callExpr.Pos())
+				args := constantArgs(callExpr, fset, nil)
+				nameArgs(args, params)
+				edge := Edge{
+					Source: sourceID,
+					Target: targetID,
+					CallSite: CallSite{
+						FilePath: filePath,
+						Line:     pos.Line,
+						Column:   pos.Column,
+					},
+					Kind:         kind,
+					IsResolved:   true,
+					Weight:       weights[callExpr].weight,
+					CallContext:  weights[callExpr].context,
+					ConstantArgs: args,
+					Provenance:   newProvenance("calls", rule, callExpr, debug),
+				}
+				edge.addCallShape(callExpr, nil, params)
+				edges = append(edges, edge)
+			}
+
+			return true
+		})
+	}
+
+	return edges
+}
+
+// ===================================================================
+// Shared helpers
+// ===================================================================
+
+func getCallTargetName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		if ident, ok := fn.X.(*ast.Ident); ok {
+			return ident.Name + "." + fn.Sel.Name
+		}
+		return fn.Sel.Name
+	}
+	return ""
+}
+
+func getReceiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// linkerSymbol returns the name the Go linker (and therefore pprof and runtime
+// stack traces) uses for a function: "path.Func", "path.T.M" or "path.(*T).M",
+// with "main" in place of the import path for main packages.
+func linkerSymbol(pkgPath, pkgName, receiver string, ptrRecv bool, name string) string {
+	if pkgName == "main" || pkgPath == "" {
+		pkgPath = pkgName
+	}
+	switch {
+	case receiver == "":
+		return pkgPath + "." + name
+	case ptrRecv:
+		return pkgPath + ".(*" + receiver + ")." + name
+	default:
+		return pkgPath + "." + receiver + "." + name
+	}
+}
+
+// funcSymbol returns the linker symbol of a type-checked function.
+func funcSymbol(fn *types.Func) string {
+	var receiver string
+	ptrRecv := false
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t, ptrRecv = ptr.Elem(), true
+		}
+		if named, ok := t.(*types.Named); ok {
+			receiver = named.Obj().Name()
+		}
+	}
+	return linkerSymbol(fn.Pkg().Path(), fn.Pkg().Name(), receiver, ptrRecv, fn.Name())
+}
+
+func isPointerReceiver(funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return false
+	}
+	_, ok := funcDecl.Recv.List[0].Type.(*ast.StarExpr)
+	return ok
+}
+
+// resolvePath resolves p against the project root unless it is already absolute.
+func resolvePath(projectRoot, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(projectRoot, p)
+}
+
+func formatFieldType(field *ast.Field) string {
+	if field.Type == nil {
+		return ""
+	}
+	switch t := field.Type.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return "*" + ident.Name
+		}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok {
+			return "[]" + ident.Name
+		}
+	case *ast.MapType:
+		return "map"
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.SelectorExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			return ident.Name + "." + t.Sel.Name
+		}
+	}
+	return "unknown"
+}
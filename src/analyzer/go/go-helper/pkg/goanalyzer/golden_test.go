@@ -0,0 +1,189 @@
+package goanalyzer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+	"golang.org/x/mod/modfile"
+)
+
+// updateGolden rewrites the snapshots instead of comparing against them:
+//
+//	go test ./pkg/goanalyzer -run TestGolden -update-golden
+var updateGolden = flag.Bool("update-golden", false, "rewrite testdata/golden snapshots from current output")
+
+// fixturesDir holds the CodeGraph test projects shared with the TypeScript tests.
+const fixturesDir = "../../../../../../test/fixtures"
+
+// TestGolden analyzes every Go project under test/fixtures with each
+// algorithm and diffs the canonical output against
+// testdata/golden/<fixture>.<algorithm>.json, so analyzer behavior changes
+// show up as reviewable snapshot diffs.
+func TestGolden(t *testing.T) {
+	fixtures, err := os.ReadDir(fixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		root := filepath.Join(fixturesDir, fixture.Name())
+		goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if !fixture.IsDir() || err != nil {
+			continue
+		}
+		for _, algorithm := range []string{goanalyzer.AlgorithmTypes, goanalyzer.AlgorithmAST} {
+			t.Run(fixture.Name()+"/"+algorithm, func(t *testing.T) {
+				if algorithm == goanalyzer.AlgorithmTypes {
+					requireTypedAnalysis(t)
+				}
+				files, err := goanalyzer.DiscoverFiles(root, goanalyzer.DefaultExclude)
+				if err != nil {
+					t.Fatal(err)
+				}
+				graph, err := goanalyzer.Analyze(context.Background(), goanalyzer.Options{
+					Files:       files,
+					ProjectRoot: root,
+					Module:      modfile.ModulePath(goMod),
+					Algorithm:   algorithm,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if graph.Stats.Algorithm != algorithm {
+					t.Skipf("%s analysis unavailable in this environment", algorithm)
+				}
+				got := canonicalJSON(t, graph)
+
+				golden := filepath.Join("testdata", "golden", fixture.Name()+"."+algorithm+".json")
+				if *updateGolden {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("%v (run with -update-golden to create it)", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from %s (run with -update-golden to accept):\n%s", golden, lineDiff(string(want), string(got)))
+				}
+			})
+		}
+	}
+}
+
+// TestSchemas checks that the published JSON Schemas under schema/ match
+// the Options and Graph types (regenerate them with -update-golden), and
+// that the golden graphs conform to the output schema.
+func TestSchemas(t *testing.T) {
+	for name, schema := range map[string]*goanalyzer.Schema{
+		"options.schema.json": goanalyzer.InputSchema(),
+		"graph.schema.json":   goanalyzer.OutputSchema(),
+	} {
+		got, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, '\n')
+		file := filepath.Join("..", "..", "schema", name)
+		if *updateGolden {
+			if err := os.WriteFile(file, got, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("%v (run with -update-golden to create it)", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date (run with -update-golden to regenerate):\n%s", file, lineDiff(string(want), string(got)))
+		}
+	}
+
+	goldens, err := filepath.Glob(filepath.Join("testdata", "golden", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, golden := range goldens {
+		data, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if problems := goanalyzer.ValidateGraph(data); len(problems) > 0 {
+			t.Errorf("%s does not match graph.schema.json:\n%s", golden, strings.Join(problems, "\n"))
+		}
+	}
+}
+
+// canonicalJSON renders the graph in a stable form: nodes and edges sorted,
+// and run-dependent stats (timings, memory) cleared.
+func canonicalJSON(t *testing.T, graph goanalyzer.Graph) []byte {
+	t.Helper()
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.CallSite.Line != b.CallSite.Line {
+			return a.CallSite.Line < b.CallSite.Line
+		}
+		return a.CallSite.Column < b.CallSite.Column
+	})
+	if graph.Stats != nil {
+		graph.Stats.Phases = nil
+		graph.Stats.PeakMemoryBytes = 0
+	}
+	out, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(out, '\n')
+}
+
+// lineDiff lists the lines only in want (-) or only in got (+), in order.
+func lineDiff(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	inWant := make(map[string]int)
+	for _, l := range wantLines {
+		inWant[l]++
+	}
+	inGot := make(map[string]int)
+	for _, l := range gotLines {
+		inGot[l]++
+	}
+	var b strings.Builder
+	for _, l := range wantLines {
+		if inGot[l] > 0 {
+			inGot[l]--
+			continue
+		}
+		b.WriteString("- " + l + "\n")
+	}
+	for _, l := range gotLines {
+		if inWant[l] > 0 {
+			inWant[l]--
+			continue
+		}
+		b.WriteString("+ " + l + "\n")
+	}
+	return b.String()
+}
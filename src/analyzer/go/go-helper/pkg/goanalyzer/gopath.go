@@ -0,0 +1,101 @@
+package goanalyzer
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// withGOPATH detects a project in GOPATH layout: no go.mod or go.work in
+// ProjectRoot or above it, and the root under the src directory of a
+// GOPATH entry, or of any directory named src above it. Type-aware loads
+// then run with GO111MODULE=off and that GOPATH (see packagesEnv), and an
+// empty Module is set to the root's import path.
+func (o Options) withGOPATH() Options {
+	if o.driver != "" {
+		return o
+	}
+	absRoot, err := filepath.Abs(o.ProjectRoot)
+	if err != nil {
+		return o
+	}
+	for dir := absRoot; ; dir = filepath.Dir(dir) {
+		for _, name := range []string{"go.mod", "go.work"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return o
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	gopath, importPath := "", ""
+	for _, entry := range filepath.SplitList(build.Default.GOPATH) {
+		if rel, ok := underSrc(filepath.Join(entry, "src"), absRoot); ok {
+			gopath, importPath = build.Default.GOPATH, rel
+			break
+		}
+	}
+	if gopath == "" {
+		// A checkout with its own GOPATH, as CI jobs of legacy services
+		// lay them out: $WORKSPACE/src/github.com/org/service.
+		for dir := filepath.Dir(absRoot); filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+			if filepath.Base(dir) != "src" {
+				continue
+			}
+			if rel, ok := underSrc(dir, absRoot); ok {
+				gopath, importPath = filepath.Dir(dir), rel
+				if build.Default.GOPATH != "" {
+					gopath += string(filepath.ListSeparator) + build.Default.GOPATH
+				}
+				break
+			}
+		}
+	}
+	if gopath == "" {
+		return o
+	}
+	o.gopath = gopath
+	if o.Module == "" {
+		o.Module = importPath
+	}
+	return o
+}
+
+// underSrc returns the import path of dir below the GOPATH src directory.
+func underSrc(src, dir string) (string, bool) {
+	rel, err := filepath.Rel(src, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// gopathEnv returns env, or the current environment if nil, set up for
+// GOPATH mode: modules off, GOPATH set, and -mod flags, which the go command
+// rejects outside module mode, dropped from GOFLAGS. The variables are
+// replaced rather than appended, since go/packages reads the first GOFLAGS.
+func (o Options) gopathEnv(env []string) []string {
+	if env == nil {
+		env = os.Environ()
+	}
+	var flags []string
+	out := make([]string, 0, len(env)+3)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		switch name {
+		case "GOFLAGS":
+			for _, f := range strings.Fields(value) {
+				if !strings.HasPrefix(f, "-mod=") {
+					flags = append(flags, f)
+				}
+			}
+		case "GO111MODULE", "GOPATH":
+		default:
+			out = append(out, kv)
+		}
+	}
+	return append(out, "GO111MODULE=off", "GOPATH="+o.gopath, "GOFLAGS="+strings.Join(flags, " "))
+}
@@ -0,0 +1,59 @@
+package goanalyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// computeHashes sets Graph.GraphHash and Graph.PackageHashes. The hashes
+// cover the structure of the call graph: each node's identity, kind,
+// visibility, entry-point flag and parameters, and each edge's endpoints,
+// kind and resolution. Positions, weights and runtime or pass data are left
+// out, so moving code around or re-profiling does not change them. A
+// package's hash covers its nodes and the edges leaving them.
+func (g *Graph) computeHashes() {
+	lines := make(map[string][]string)
+	pkgOf := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		pkgOf[n.ID] = n.PackageOrModule
+		var params []string
+		for _, p := range n.Parameters {
+			t := ""
+			if p.Type != nil {
+				t = *p.Type
+			}
+			params = append(params, p.Name+" "+t)
+		}
+		lines[n.PackageOrModule] = append(lines[n.PackageOrModule], strings.Join([]string{
+			"node", n.ID, n.Kind, n.Visibility, strconv.FormatBool(n.IsEntryPoint), strings.Join(params, ", "),
+		}, "\t"))
+	}
+	for _, e := range g.Edges {
+		pkg := pkgOf[e.Source]
+		lines[pkg] = append(lines[pkg], strings.Join([]string{
+			"edge", e.Source, e.Target, e.Kind, strconv.FormatBool(e.IsResolved),
+		}, "\t"))
+	}
+
+	pkgs := make([]string, 0, len(lines))
+	for pkg := range lines {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	g.PackageHashes = make(map[string]string, len(pkgs))
+	all := sha256.New()
+	for _, pkg := range pkgs {
+		h := sha256.New()
+		for _, line := range sortedUnique(lines[pkg]) {
+			h.Write([]byte(line))
+			h.Write([]byte{'\n'})
+		}
+		sum := hex.EncodeToString(h.Sum(nil))
+		g.PackageHashes[pkg] = sum
+		all.Write([]byte(pkg + "\t" + sum + "\n"))
+	}
+	g.GraphHash = hex.EncodeToString(all.Sum(nil))
+}
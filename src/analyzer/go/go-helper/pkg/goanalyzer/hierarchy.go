@@ -0,0 +1,90 @@
+package goanalyzer
+
+import "sort"
+
+// Container is a level of the module → package → file hierarchy
+// (Options.Hierarchy), with metrics rolled up from the functions below it,
+// for collapsible and treemap views.
+type Container struct {
+	// Kind is "module", "package" or "file".
+	Kind string `json:"kind"`
+	// Name is the module path, the package directory (as PackageOrModule)
+	// or the file path.
+	Name        string `json:"name"`
+	Functions   int    `json:"functions"`
+	LinesOfCode int    `json:"linesOfCode"`
+	Entry       int    `json:"entry"`
+	Live        int    `json:"live"`
+	Dead        int    `json:"dead"`
+	TestOnly    int    `json:"testOnly,omitempty"`
+	// Children are the packages of a module or the files of a package,
+	// sorted by name.
+	Children []*Container `json:"children,omitempty"`
+	// Nodes are the IDs of the functions directly in the container: those
+	// of a file, or of a package for synthetic nodes without a file.
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// buildHierarchy groups g's nodes under a module container named module.
+func buildHierarchy(g *Graph, module string) *Container {
+	if module == "" {
+		module = "."
+	}
+	root := &Container{Kind: "module", Name: module}
+	pkgs := make(map[string]*Container)
+	files := make(map[string]*Container)
+	for _, n := range g.Nodes {
+		pkg := pkgs[n.PackageOrModule]
+		if pkg == nil {
+			pkg = &Container{Kind: "package", Name: n.PackageOrModule}
+			pkgs[n.PackageOrModule] = pkg
+			root.Children = append(root.Children, pkg)
+		}
+		leaf := pkg
+		if n.FilePath != "" {
+			leaf = files[n.FilePath]
+			if leaf == nil {
+				leaf = &Container{Kind: "file", Name: n.FilePath}
+				files[n.FilePath] = leaf
+				pkg.Children = append(pkg.Children, leaf)
+			}
+		}
+		leaf.Nodes = append(leaf.Nodes, n.ID)
+		root.count(n)
+		pkg.count(n)
+		if leaf != pkg {
+			leaf.count(n)
+		}
+	}
+	sortContainers(root.Children)
+	for _, pkg := range root.Children {
+		sortContainers(pkg.Children)
+	}
+	return root
+}
+
+func (c *Container) count(n Node) {
+	c.Functions++
+	c.LinesOfCode += n.LinesOfCode
+	switch n.Status {
+	case "entry":
+		c.Entry++
+	case "live":
+		c.Live++
+	case "dead":
+		c.Dead++
+	case "test-only":
+		c.TestOnly++
+	}
+}
+
+func sortContainers(list []*Container) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+}
+
+// refreshHierarchy rebuilds the hierarchy if the graph has one.
+func (g *Graph) refreshHierarchy() {
+	if g.Hierarchy != nil {
+		g.Hierarchy = buildHierarchy(g, g.Hierarchy.Name)
+	}
+}
@@ -0,0 +1,149 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// HTTPCall is an outbound HTTP request made by a function.
+type HTTPCall struct {
+	Method string `json:"method,omitempty"`
+	// URL is the constant URL or its constant prefix ("https://api/users/"
+	// for "https://api/users/" + id); empty when fully dynamic.
+	URL  string `json:"url,omitempty"`
+	Line int    `json:"line"`
+}
+
+// httpRequestFuncs are net/http functions and *http.Client methods that send
+// or build a request, with the argument positions of the method and URL
+// (method -1 means it is implied by the function name).
+var httpRequestFuncs = map[string]struct {
+	method    string
+	methodArg int
+	urlArg    int
+}{
+	"Get":                   {"GET", -1, 0},
+	"Head":                  {"HEAD", -1, 0},
+	"Post":                  {"POST", -1, 0},
+	"PostForm":              {"POST", -1, 0},
+	"NewRequest":            {"", 0, 1},
+	"NewRequestWithContext": {"", 1, 2},
+}
+
+// collectHTTPCalls returns the outbound HTTP requests built or sent in body.
+// A request built with http.NewRequest and sent with client.Do is recorded
+// once, at NewRequest, where its method and URL are known.
+func collectHTTPCalls(body *ast.BlockStmt, ctx *passContext) []HTTPCall {
+	var calls []HTTPCall
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		target, ok := ctx.resolveCall(call)
+		if !ok {
+			return true
+		}
+		spec, ok := httpRequestFuncs[target.name]
+		if !ok || !isHTTPClientCall(target, ctx.info != nil) {
+			return true
+		}
+
+		hc := HTTPCall{Method: spec.method, Line: ctx.fset.Position(call.Pos()).Line}
+		if spec.methodArg >= 0 && spec.methodArg < len(call.Args) {
+			hc.Method = httpMethodOf(call.Args[spec.methodArg], ctx)
+		}
+		if spec.urlArg < len(call.Args) {
+			hc.URL = ctx.constPrefix(call.Args[spec.urlArg], false)
+		}
+		calls = append(calls, hc)
+		return true
+	})
+	return calls
+}
+
+// isHTTPClientCall reports whether target is a net/http package function or
+// an http.Client method. Without type information, method calls are accepted
+// when the receiver is named like a client.
+func isHTTPClientCall(target callTarget, typed bool) bool {
+	if target.pkgPath != "" {
+		return target.pkgPath == "net/http" && (target.recv == "" || target.recv == "Client")
+	}
+	if typed || target.recvExpr == nil || strings.HasPrefix(target.name, "NewRequest") {
+		return false
+	}
+	return strings.Contains(strings.ToLower(target.recvText()), "client")
+}
+
+// httpMethodOf resolves a method argument: a constant ("GET", http.MethodGet)
+// or, without type information, an http.MethodXxx selector by name.
+func httpMethodOf(e ast.Expr, ctx *passContext) string {
+	if s, ok := ctx.constString(e); ok {
+		return strings.ToUpper(s)
+	}
+	if sel, ok := ast.Unparen(e).(*ast.SelectorExpr); ok && strings.HasPrefix(sel.Sel.Name, "Method") {
+		return strings.ToUpper(strings.TrimPrefix(sel.Sel.Name, "Method"))
+	}
+	return ""
+}
+
+// addExternalHTTPEdges creates one synthetic node per distinct outbound
+// endpoint (method + URL) and an "external_http" edge from every function
+// that calls it. Calls with a dynamic URL get no edge.
+func addExternalHTTPEdges(output *Graph) {
+	endpoints := make(map[string]bool)
+	var newNodes []Node
+	for _, n := range output.Nodes {
+		if n.Metadata == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, hc := range n.Metadata.HTTPCalls {
+			if hc.URL == "" {
+				continue
+			}
+			name := strings.TrimSpace(hc.Method + " " + hc.URL)
+			id := "external:http:" + name
+			if !endpoints[id] {
+				endpoints[id] = true
+				newNodes = append(newNodes, externalNode(id, name, "http"))
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			output.Edges = append(output.Edges, Edge{
+				Source: n.ID,
+				Target: id,
+				CallSite: CallSite{
+					FilePath: n.FilePath,
+					Line:     hc.Line,
+				},
+				Kind:       "external_http",
+				IsResolved: true,
+				Provenance: Provenance{Phase: "postprocess", Rule: ruleExternalEndpoint},
+			})
+		}
+	}
+	sort.Slice(newNodes, func(i, j int) bool { return newNodes[i].ID < newNodes[j].ID })
+	output.Nodes = append(output.Nodes, newNodes...)
+}
+
+// externalNode builds a synthetic node standing for something outside the
+// project (an HTTP endpoint, a database, ...). It has no file or position.
+func externalNode(id, name, pkg string) Node {
+	return Node{
+		ID:               id,
+		Name:             name,
+		QualifiedName:    id,
+		Language:         "go",
+		Kind:             "external",
+		Visibility:       "exported",
+		Parameters:       []Parameter{},
+		UnusedParameters: []string{},
+		PackageOrModule:  pkg,
+		Status:           "dead",
+		Color:            "red",
+	}
+}
@@ -0,0 +1,131 @@
+package goanalyzer
+
+import (
+	"go/types"
+	"runtime"
+	"sync"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// minImplementsShard is the fewest concrete types worth a goroutine of
+// their own in implementers.of.
+const minImplementsShard = 64
+
+// implementers records which concrete project types implement each
+// interface, as the constructors and calls phases ask the same question of
+// the same interfaces over and over. Interfaces are keyed by identity
+// (types.Identical), so that the same anonymous interface met twice is
+// checked once.
+type implementers struct {
+	concrete []*types.Named
+	byIface  typeutil.Map // *types.Interface → []*types.Named
+	// prepared are the types whose lazily computed state has been filled
+	// in (see prepare).
+	prepared map[types.Type]bool
+}
+
+func newImplementers(concrete []*types.Named) *implementers {
+	m := &implementers{concrete: concrete, prepared: make(map[types.Type]bool)}
+	for _, ct := range concrete {
+		m.prepare(ct)
+	}
+	return m
+}
+
+// prepare fills in the state go/types computes on first use and that
+// types.Implements reads: the type set of every interface reachable from
+// t, through embedding, fields and signatures, and the method sets of the
+// named types, with and without a pointer. Done once, in one goroutine,
+// it leaves the concurrent checks of of nothing to write.
+func (m *implementers) prepare(t types.Type) {
+	if t == nil || m.prepared[t] {
+		return
+	}
+	m.prepared[t] = true
+	switch t := t.(type) {
+	case *types.Alias:
+		m.prepare(types.Unalias(t))
+	case *types.Named:
+		types.NewMethodSet(t)
+		types.NewMethodSet(types.NewPointer(t))
+		m.prepare(t.Underlying())
+		for i := range t.NumMethods() {
+			m.prepare(t.Method(i).Type())
+		}
+	case *types.Interface:
+		t.Complete()
+		for i := range t.NumEmbeddeds() {
+			m.prepare(t.EmbeddedType(i))
+		}
+		for i := range t.NumMethods() {
+			m.prepare(t.Method(i).Type())
+		}
+	case *types.Struct:
+		for i := range t.NumFields() {
+			m.prepare(t.Field(i).Type())
+		}
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := range tuple.Len() {
+				m.prepare(tuple.At(i).Type())
+			}
+		}
+	case *types.Pointer:
+		m.prepare(t.Elem())
+	case *types.Slice:
+		m.prepare(t.Elem())
+	case *types.Array:
+		m.prepare(t.Elem())
+	case *types.Chan:
+		m.prepare(t.Elem())
+	case *types.Map:
+		m.prepare(t.Key())
+		m.prepare(t.Elem())
+	case *types.TypeParam:
+		m.prepare(t.Constraint())
+	}
+}
+
+// of returns the concrete types that implement iface, directly or through
+// a pointer.
+func (m *implementers) of(iface *types.Interface) []*types.Named {
+	if impls, ok := m.byIface.At(iface).([]*types.Named); ok {
+		return impls
+	}
+	m.prepare(iface)
+	// With the types prepared, types.Implements only reads them, so the
+	// concrete types are checked in shards, one goroutine each, into
+	// disjoint parts of ok.
+	ok := make([]bool, len(m.concrete))
+	check := func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			ct := m.concrete[i]
+			ok[i] = types.Implements(ct, iface) || types.Implements(types.NewPointer(ct), iface)
+		}
+	}
+	shards := min(runtime.GOMAXPROCS(0), len(m.concrete)/minImplementsShard)
+	if shards <= 1 {
+		check(0, len(m.concrete))
+	} else {
+		var wg sync.WaitGroup
+		size := (len(m.concrete) + shards - 1) / shards
+		for lo := 0; lo < len(m.concrete); lo += size {
+			wg.Add(1)
+			go func(lo, hi int) {
+				defer wg.Done()
+				check(lo, hi)
+			}(lo, min(lo+size, len(m.concrete)))
+		}
+		wg.Wait()
+	}
+
+	impls := []*types.Named{}
+	for i, ct := range m.concrete {
+		if ok[i] {
+			impls = append(impls, ct)
+		}
+	}
+	m.byIface.Set(iface, impls)
+	return impls
+}
@@ -0,0 +1,201 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// InitGraph is the initialization order of the project's packages, as the
+// Go specification defines it, with the dependencies that determine it.
+type InitGraph struct {
+	// Steps run in order: the packages one after another, imports first,
+	// each with its package-level variables and then its init functions.
+	Steps []InitStep `json:"steps"`
+}
+
+// InitStep initializes package-level variables or runs an init function.
+type InitStep struct {
+	// ID is "importpath.name" of the first variable initialized, or the
+	// node ID of the init function.
+	ID      string `json:"id"`
+	Kind    string `json:"kind"` // "var" or "init"
+	Package string `json:"package"`
+	// Vars are the variables an initializer sets, several for
+	// "var a, b = f()".
+	Vars     []string `json:"vars,omitempty"`
+	FilePath string   `json:"filePath"`
+	Line     int      `json:"line"`
+	// DependsOn are the steps initializing the variables the step reads,
+	// directly or through the functions it references, in this package or
+	// an imported one.
+	DependsOn []string `json:"dependsOn"`
+}
+
+// buildInitGraph orders the initialization of pkgs. Packages are taken in
+// import path order, each as soon as the project packages it imports are
+// initialized; within a package, variables follow types.Info.InitOrder and
+// init functions follow their files, in the order given to the compiler.
+func buildInitGraph(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string) *InitGraph {
+	byPath := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo != nil {
+			byPath[pkg.PkgPath] = pkg
+		}
+	}
+	order := initPackageOrder(byPath)
+
+	// The step initializing each package-level variable, and the
+	// package-level variables and functions each function references.
+	stepOf := make(map[*types.Var]string)
+	funcRefs := make(map[*types.Func][]types.Object)
+	for _, pkg := range order {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if fd, ok := decl.(*ast.FuncDecl); ok && fd.Body != nil {
+					if fn, ok := pkg.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+						funcRefs[fn] = packageLevelRefs(fd.Body, pkg.TypesInfo)
+					}
+				}
+			}
+		}
+	}
+	var varsReachedBy func(refs []types.Object, seen map[*types.Func]bool, into map[*types.Var]bool)
+	varsReachedBy = func(refs []types.Object, seen map[*types.Func]bool, into map[*types.Var]bool) {
+		for _, obj := range refs {
+			switch obj := obj.(type) {
+			case *types.Var:
+				into[obj] = true
+			case *types.Func:
+				if seen[obj] {
+					continue
+				}
+				seen[obj] = true
+				varsReachedBy(funcRefs[obj], seen, into)
+			}
+		}
+	}
+	dependsOn := func(id string, refs []types.Object) []string {
+		vars := make(map[*types.Var]bool)
+		varsReachedBy(refs, make(map[*types.Func]bool), vars)
+		deps := []string{}
+		for v := range vars {
+			if step, ok := stepOf[v]; ok && step != id && !contains(deps, step) {
+				deps = append(deps, step)
+			}
+		}
+		sort.Strings(deps)
+		return deps
+	}
+
+	g := &InitGraph{Steps: []InitStep{}}
+	for _, pkg := range order {
+		for _, init := range pkg.TypesInfo.InitOrder {
+			if len(init.Lhs) == 0 {
+				continue
+			}
+			id := pkg.PkgPath + "." + init.Lhs[0].Name()
+			step := InitStep{ID: id, Kind: "var", Package: pkg.PkgPath}
+			for _, v := range init.Lhs {
+				stepOf[v] = id
+				step.Vars = append(step.Vars, v.Name())
+			}
+			step.FilePath, step.Line = initPosition(pkg, paths, init.Rhs)
+			step.DependsOn = dependsOn(id, packageLevelRefs(init.Rhs, pkg.TypesInfo))
+			g.Steps = append(g.Steps, step)
+		}
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Recv != nil || fd.Name.Name != "init" || fd.Body == nil {
+					continue
+				}
+				filePath, line := initPosition(pkg, paths, fd)
+				id := objToNodeID[pkg.TypesInfo.Defs[fd.Name]]
+				if id == "" {
+					id = filePath + ":init"
+				}
+				step := InitStep{ID: id, Kind: "init", Package: pkg.PkgPath, FilePath: filePath, Line: line}
+				step.DependsOn = dependsOn(id, packageLevelRefs(fd.Body, pkg.TypesInfo))
+				g.Steps = append(g.Steps, step)
+			}
+		}
+	}
+	return g
+}
+
+// initPackageOrder sorts the packages by import path and then repeatedly
+// takes the first one whose imported project packages are all taken, as
+// the Go specification orders package initialization.
+func initPackageOrder(byPath map[string]*packages.Package) []*packages.Package {
+	var pending []*packages.Package
+	for _, pkg := range byPath {
+		pending = append(pending, pkg)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].PkgPath < pending[j].PkgPath })
+	done := make(map[string]bool, len(pending))
+	var order []*packages.Package
+	for len(pending) > 0 {
+		next := 0 // an import cycle, which the compiler rejects, falls back to the first
+		for i, pkg := range pending {
+			ready := true
+			for path := range pkg.Imports {
+				if byPath[path] != nil && !done[path] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		done[pending[next].PkgPath] = true
+		order = append(order, pending[next])
+		pending = append(pending[:next], pending[next+1:]...)
+	}
+	return order
+}
+
+// packageLevelRefs returns the package-level variables and the functions
+// and methods used in n, in order of first use.
+func packageLevelRefs(n ast.Node, info *types.Info) []types.Object {
+	var refs []types.Object
+	seen := make(map[types.Object]bool)
+	ast.Inspect(n, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := info.Uses[ident]
+		switch o := obj.(type) {
+		case *types.Var:
+			if o.Pkg() == nil || o.Parent() != o.Pkg().Scope() || seen[o] {
+				return true
+			}
+		case *types.Func:
+			if seen[o] {
+				return true
+			}
+			obj = o.Origin()
+		default:
+			return true
+		}
+		seen[obj] = true
+		refs = append(refs, obj)
+		return true
+	})
+	return refs
+}
+
+func initPosition(pkg *packages.Package, paths *sourcePaths, n ast.Node) (string, int) {
+	pos := pkg.Fset.Position(n.Pos())
+	rel, err := paths.rel(pos.Filename)
+	if err != nil {
+		return "", pos.Line
+	}
+	return filepath.ToSlash(rel), pos.Line
+}
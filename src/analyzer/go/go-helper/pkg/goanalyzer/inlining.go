@@ -0,0 +1,85 @@
+package goanalyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Inlining is whether the compiler inlines a function, which then does not
+// appear as its own frame in profiles.
+type Inlining struct {
+	Inlinable bool `json:"inlinable"`
+	// Cost is the compiler's estimate, inlinable up to a budget of 80.
+	Cost int `json:"cost,omitempty"`
+	// Reason says why the function cannot be inlined, as the compiler
+	// puts it ("function too complex: cost 246 exceeds budget 80").
+	Reason string `json:"reason,omitempty"`
+	// Source is "compiler", or "heuristic" when the project could not be
+	// built and inlinability was guessed from the function's size.
+	Source string `json:"source"`
+}
+
+var (
+	canInline    = regexp.MustCompile(`^can inline (\S+)(?: with cost (\d+))?`)
+	cannotInline = regexp.MustCompile(`^cannot inline (\S+): (.*)$`)
+	inlineCost   = regexp.MustCompile(`\bcost (\d+)`)
+)
+
+// inlineHeuristicSloc is the largest function, in lines of code, the
+// heuristic takes for inlinable: a few simple statements fit the budget.
+const inlineHeuristicSloc = 4
+
+// annotateInlining sets Node.Inlining from the compiler's decisions in
+// diags, the report of go build -gcflags=-m=2. When the build failed with
+// err, functions are guessed inlinable from their size alone.
+func annotateInlining(g *Graph, diags []compilerDiagnostic, err error, input Options) {
+	type key struct {
+		file string
+		line int
+	}
+	nodes := make(map[key][]*Node)
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if (n.Kind == "function" || n.Kind == "method") && !n.Dependency && n.FilePath != "" {
+			k := key{n.FilePath, n.StartLine}
+			nodes[k] = append(nodes[k], n)
+		}
+	}
+
+	if err != nil {
+		input.warnf("inlining estimated from function size: %v", err)
+		for _, list := range nodes {
+			for _, n := range list {
+				n.Inlining = &Inlining{Inlinable: n.Sloc <= inlineHeuristicSloc, Source: "heuristic"}
+			}
+		}
+		return
+	}
+	for _, d := range diags {
+		var inl Inlining
+		var name string
+		if m := canInline.FindStringSubmatch(d.Message); m != nil {
+			name = m[1]
+			inl = Inlining{Inlinable: true, Source: "compiler"}
+			inl.Cost, _ = strconv.Atoi(m[2])
+		} else if m := cannotInline.FindStringSubmatch(d.Message); m != nil {
+			name = m[1]
+			inl = Inlining{Reason: m[2], Source: "compiler"}
+			if c := inlineCost.FindStringSubmatch(m[2]); c != nil {
+				inl.Cost, _ = strconv.Atoi(c[1])
+			}
+		} else {
+			continue
+		}
+		// The compiler names methods "T.M" or "(*T).M"; closures, named
+		// "f.func1", share their line with no node.
+		short := name[strings.LastIndex(name, ".")+1:]
+		for _, n := range nodes[key{d.FilePath, d.Line}] {
+			if n.Name == short && n.Inlining == nil {
+				copied := inl
+				n.Inlining = &copied
+			}
+		}
+	}
+}
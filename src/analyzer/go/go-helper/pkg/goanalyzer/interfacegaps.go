@@ -0,0 +1,146 @@
+package goanalyzer
+
+import (
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// maxInterfaceGap is the most methods a type may lack, or have with the
+// wrong signature, to be reported as a near-miss of an interface.
+const maxInterfaceGap = 2
+
+// InterfaceGaps reports the interfaces of the project that its types almost
+// implement, and those none of them implement.
+type InterfaceGaps struct {
+	NearMisses    []NearMiss               `json:"nearMisses"`
+	Unimplemented []UnimplementedInterface `json:"unimplemented"`
+}
+
+// NearMiss is a project type that implements a project interface but for
+// one or two methods. Types and interfaces are named "importpath.Name".
+type NearMiss struct {
+	Type      string `json:"type"`
+	Interface string `json:"interface"`
+	// Missing are the interface's methods the type (or a pointer to it)
+	// does not have.
+	Missing []string `json:"missing,omitempty"`
+	// Mismatched are the methods the type has with a different signature.
+	Mismatched []string `json:"mismatched,omitempty"`
+	FilePath   string   `json:"filePath"`
+	Line       int      `json:"line"`
+}
+
+// UnimplementedInterface is a project interface that no concrete project
+// type implements.
+type UnimplementedInterface struct {
+	Interface string `json:"interface"`
+	Methods   int    `json:"methods"`
+	FilePath  string `json:"filePath"`
+	Line      int    `json:"line"`
+}
+
+// findInterfaceGaps compares the interfaces declared in pkgs with the
+// concrete types implementedBy knows. Empty interfaces, constraints and
+// generic types are left out, and a type must have at least one of an
+// interface's methods to be a near-miss of it.
+func findInterfaceGaps(pkgs []*packages.Package, paths *sourcePaths, implementedBy *implementers) *InterfaceGaps {
+	type iface struct {
+		named *types.Named
+		iface *types.Interface
+	}
+	var ifaces []iface
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			it, ok := named.Underlying().(*types.Interface)
+			if !ok || !it.IsMethodSet() || it.NumMethods() == 0 {
+				continue
+			}
+			ifaces = append(ifaces, iface{named, it})
+		}
+	}
+
+	position := func(obj types.Object) (string, int) {
+		pos := pkgs[0].Fset.Position(obj.Pos())
+		rel, err := paths.rel(pos.Filename)
+		if err != nil {
+			return "", pos.Line
+		}
+		return filepath.ToSlash(rel), pos.Line
+	}
+
+	gaps := &InterfaceGaps{NearMisses: []NearMiss{}, Unimplemented: []UnimplementedInterface{}}
+	for _, in := range ifaces {
+		if len(implementedBy.of(in.iface)) == 0 {
+			file, line := position(in.named.Obj())
+			gaps.Unimplemented = append(gaps.Unimplemented, UnimplementedInterface{
+				Interface: typeName(in.named),
+				Methods:   in.iface.NumMethods(),
+				FilePath:  file,
+				Line:      line,
+			})
+		}
+	}
+	for _, ct := range implementedBy.concrete {
+		if ct.TypeParams().Len() > 0 {
+			continue
+		}
+		mset := types.NewMethodSet(types.NewPointer(ct))
+		for _, in := range ifaces {
+			if in.iface.NumMethods() <= 1 {
+				continue // nothing to nearly implement
+			}
+			var missing, mismatched []string
+			for i := range in.iface.NumMethods() {
+				m := in.iface.Method(i)
+				sel := mset.Lookup(m.Pkg(), m.Name())
+				switch {
+				case sel == nil:
+					missing = append(missing, m.Name())
+				case !types.Identical(sel.Obj().Type(), m.Type()):
+					mismatched = append(mismatched, m.Name())
+				}
+			}
+			gap := len(missing) + len(mismatched)
+			if gap == 0 || gap > maxInterfaceGap || gap == in.iface.NumMethods() {
+				continue
+			}
+			file, line := position(ct.Obj())
+			gaps.NearMisses = append(gaps.NearMisses, NearMiss{
+				Type:       typeName(ct),
+				Interface:  typeName(in.named),
+				Missing:    missing,
+				Mismatched: mismatched,
+				FilePath:   file,
+				Line:       line,
+			})
+		}
+	}
+	sort.Slice(gaps.NearMisses, func(i, j int) bool {
+		a, b := gaps.NearMisses[i], gaps.NearMisses[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Interface < b.Interface
+	})
+	sort.Slice(gaps.Unimplemented, func(i, j int) bool {
+		return gaps.Unimplemented[i].Interface < gaps.Unimplemented[j].Interface
+	})
+	return gaps
+}
+
+func typeName(named *types.Named) string {
+	obj := named.Obj()
+	return obj.Pkg().Path() + "." + obj.Name()
+}
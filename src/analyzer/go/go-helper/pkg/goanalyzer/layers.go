@@ -0,0 +1,173 @@
+package goanalyzer
+
+import (
+	"path"
+	"strings"
+)
+
+// Architectural layers guessed by the "layers" pass, from the outside in.
+const (
+	layerEntry      = "entry"
+	layerHandler    = "handler"
+	layerService    = "service"
+	layerRepository = "repository"
+	layerUtil       = "util"
+)
+
+// layerWords maps naming conventions to layers. They are matched against the
+// words of a function's package directory, file name and receiver type.
+var layerWords = []struct {
+	layer string
+	words []string
+}{
+	{layerHandler, []string{"handler", "handlers", "controller", "controllers", "api", "http", "rest", "rpc", "grpc", "server", "router", "routes", "endpoint", "endpoints", "transport", "web"}},
+	{layerRepository, []string{"repository", "repositories", "repo", "repos", "store", "storage", "dao", "db", "database", "persistence", "sql", "postgres", "mysql", "mongo", "redis", "cache"}},
+	{layerService, []string{"service", "services", "svc", "usecase", "usecases", "domain", "core", "logic", "business", "app", "manager"}},
+	{layerUtil, []string{"util", "utils", "helper", "helpers", "common", "shared", "lib"}},
+}
+
+// handlerParamTypes are parameter types of HTTP and RPC handler signatures.
+var handlerParamTypes = []string{
+	"http.ResponseWriter", "*http.Request", "*gin.Context", "echo.Context",
+	"*fiber.Ctx", "*chi.Context", "*fasthttp.RequestCtx",
+}
+
+// inferLayers records a layer guess in the metadata of every project
+// function: entry points first, then naming conventions, then handler
+// signatures, and finally graph topology (functions called from handlers are
+// services; leaves shared by several packages are utilities). Functions
+// matching none of these are left without a layer.
+func inferLayers(g *Graph) {
+	layers := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.FilePath == "" {
+			continue // synthetic external nodes
+		}
+		if layer := layerByConvention(n); layer != "" {
+			layers[n.ID] = layer
+		}
+	}
+
+	callers := make(map[string][]string)
+	hasCallees := make(map[string]bool)
+	for _, e := range g.Edges {
+		callers[e.Target] = append(callers[e.Target], e.Source)
+		hasCallees[e.Source] = true
+	}
+	pkgOf := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		pkgOf[n.ID] = n.PackageOrModule
+	}
+	for _, n := range g.Nodes {
+		if n.FilePath == "" || layers[n.ID] != "" {
+			continue
+		}
+		callerPkgs := make(map[string]bool)
+		calledByHandler := false
+		for _, c := range callers[n.ID] {
+			callerPkgs[pkgOf[c]] = true
+			if l := layers[c]; l == layerHandler || l == layerEntry {
+				calledByHandler = true
+			}
+		}
+		switch {
+		case !hasCallees[n.ID] && len(callerPkgs) >= 2:
+			layers[n.ID] = layerUtil
+		case calledByHandler:
+			layers[n.ID] = layerService
+		}
+	}
+
+	for i := range g.Nodes {
+		layer := layers[g.Nodes[i].ID]
+		if layer == "" {
+			continue
+		}
+		if g.Nodes[i].Metadata == nil {
+			g.Nodes[i].Metadata = &NodeMetadata{}
+		}
+		g.Nodes[i].Metadata.Layer = layer
+	}
+}
+
+// layerByConvention guesses a layer from what the node is and what it is
+// called, or returns "".
+func layerByConvention(n Node) string {
+	if n.IsEntryPoint || n.Kind == "init" {
+		return layerEntry
+	}
+	words := nameWords(n)
+	for _, lw := range layerWords {
+		for _, w := range lw.words {
+			if words[w] {
+				return lw.layer
+			}
+		}
+	}
+	for _, p := range n.Parameters {
+		if p.Type == nil {
+			continue
+		}
+		for _, t := range handlerParamTypes {
+			if *p.Type == t {
+				return layerHandler
+			}
+		}
+	}
+	return ""
+}
+
+// nameWords splits a node's directory segments, file name and receiver type
+// into lower-case words: "internal/userrepo/pg_store.go:UserStore.Get" yields
+// internal, userrepo, pg, store, user.
+func nameWords(n Node) map[string]bool {
+	words := make(map[string]bool)
+	add := func(s string) {
+		for _, w := range splitWords(s) {
+			words[w] = true
+		}
+	}
+	dir, file := path.Split(n.FilePath)
+	for _, seg := range strings.Split(strings.Trim(dir, "/"), "/") {
+		add(seg)
+	}
+	add(strings.TrimSuffix(file, ".go"))
+	if n.Kind == "method" {
+		_, qualified, _ := strings.Cut(n.QualifiedName, ":")
+		if recv, _, ok := strings.Cut(qualified, "."); ok {
+			add(recv)
+		}
+	}
+	return words
+}
+
+// splitWords splits on non-letters and camel-case boundaries, lower-casing.
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		isUpper := r >= 'A' && r <= 'Z'
+		isLetter := isUpper || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+		switch {
+		case !isLetter:
+			flush()
+			continue
+		case isUpper && i > 0 && len(cur) > 0:
+			prevLower := runes[i-1] >= 'a' && runes[i-1] <= 'z'
+			nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+			if prevLower || nextLower {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,88 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// countLines splits the lines of funcDecl into code (lines holding one of
+// its tokens), comment-only and blank lines. Tokens are located by the
+// positions of the syntax tree nodes, which start or end on every line that
+// holds code; comments comes from the declaring file.
+func countLines(fset *token.FileSet, funcDecl *ast.FuncDecl, comments []*ast.CommentGroup) (sloc, commentLines, blankLines int) {
+	first, last := fset.Position(funcDecl.Pos()).Line, fset.Position(funcDecl.End()).Line
+	code := make(map[int]bool)
+	mark := func(from, to token.Pos) {
+		for l := fset.Position(from).Line; l <= fset.Position(to).Line; l++ {
+			code[l] = true
+		}
+	}
+	ast.Inspect(funcDecl, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case nil:
+			return false
+		case *ast.CommentGroup:
+			return false
+		case *ast.BasicLit:
+			// Raw strings span lines.
+			mark(n.Pos(), n.End()-1)
+			return false
+		}
+		if n.Pos().IsValid() {
+			code[fset.Position(n.Pos()).Line] = true
+		}
+		if n.End().IsValid() {
+			code[fset.Position(n.End()-1).Line] = true
+		}
+		return true
+	})
+
+	comment := make(map[int]bool)
+	for _, group := range comments {
+		if group.End() < funcDecl.Pos() || group.Pos() > funcDecl.End() {
+			continue
+		}
+		for _, c := range group.List {
+			for l := fset.Position(c.Pos()).Line; l <= fset.Position(c.End()).Line; l++ {
+				comment[l] = true
+			}
+		}
+	}
+
+	for l := first; l <= last; l++ {
+		switch {
+		case code[l]:
+			sloc++
+		case comment[l]:
+			commentLines++
+		default:
+			blankLines++
+		}
+	}
+	return sloc, commentLines, blankLines
+}
+
+// countSourceLines classifies source lines by their text, for functions in
+// files with syntax errors whose syntax tree is incomplete.
+func countSourceLines(lines []string) (sloc, commentLines, blankLines int) {
+	inBlock := false
+	for _, line := range lines {
+		text := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			commentLines++
+			inBlock = !strings.Contains(text, "*/")
+		case text == "":
+			blankLines++
+		case strings.HasPrefix(text, "//"):
+			commentLines++
+		case strings.HasPrefix(text, "/*"):
+			commentLines++
+			inBlock = !strings.Contains(text, "*/")
+		default:
+			sloc++
+		}
+	}
+	return sloc, commentLines, blankLines
+}
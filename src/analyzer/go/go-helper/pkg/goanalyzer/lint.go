@@ -0,0 +1,173 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintIssue is a finding of go vet or staticcheck about a function.
+type LintIssue struct {
+	// Tool is "vet" or "staticcheck".
+	Tool string `json:"tool"`
+	// Check is the vet analyzer ("printf") or staticcheck code ("SA4006").
+	Check string `json:"check"`
+	// Severity is staticcheck's ("error", "warning"), or "warning" for vet.
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// vetPosition splits the "posn" of go vet -json: "/abs/file.go:10:2".
+var vetPosition = regexp.MustCompile(`^(.*):(\d+):(\d+)$`)
+
+// readLintReport reads the JSON output of go vet -json or staticcheck
+// -f json. Vet prints one object per package, mapping analyzers to
+// diagnostics, after a "# package" comment line; staticcheck prints one
+// object per diagnostic. Issues are returned with the file they are in.
+func readLintReport(r io.Reader) (map[string][]LintIssue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			lines = append(lines, line)
+		}
+	}
+	issues := make(map[string][]LintIssue)
+	dec := json.NewDecoder(bytes.NewReader(bytes.Join(lines, []byte("\n"))))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return issues, nil
+		} else if err != nil {
+			return nil, err
+		}
+		var sc struct {
+			Code     string `json:"code"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+			Location struct {
+				File   string `json:"file"`
+				Line   int    `json:"line"`
+				Column int    `json:"column"`
+			} `json:"location"`
+		}
+		if err := json.Unmarshal(raw, &sc); err == nil && sc.Code != "" && sc.Location.File != "" {
+			if sc.Severity == "ignored" {
+				continue
+			}
+			issues[sc.Location.File] = append(issues[sc.Location.File], LintIssue{
+				Tool: "staticcheck", Check: sc.Code, Severity: sc.Severity, Message: sc.Message,
+				Line: sc.Location.Line, Column: sc.Location.Column,
+			})
+			continue
+		}
+		var vet map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &vet); err != nil {
+			return nil, fmt.Errorf("neither go vet nor staticcheck output: %w", err)
+		}
+		for _, analyzers := range vet {
+			for check, diags := range analyzers {
+				var list []struct {
+					Posn    string `json:"posn"`
+					Message string `json:"message"`
+				}
+				// A failed analyzer reports {"error": "..."} instead.
+				if json.Unmarshal(diags, &list) != nil {
+					continue
+				}
+				for _, d := range list {
+					m := vetPosition.FindStringSubmatch(d.Posn)
+					if m == nil {
+						continue
+					}
+					line, _ := strconv.Atoi(m[2])
+					column, _ := strconv.Atoi(m[3])
+					issues[m[1]] = append(issues[m[1]], LintIssue{
+						Tool: "vet", Check: check, Severity: "warning", Message: d.Message, Line: line, Column: column,
+					})
+				}
+			}
+		}
+	}
+}
+
+// applyLintReports attaches the issues of the Options.LintReports files to
+// the innermost function around each, in Node.LintIssues. Issues outside
+// any function of the graph are dropped.
+func applyLintReports(g *Graph, input Options) {
+	absRoot, _ := filepath.Abs(input.ProjectRoot)
+	enclosing := enclosingFunction(g)
+	for _, report := range input.LintReports {
+		f, err := os.Open(resolvePath(input.ProjectRoot, report))
+		if err != nil {
+			input.warnf("lint report skipped: %v", err)
+			continue
+		}
+		issues, err := readLintReport(f)
+		f.Close()
+		if err != nil {
+			input.warnf("lint report %s skipped: %v", report, err)
+			continue
+		}
+		for file, list := range issues {
+			rel := file
+			if filepath.IsAbs(file) {
+				if rel, err = filepath.Rel(absRoot, file); err != nil {
+					continue
+				}
+			}
+			rel = filepath.ToSlash(filepath.Clean(rel))
+			if strings.HasPrefix(rel, "../") {
+				continue
+			}
+			for _, issue := range list {
+				if n := enclosing(rel, issue.Line); n != nil {
+					n.LintIssues = append(n.LintIssues, issue)
+				}
+			}
+		}
+	}
+	for i := range g.Nodes {
+		issues := g.Nodes[i].LintIssues
+		sort.Slice(issues, func(a, b int) bool {
+			ia, ib := issues[a], issues[b]
+			if ia.Line != ib.Line {
+				return ia.Line < ib.Line
+			}
+			if ia.Column != ib.Column {
+				return ia.Column < ib.Column
+			}
+			return ia.Tool+ia.Check+ia.Message < ib.Tool+ib.Check+ib.Message
+		})
+	}
+}
+
+// lintSeverities counts the lint issues of each package by severity.
+func lintSeverities(g *Graph) map[string]map[string]int {
+	var rollup map[string]map[string]int
+	for _, n := range g.Nodes {
+		for _, issue := range n.LintIssues {
+			if rollup == nil {
+				rollup = make(map[string]map[string]int)
+			}
+			if rollup[n.PackageOrModule] == nil {
+				rollup[n.PackageOrModule] = make(map[string]int)
+			}
+			rollup[n.PackageOrModule][issue.Severity]++
+		}
+	}
+	return rollup
+}
@@ -0,0 +1,174 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Directives recognized in a function's doc comment. Like other Go
+// directives they are written without a space after the slashes.
+const (
+	// directiveKeep marks a function that is reached in ways the analyzer
+	// cannot see (reflection, linkname, plugins) as live.
+	directiveKeep = "//codegraph:keep"
+	// directiveEntrypoint makes a function an entry point, e.g. one called
+	// from assembly, cgo or another binary.
+	directiveEntrypoint = "//codegraph:entrypoint"
+)
+
+// keptByDirective is Node.KeptBy for functions marked with directiveKeep.
+const keptByDirective = "directive"
+
+// funcDirectives reports which codegraph directives the doc comment of
+// funcDecl carries.
+func funcDirectives(funcDecl *ast.FuncDecl) (entry, keep bool) {
+	if funcDecl.Doc == nil {
+		return false, false
+	}
+	for _, c := range funcDecl.Doc.List {
+		directive, _, _ := strings.Cut(c.Text, " ")
+		switch directive {
+		case directiveEntrypoint:
+			entry = true
+		case directiveKeep:
+			keep = true
+		}
+	}
+	return entry, keep
+}
+
+// applyKeep marks the nodes matched by the Options.Keep globs.
+func applyKeep(g *Graph, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+	keptBy := keepMatcher(patterns)
+	for i := range g.Nodes {
+		if g.Nodes[i].KeptBy == "" {
+			g.Nodes[i].KeptBy = keptBy(g.Nodes[i])
+		}
+	}
+}
+
+// keepMatcher returns a function giving the first of the Options.Keep globs
+// that matches a node's ID or SymbolID, or "".
+func keepMatcher(patterns []string) func(Node) string {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = globRegexp(p)
+	}
+	return func(n Node) string {
+		for i, re := range res {
+			if re.MatchString(n.ID) || n.SymbolID != "" && re.MatchString(n.SymbolID) {
+				return patterns[i]
+			}
+		}
+		return ""
+	}
+}
+
+// markLiveness sets each node's Status and Color from the call graph:
+// entry points are "entry", nodes reachable from an entry point or from a
+// kept node are "live", nodes reachable only from the entry points of
+// _test.go files (see Options.Tests) are "test-only", and the rest are
+// "dead". Colors follow the viewer: functions with unused parameters are
+// yellow when live and orange when dead. Graph.ColorRules then restyle the
+// nodes they match.
+func markLiveness(g *Graph) {
+	out := make(map[string][]string)
+	for _, e := range g.Edges {
+		out[e.Source] = append(out[e.Source], e.Target)
+	}
+	reach := func(roots []string) map[string]bool {
+		reachable := make(map[string]bool)
+		queue := roots
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			if reachable[id] {
+				continue
+			}
+			reachable[id] = true
+			queue = append(queue, out[id]...)
+		}
+		return reachable
+	}
+	var roots, testRoots []string
+	for _, n := range g.Nodes {
+		switch {
+		case isTestFile(n.FilePath) && n.IsEntryPoint:
+			testRoots = append(testRoots, n.ID)
+		case n.IsEntryPoint || n.KeptBy != "":
+			roots = append(roots, n.ID)
+		}
+	}
+	reachable := reach(roots)
+	fromTests := reach(testRoots)
+
+	markBinaries(g, out)
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		switch {
+		case n.IsEntryPoint:
+			n.Status, n.Color = "entry", "blue"
+		case reachable[n.ID]:
+			n.Status, n.Color = "live", "green"
+			if len(n.UnusedParameters) > 0 {
+				n.Color = "yellow"
+			}
+		case fromTests[n.ID]:
+			n.Status, n.Color = "test-only", "purple"
+		default:
+			n.Status, n.Color = "dead", "red"
+			if len(n.UnusedParameters) > 0 {
+				n.Color = "orange"
+			}
+		}
+	}
+	applyColorRules(g)
+}
+
+// markBinaries sets each node's ReachableFrom to the main packages (by
+// PackageOrModule, e.g. "cmd/worker") whose binary reaches it: from the
+// package's main function, init functions and package-level initializers.
+// Code used only by one binary, or by none, stands out this way.
+func markBinaries(g *Graph, out map[string][]string) {
+	roots := make(map[string][]string)
+	for _, n := range g.Nodes {
+		if n.Kind == "function" && n.Name == "main" && n.IsEntryPoint {
+			roots[n.PackageOrModule] = append(roots[n.PackageOrModule], n.ID)
+		}
+	}
+	for _, n := range g.Nodes {
+		if _, ok := roots[n.PackageOrModule]; ok && n.IsEntryPoint && (n.Kind == "init" || n.Name == "init") {
+			roots[n.PackageOrModule] = append(roots[n.PackageOrModule], n.ID)
+		}
+	}
+	binaries := make([]string, 0, len(roots))
+	for binary := range roots {
+		binaries = append(binaries, binary)
+	}
+	sort.Strings(binaries)
+
+	reachedBy := make(map[string][]string)
+	for _, binary := range binaries {
+		seen := make(map[string]bool)
+		queue := roots[binary]
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			reachedBy[id] = append(reachedBy[id], binary)
+			queue = append(queue, out[id]...)
+		}
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].ReachableFrom = reachedBy[g.Nodes[i].ID]
+	}
+}
@@ -0,0 +1,166 @@
+package goanalyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadCache keeps the packages of earlier type-aware loads, so that a
+// long-running helper (go-helper --batch) answers repeated requests on an
+// unchanged project without loading and type-checking it again. A load is
+// reused when its configuration (root, patterns, build context, overlays)
+// is the same and no .go, go.mod, go.sum or go.work file under the root was
+// added, removed or modified since; changes outside the root, such as to a
+// replace directory, go unnoticed. Only the loads of the latest project
+// root are kept. The toolchain checks of type-aware analysis are kept too,
+// while no go.mod, go.sum or go.work file changes. Set it in Options.Cache.
+type LoadCache struct {
+	mu        sync.Mutex
+	root      string
+	entries   map[string]*cachedLoad
+	toolchain map[string]*cachedCheck
+}
+
+type cachedLoad struct {
+	fingerprint string
+	pkgs        []*packages.Package
+}
+
+type cachedCheck struct {
+	fingerprint string
+	err         error
+}
+
+func NewLoadCache() *LoadCache {
+	return &LoadCache{entries: make(map[string]*cachedLoad), toolchain: make(map[string]*cachedCheck)}
+}
+
+// reset forgets the entries of another root. c.mu must be held.
+func (c *LoadCache) reset(absRoot string) {
+	if c.root != absRoot {
+		c.root, c.entries, c.toolchain = absRoot, make(map[string]*cachedLoad), make(map[string]*cachedCheck)
+	}
+}
+
+// checkToolchain runs check, or returns the result of an earlier check
+// with the same environment while the module files under absRoot are
+// unchanged. Checks cut short by ctx are not kept. A nil cache always
+// checks.
+func (c *LoadCache) checkToolchain(ctx context.Context, absRoot string, env []string, check func() error) error {
+	if c == nil {
+		return check()
+	}
+	fingerprint, err := fingerprintFiles(absRoot, isModuleFile)
+	if err != nil {
+		return check()
+	}
+	key := fmt.Sprintf("%q", env)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset(absRoot)
+	if e, ok := c.toolchain[key]; ok && e.fingerprint == fingerprint {
+		return e.err
+	}
+	err = check()
+	if ctx.Err() == nil {
+		c.toolchain[key] = &cachedCheck{fingerprint: fingerprint, err: err}
+	}
+	return err
+}
+
+// load runs packages.Load, or returns the packages of an earlier load with
+// the same configuration over the same files. A nil cache always loads.
+func (c *LoadCache) load(cfg *packages.Config, patterns []string, absRoot string) (pkgs []*packages.Package, cached bool, err error) {
+	if c == nil {
+		pkgs, err = packages.Load(cfg, patterns...)
+		return pkgs, false, err
+	}
+	// The files are fingerprinted before loading, so that an edit made
+	// during the load invalidates it.
+	fingerprint, err := FingerprintProject(absRoot)
+	if err != nil {
+		pkgs, err = packages.Load(cfg, patterns...)
+		return pkgs, false, err
+	}
+	key := loadKey(cfg, patterns, absRoot)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset(absRoot)
+	if e, ok := c.entries[key]; ok && e.fingerprint == fingerprint {
+		return e.pkgs, true, nil
+	}
+	pkgs, err = packages.Load(cfg, patterns...)
+	if err == nil {
+		c.entries[key] = &cachedLoad{fingerprint: fingerprint, pkgs: pkgs}
+	} else {
+		delete(c.entries, key)
+	}
+	return pkgs, false, err
+}
+
+// loadKey identifies the configuration of a load.
+func loadKey(cfg *packages.Config, patterns []string, absRoot string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%q\x00%q\x00%q\n", absRoot, patterns, cfg.Env, cfg.BuildFlags)
+	names := make([]string, 0, len(cfg.Overlay))
+	for name := range cfg.Overlay {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(cfg.Overlay[name]))
+		h.Write(cfg.Overlay[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FingerprintProject hashes the names, sizes and modification times of the
+// files under absRoot that loading its packages depends on: the .go, go.mod,
+// go.sum and go.work files outside hidden directories and node_modules. It
+// changes when any of them is added, removed or modified.
+func FingerprintProject(absRoot string) (string, error) {
+	return fingerprintFiles(absRoot, func(name string) bool { return strings.HasSuffix(name, ".go") || isModuleFile(name) })
+}
+
+// isModuleFile reports whether name is a go.mod, go.sum or go.work file.
+func isModuleFile(name string) bool {
+	return name == "go.mod" || name == "go.sum" || name == "go.work"
+}
+
+// fingerprintFiles is FingerprintProject over the files whose names match.
+func fingerprintFiles(absRoot string, match func(name string) bool) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != absRoot && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !match(name) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil)), err
+}
@@ -0,0 +1,198 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// LogCall is a logging statement found in a function body.
+type LogCall struct {
+	Library string `json:"library"`
+	Level   string `json:"level"`
+	// Message is the constant prefix of the log message (up to the first
+	// format verb or non-constant operand), empty if none is known.
+	Message string `json:"message,omitempty"`
+	Line    int    `json:"line"`
+}
+
+// Logging libraries by import path prefix.
+var logLibraries = []struct {
+	pkgPrefix string
+	library   string
+}{
+	{"log/slog", "slog"},
+	{"log", "log"},
+	{"go.uber.org/zap", "zap"},
+	{"github.com/rs/zerolog", "zerolog"},
+	{"github.com/sirupsen/logrus", "logrus"},
+}
+
+// logLevels maps level-named logging functions (after stripping the f/w/ln/
+// Context suffixes) to a normalized level.
+var logLevels = map[string]string{
+	"Trace": "trace", "Debug": "debug", "Info": "info", "Print": "info",
+	"Warn": "warn", "Warning": "warn", "Error": "error", "Err": "error",
+	"DPanic": "dpanic", "Panic": "panic", "Fatal": "fatal",
+}
+
+// collectLogCalls returns the logging calls made in body.
+func collectLogCalls(body *ast.BlockStmt, ctx *passContext) []LogCall {
+	var calls []LogCall
+	// Calls whose result is the receiver of another call, i.e. links in the
+	// middle of a fluent chain such as log.Info().Err(err).Msg("x").
+	chained := make(map[*ast.CallExpr]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			if inner, ok := ast.Unparen(sel.X).(*ast.CallExpr); ok {
+				chained[inner] = true
+			}
+		}
+		target, ok := ctx.resolveCall(call)
+		if !ok {
+			return true
+		}
+		lc, ok := matchLogCall(call, target, ctx)
+		if !ok || (chained[call] && lc.Library == "unknown") {
+			return true
+		}
+		lc.Line = ctx.fset.Position(call.Pos()).Line
+		calls = append(calls, lc)
+		return true
+	})
+	return calls
+}
+
+func matchLogCall(call *ast.CallExpr, target callTarget, ctx *passContext) (LogCall, bool) {
+	library := logLibrary(target, ctx.info != nil)
+	if library == "" {
+		return LogCall{}, false
+	}
+
+	// zerolog builds events fluently: log.Info().Str("k", v).Msg("text").
+	// The terminal Msg/Msgf/Send call carries the message; the level comes
+	// from the head of the chain.
+	if library == "zerolog" {
+		switch target.name {
+		case "Msg", "Msgf", "Send":
+		default:
+			return LogCall{}, false
+		}
+		lc := LogCall{Library: library, Level: zerologChainLevel(target.recvExpr)}
+		if target.name != "Send" && len(call.Args) > 0 {
+			lc.Message = ctx.constPrefix(call.Args[0], target.name == "Msgf")
+		}
+		return lc, true
+	}
+
+	level, formatted := logLevelOf(target.name)
+	msgArg := 0
+	if strings.HasSuffix(target.name, "Context") {
+		msgArg = 1 // slog.InfoContext(ctx, msg, ...)
+	}
+	if library == "slog" && target.name == "Log" {
+		// slog.Log(ctx, level, msg, ...)
+		level, msgArg = slogLevelArg(call), 2
+	}
+	if level == "" {
+		return LogCall{}, false
+	}
+
+	lc := LogCall{Library: library, Level: level}
+	if msgArg < len(call.Args) {
+		lc.Message = ctx.constPrefix(call.Args[msgArg], formatted)
+	}
+	return lc, true
+}
+
+// logLibrary identifies the logging library of a call target, or "".
+// Without type information, method calls on receivers whose name mentions
+// "log" are attributed to an "unknown" library.
+func logLibrary(target callTarget, typed bool) string {
+	if target.pkgPath != "" {
+		for _, l := range logLibraries {
+			if !target.inPackage(l.pkgPrefix) {
+				continue
+			}
+			// zap's package-level functions are field constructors
+			// (zap.Error(err)), only Logger methods log.
+			if l.library == "zap" && target.recvExpr == nil {
+				return ""
+			}
+			return l.library
+		}
+		return ""
+	}
+	if typed || target.recvExpr == nil {
+		return ""
+	}
+	if strings.Contains(strings.ToLower(target.recvText()), "log") {
+		if strings.HasPrefix(target.name, "Msg") || target.name == "Send" {
+			return "zerolog"
+		}
+		return "unknown"
+	}
+	return ""
+}
+
+// logLevelOf maps a logging function name to its level and whether its first
+// message argument is a format string.
+func logLevelOf(name string) (level string, formatted bool) {
+	base := strings.TrimSuffix(name, "Context")
+	switch {
+	case strings.HasSuffix(base, "ln"):
+		base = strings.TrimSuffix(base, "ln")
+	case strings.HasSuffix(base, "f"):
+		base, formatted = strings.TrimSuffix(base, "f"), true
+	case strings.HasSuffix(base, "w"):
+		base = strings.TrimSuffix(base, "w")
+	}
+	return logLevels[base], formatted
+}
+
+// zerologChainLevel walks a zerolog event chain back to the call that
+// created the event (Info(), Error(), Err(err), WithLevel(lvl), ...). The
+// head of the chain wins, so Info().Err(err) is still an info event.
+func zerologChainLevel(e ast.Expr) string {
+	level := ""
+	for {
+		call, ok := ast.Unparen(e).(*ast.CallExpr)
+		if !ok {
+			return level
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return level
+		}
+		if l, _ := logLevelOf(sel.Sel.Name); l != "" {
+			level = l
+		} else if sel.Sel.Name == "WithLevel" && len(call.Args) == 1 {
+			level = levelFromExpr(call.Args[0])
+		}
+		e = sel.X
+	}
+}
+
+func slogLevelArg(call *ast.CallExpr) string {
+	if len(call.Args) < 2 {
+		return ""
+	}
+	if level := levelFromExpr(call.Args[1]); level != "" {
+		return level
+	}
+	return "custom"
+}
+
+// levelFromExpr recognizes level constants such as slog.LevelWarn,
+// zerolog.ErrorLevel or zapcore.InfoLevel by name.
+func levelFromExpr(e ast.Expr) string {
+	sel, ok := ast.Unparen(e).(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(sel.Sel.Name, "Level"), "Level")
+	return logLevels[name]
+}
@@ -0,0 +1,373 @@
+package goanalyzer
+
+import (
+	"path"
+	"strings"
+)
+
+// Shard is a graph produced by a run scoped to part of a project, such as a
+// subtree analyzed by one CI job.
+type Shard struct {
+	// Prefix is the shard's ProjectRoot relative to the merged project root,
+	// slash-separated, or "" if the shard ran from the same root.
+	Prefix string
+	Graph  Graph
+}
+
+// Merge combines shards into one graph. File paths and node IDs are rebased
+// onto the common root, and nodes found by several shards are kept once:
+// nodes are the same if they have the same ID or, outside main packages
+// (whose symbols all start with "main."), the same SymbolID. Unresolved
+// edges whose target is the SymbolID of a merged node, as recorded with
+// Options.KeepUnresolved, are linked to it. Diagnostics, findings, routes
+// and dead-code reports are concatenated, stats are summed, the first color
+// rules found are kept, and liveness, the summary, hashes and any search
+// index, hierarchy, bundles, digest and endpoint dependencies are rebuilt.
+func Merge(shards []Shard) Graph {
+	merged := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	index := make(map[string]int)    // node ID → position in merged.Nodes
+	alias := make(map[string]string) // shard node ID → merged node ID
+	bySymbol := make(map[string]string)
+	var stats *Stats
+	var deadCode *DeadCodeReport
+	indexed, bundled, digested, endpoints := false, false, false, false
+	var hierarchy *Container
+
+	// aliased maps a shard node ID to the merged one; IDs of no node, such
+	// as the qualified names of external handlers, are kept.
+	aliased := func(id string) string {
+		if merged, ok := alias[id]; ok {
+			return merged
+		}
+		return id
+	}
+
+	var edges []Edge
+	for _, shard := range shards {
+		g := shard.Graph.rebased(shard.Prefix)
+		for _, n := range g.Nodes {
+			id := n.ID
+			if _, ok := index[id]; !ok && n.SymbolID != "" && !strings.HasPrefix(n.SymbolID, "main.") {
+				if existing, ok := bySymbol[n.SymbolID]; ok {
+					id = existing
+				}
+			}
+			alias[n.ID] = id
+			if i, ok := index[id]; ok {
+				// Prefer a precise node over one recovered from a broken file.
+				if merged.Nodes[i].Approximate && !n.Approximate {
+					n.ID = id
+					merged.Nodes[i] = n
+				}
+				continue
+			}
+			index[id] = len(merged.Nodes)
+			merged.Nodes = append(merged.Nodes, n)
+			if n.SymbolID != "" {
+				bySymbol[n.SymbolID] = id
+			}
+		}
+		// Edges are linked once every shard's nodes are known.
+		for _, e := range g.Edges {
+			if id, ok := alias[e.Source]; ok {
+				e.Source = id
+			}
+			if id, ok := alias[e.Target]; ok {
+				e.Target = id
+			}
+			edges = append(edges, e)
+		}
+		merged.Diagnostics = append(merged.Diagnostics, g.Diagnostics...)
+		for _, f := range g.Findings {
+			if len(f.Nodes) > 0 {
+				nodes := make([]string, len(f.Nodes))
+				for i, id := range f.Nodes {
+					nodes[i] = aliased(id)
+				}
+				f.Nodes = nodes
+			}
+			if len(f.Suggestions) > 0 {
+				suggestions := make([]FixSuggestion, len(f.Suggestions))
+				for i, fix := range f.Suggestions {
+					nodes := make([]string, len(fix.Nodes))
+					for j, id := range fix.Nodes {
+						nodes[j] = aliased(id)
+					}
+					fix.Nodes = nodes
+					suggestions[i] = fix
+				}
+				f.Suggestions = suggestions
+			}
+			merged.Findings = append(merged.Findings, f)
+		}
+		for _, r := range g.Routes {
+			r.Handler, r.RegisteredIn = aliased(r.Handler), aliased(r.RegisteredIn)
+			merged.Routes = append(merged.Routes, r)
+		}
+		stats = stats.add(g.Stats)
+		indexed = indexed || shard.Graph.SearchIndex != nil
+		bundled = bundled || shard.Graph.Bundles != nil
+		digested = digested || shard.Graph.AISummary != nil
+		endpoints = endpoints || shard.Graph.EndpointDependencies != nil
+		if hierarchy == nil {
+			hierarchy = shard.Graph.Hierarchy
+		}
+		if d := g.DeadCode; d != nil {
+			if deadCode == nil {
+				deadCode = &DeadCodeReport{New: []string{}}
+			}
+			for _, id := range d.New {
+				deadCode.New = append(deadCode.New, aliased(id))
+			}
+			deadCode.Baselined += d.Baselined
+			deadCode.Resolved = append(deadCode.Resolved, d.Resolved...)
+		}
+	}
+
+	seen := make(map[edgeKey]bool)
+	for _, e := range edges {
+		if !e.IsResolved {
+			if id, ok := bySymbol[e.Target]; ok {
+				e.Target, e.IsResolved = id, true
+				e.Provenance.Phase, e.Provenance.Rule = "merge", ruleSymbolLink
+			}
+		}
+		key := keyOf(e)
+		if e.Source == e.Target || seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged.Edges = append(merged.Edges, e)
+	}
+
+	markLiveness(&merged)
+	if deadCode != nil {
+		// Calls linked across shards may have revived functions.
+		status := make(map[string]string, len(merged.Nodes))
+		for _, n := range merged.Nodes {
+			status[n.ID] = n.Status
+		}
+		added := deadCode.New[:0]
+		for _, id := range deadCode.New {
+			if status[id] == "dead" {
+				added = append(added, id)
+				status[id] = "" // report once
+			}
+		}
+		deadCode.New = added
+		merged.DeadCode = deadCode
+	}
+	merged.countCalls()
+	merged.Summary = buildSummary(&merged)
+	merged.computeHashes()
+	if indexed {
+		merged.SearchIndex = buildSearchIndex(&merged)
+	}
+	if hierarchy != nil {
+		merged.Hierarchy = buildHierarchy(&merged, hierarchy.Name)
+	}
+	if bundled {
+		merged.Bundles = buildBundles(&merged)
+	}
+	if digested {
+		merged.AISummary = buildAISummary(&merged)
+	}
+	if endpoints {
+		merged.EndpointDependencies = buildEndpointDependencies(&merged)
+	}
+	if stats != nil {
+		stats.finish(&merged)
+		merged.Stats = stats
+	}
+	return merged
+}
+
+// rebased returns a copy of g with file paths, and the node IDs and
+// packages derived from them, moved under prefix.
+func (g Graph) rebased(prefix string) Graph {
+	prefix = strings.Trim(path.Clean("/"+prefix), "/")
+	if prefix == "" {
+		return g
+	}
+	ids := make(map[string]string, len(g.Nodes))
+	out := Graph{Summary: g.Summary, Stats: g.Stats}
+	for _, n := range g.Nodes {
+		if n.FilePath != "" {
+			file := path.Join(prefix, n.FilePath)
+			oldID := n.ID
+			n.ID = rebaseID(n.ID, n.FilePath, file)
+			n.QualifiedName = rebaseID(n.QualifiedName, n.FilePath, file)
+			n.FilePath = file
+			n.PackageOrModule = path.Dir(file)
+			ids[oldID] = n.ID
+		}
+		out.Nodes = append(out.Nodes, n)
+	}
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, e.rebased(prefix, ids))
+	}
+	for _, d := range g.Diagnostics {
+		d.FilePath = path.Join(prefix, d.FilePath)
+		out.Diagnostics = append(out.Diagnostics, d)
+	}
+	for _, r := range g.Routes {
+		if id, ok := ids[r.Handler]; ok {
+			r.Handler = id
+		}
+		if id, ok := ids[r.RegisteredIn]; ok {
+			r.RegisteredIn = id
+		}
+		r.CallSite.FilePath = path.Join(prefix, r.CallSite.FilePath)
+		out.Routes = append(out.Routes, r)
+	}
+	for _, f := range g.Findings {
+		if packageFindings[f.Kind] {
+			f.From, f.To = rebasePath(prefix, f.From), rebasePath(prefix, f.To)
+		}
+		offending := make([]Edge, 0, len(f.Edges))
+		for _, e := range f.Edges {
+			offending = append(offending, e.rebased(prefix, ids))
+		}
+		f.Edges = offending
+		if len(f.Nodes) > 0 {
+			nodes := make([]string, len(f.Nodes))
+			for i, id := range f.Nodes {
+				if newID, ok := ids[id]; ok {
+					id = newID
+				}
+				nodes[i] = id
+			}
+			f.Nodes = nodes
+		}
+		if len(f.Suggestions) > 0 {
+			suggestions := make([]FixSuggestion, len(f.Suggestions))
+			for i, fix := range f.Suggestions {
+				nodes := make([]string, len(fix.Nodes))
+				for j, id := range fix.Nodes {
+					if newID, ok := ids[id]; ok {
+						id = newID
+					}
+					nodes[j] = id
+				}
+				fix.Nodes = nodes
+				fix.Package = rebasePath(prefix, fix.Package)
+				suggestions[i] = fix
+			}
+			f.Suggestions = suggestions
+		}
+		out.Findings = append(out.Findings, f)
+	}
+	if g.DeadCode != nil {
+		d := *g.DeadCode
+		d.New = make([]string, 0, len(g.DeadCode.New))
+		for _, id := range g.DeadCode.New {
+			if newID, ok := ids[id]; ok {
+				id = newID
+			}
+			d.New = append(d.New, id)
+		}
+		d.Resolved = make([]BaselineEntry, 0, len(g.DeadCode.Resolved))
+		for _, e := range g.DeadCode.Resolved {
+			e.ID = path.Join(prefix, e.ID)
+			d.Resolved = append(d.Resolved, e)
+		}
+		out.DeadCode = &d
+	}
+	return out
+}
+
+func (e Edge) rebased(prefix string, ids map[string]string) Edge {
+	if id, ok := ids[e.Source]; ok {
+		e.Source = id
+	}
+	if id, ok := ids[e.Target]; ok {
+		e.Target = id
+	}
+	if e.CallSite.FilePath != "" {
+		e.CallSite.FilePath = path.Join(prefix, e.CallSite.FilePath)
+	}
+	return e
+}
+
+// packageFindings are the kinds of findings whose From and To are package
+// directories.
+var packageFindings = map[string]bool{
+	"dependencyViolation": true,
+	"duplicateSymbol":     true,
+	"longParameterList":   true,
+	"parameterGroup":      true,
+}
+
+// rebasePath moves a non-empty relative path under prefix.
+func rebasePath(prefix, p string) string {
+	if p == "" {
+		return p
+	}
+	return path.Join(prefix, p)
+}
+
+// rebaseID replaces the file path an ID ("file.go:Func") starts with.
+func rebaseID(id, oldFile, newFile string) string {
+	if rest, ok := strings.CutPrefix(id, oldFile+":"); ok {
+		return newFile + ":" + rest
+	}
+	return id
+}
+
+// add returns the sum of two runs' stats. Phase timings are dropped, and
+// the merged graph is AlgorithmTypes only if every shard was.
+func (s *Stats) add(o *Stats) *Stats {
+	if o == nil {
+		return s
+	}
+	if s == nil {
+		sum := *o
+		sum.Phases = nil
+		return &sum
+	}
+	if s.Algorithm != o.Algorithm {
+		s.Algorithm = AlgorithmAST
+	}
+	s.PackagesLoaded += o.PackagesLoaded
+	s.FilesParsed += o.FilesParsed
+	s.UnresolvedCalls += o.UnresolvedCalls
+	s.ExternalCalls += o.ExternalCalls
+	s.InterfaceCallSites += o.InterfaceCallSites
+	s.InterfaceEdges += o.InterfaceEdges
+	s.MaxInterfaceFanOut = max(s.MaxInterfaceFanOut, o.MaxInterfaceFanOut)
+	s.GeneratedTypesSummarized += o.GeneratedTypesSummarized
+	s.GeneratedMethodEdgesSkipped += o.GeneratedMethodEdgesSkipped
+	return s
+}
+
+// CarryCuration copies the fields set by consumers of the graph
+// (StatusOverride and Keep) from the nodes of base onto the nodes of g
+// with the same SymbolID, or the same ID for nodes without one, so that a
+// user's curation survives re-analysis and merging. Nodes of g that carry
+// curation of their own keep it.
+func (g *Graph) CarryCuration(base Graph) {
+	curated := make(map[string]*Node)
+	for i := range base.Nodes {
+		n := &base.Nodes[i]
+		if n.StatusOverride != "" || n.Keep {
+			curated[curationKey(*n)] = n
+		}
+	}
+	if len(curated) == 0 {
+		return
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if old, ok := curated[curationKey(*n)]; ok && n.StatusOverride == "" && !n.Keep {
+			n.StatusOverride, n.Keep = old.StatusOverride, old.Keep
+		}
+	}
+}
+
+// curationKey identifies a node across analyses for CarryCuration.
+func curationKey(n Node) string {
+	if n.SymbolID != "" {
+		return "symbol:" + n.SymbolID
+	}
+	return "id:" + n.ID
+}
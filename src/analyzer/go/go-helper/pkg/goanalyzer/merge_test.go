@@ -0,0 +1,48 @@
+package goanalyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestMergeUnknownIDs(t *testing.T) {
+	shard := goanalyzer.Graph{
+		Nodes: []goanalyzer.Node{
+			{ID: "a/a.go:Run", FilePath: "a/a.go", PackageOrModule: "a"},
+			{ID: "b/b.go:Get", FilePath: "b/b.go", PackageOrModule: "b"},
+		},
+		Edges: []goanalyzer.Edge{{Source: "a/a.go:Run", Target: "b/b.go:Get", CallSite: goanalyzer.CallSite{FilePath: "a/a.go", Line: 3}, IsResolved: true}},
+		Routes: []goanalyzer.Route{{
+			Kind:         "http",
+			Path:         "/",
+			Handler:      "net/http.NotFound",
+			RegisteredIn: "a/a.go:Run",
+			CallSite:     goanalyzer.CallSite{FilePath: "a/a.go", Line: 4},
+		}},
+		Findings: []goanalyzer.Finding{
+			{Kind: "dependencyViolation", Rule: "a", From: "a", To: "b", Nodes: []string{"a/a.go:Run", "gone.go:Gone"}},
+			{Kind: "custom", Rule: "team", From: "payments", Nodes: []string{"gone.go:Gone"}},
+		},
+	}
+	merged := goanalyzer.Merge([]goanalyzer.Shard{{Prefix: "svc", Graph: shard}})
+
+	if len(merged.Routes) != 1 {
+		t.Fatalf("got routes %+v, want one", merged.Routes)
+	}
+	if r := merged.Routes[0]; r.Handler != "net/http.NotFound" || r.RegisteredIn != "svc/a/a.go:Run" {
+		t.Errorf("route served by %q registered in %q, want net/http.NotFound registered in svc/a/a.go:Run", r.Handler, r.RegisteredIn)
+	}
+	if len(merged.Findings) != 2 {
+		t.Fatalf("got findings %+v, want two", merged.Findings)
+	}
+	dep, custom := merged.Findings[0], merged.Findings[1]
+	if dep.From != "svc/a" || dep.To != "svc/b" || !slices.Equal(dep.Nodes, []string{"svc/a/a.go:Run", "gone.go:Gone"}) {
+		t.Errorf("dependency finding from %q to %q with nodes %v, want svc/a to svc/b with [svc/a/a.go:Run gone.go:Gone]", dep.From, dep.To, dep.Nodes)
+	}
+	// Only package directories are rebased.
+	if custom.From != "payments" || custom.To != "" || !slices.Equal(custom.Nodes, []string{"gone.go:Gone"}) {
+		t.Errorf("custom finding from %q to %q with nodes %v, want payments to \"\" with [gone.go:Gone]", custom.From, custom.To, custom.Nodes)
+	}
+}
@@ -0,0 +1,240 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+const (
+	prometheusPkg = "github.com/prometheus/client_golang/prometheus"
+)
+
+// metricUseMethods are the methods through which a collector is updated or a
+// labelled child is obtained.
+var metricUseMethods = map[string]bool{
+	"Inc": true, "Dec": true, "Add": true, "Sub": true, "Set": true,
+	"SetToCurrentTime": true, "Observe": true,
+	"WithLabelValues": true, "With": true, "CurryWith": true, "MustCurryWith": true,
+	"GetMetricWith": true, "GetMetricWithLabelValues": true,
+}
+
+// metricBindings records which variables and struct fields hold Prometheus
+// collectors, and the fully qualified metric name of each. Typed analysis keys
+// bindings by object; AST-only analysis falls back to the identifier name.
+type metricBindings struct {
+	byObj  map[types.Object]string
+	byName map[string]string
+}
+
+func newMetricBindings() *metricBindings {
+	return &metricBindings{
+		byObj:  make(map[types.Object]string),
+		byName: make(map[string]string),
+	}
+}
+
+// scanMetricDefinitions finds collector constructors in a file (at package
+// level and inside functions) and records the variable or field each one is
+// assigned to.
+func scanMetricDefinitions(file *ast.File, ctx *passContext) {
+	bind := func(lhs ast.Expr, rhs ast.Expr) {
+		name, ok := metricDefinition(rhs, ctx)
+		if !ok {
+			return
+		}
+		ctx.shared.metrics.bind(lhs, name, ctx)
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ValueSpec:
+			for i, v := range x.Values {
+				if i < len(x.Names) {
+					bind(x.Names[i], v)
+				}
+			}
+		case *ast.AssignStmt:
+			if len(x.Lhs) == len(x.Rhs) {
+				for i := range x.Lhs {
+					bind(x.Lhs[i], x.Rhs[i])
+				}
+			}
+		case *ast.KeyValueExpr:
+			bind(x.Key, x.Value)
+		}
+		return true
+	})
+}
+
+func (b *metricBindings) bind(lhs ast.Expr, metric string, ctx *passContext) {
+	if obj := bindingObject(lhs, ctx); obj != nil {
+		b.byObj[obj] = metric
+	}
+	if name := bindingName(lhs); name != "" {
+		b.byName[name] = metric
+	}
+}
+
+func (b *metricBindings) lookup(e ast.Expr, ctx *passContext) (string, bool) {
+	if ctx.info != nil {
+		obj := bindingObject(e, ctx)
+		if obj == nil {
+			return "", false
+		}
+		name, ok := b.byObj[obj]
+		return name, ok
+	}
+	name, ok := b.byName[bindingName(e)]
+	return name, ok
+}
+
+// bindingObject returns the variable or field object denoted by an
+// identifier or selector expression.
+func bindingObject(e ast.Expr, ctx *passContext) types.Object {
+	if ctx.info == nil {
+		return nil
+	}
+	switch x := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		if obj := ctx.info.Defs[x]; obj != nil {
+			return obj
+		}
+		return ctx.info.Uses[x]
+	case *ast.SelectorExpr:
+		if sel, ok := ctx.info.Selections[x]; ok {
+			return sel.Obj()
+		}
+		return ctx.info.Uses[x.Sel]
+	}
+	return nil
+}
+
+func bindingName(e ast.Expr) string {
+	switch x := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	}
+	return ""
+}
+
+// metricDefinition reports whether e constructs a Prometheus collector
+// (prometheus.NewCounterVec(...), promauto.NewGauge(...), factory.NewHistogram(...),
+// prometheus.NewDesc(...)) and returns its fully qualified metric name.
+func metricDefinition(e ast.Expr, ctx *passContext) (string, bool) {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+	target, ok := ctx.resolveCall(call)
+	if !ok || !strings.HasPrefix(target.name, "New") {
+		return "", false
+	}
+	if ctx.info != nil || target.pkgPath != "" {
+		if !target.inPackage(prometheusPkg) {
+			return "", false
+		}
+	} else if !strings.Contains(strings.ToLower(target.recvText()), "prom") &&
+		!strings.Contains(strings.ToLower(target.recvText()), "factory") {
+		return "", false
+	}
+
+	if target.name == "NewDesc" {
+		return ctx.constString(call.Args[0])
+	}
+	if !isCollectorConstructor(target.name) {
+		return "", false
+	}
+	opts := call.Args[0]
+	if u, ok := opts.(*ast.UnaryExpr); ok {
+		opts = u.X
+	}
+	lit, ok := opts.(*ast.CompositeLit)
+	if !ok {
+		return "", false
+	}
+	var namespace, subsystem, name string
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, _ := ctx.constString(kv.Value)
+		switch key.Name {
+		case "Namespace":
+			namespace = v
+		case "Subsystem":
+			subsystem = v
+		case "Name":
+			name = v
+		}
+	}
+	if name == "" {
+		return "", false
+	}
+	return buildFQName(namespace, subsystem, name), true
+}
+
+func isCollectorConstructor(name string) bool {
+	kind := strings.TrimPrefix(name, "New")
+	kind = strings.TrimSuffix(strings.TrimSuffix(kind, "Vec"), "Func")
+	switch kind {
+	case "Counter", "Gauge", "Histogram", "Summary", "UntypedFunc", "Untyped":
+		return true
+	}
+	return false
+}
+
+// buildFQName joins non-empty name components with "_", like prometheus.BuildFQName.
+func buildFQName(namespace, subsystem, name string) string {
+	var parts []string
+	for _, p := range []string{namespace, subsystem, name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// collectMetrics returns the names of metrics a function defines or updates.
+func collectMetrics(body *ast.BlockStmt, ctx *passContext) []string {
+	var metrics []string
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if name, ok := metricDefinition(call, ctx); ok {
+			metrics = append(metrics, name)
+			return true
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok || !metricUseMethods[sel.Sel.Name] {
+			return true
+		}
+		// Unwind labelled children: m.WithLabelValues("a").Inc() → m
+		recv := ast.Unparen(sel.X)
+		for {
+			inner, ok := recv.(*ast.CallExpr)
+			if !ok {
+				break
+			}
+			innerSel, ok := ast.Unparen(inner.Fun).(*ast.SelectorExpr)
+			if !ok || !metricUseMethods[innerSel.Sel.Name] {
+				break
+			}
+			recv = ast.Unparen(innerSel.X)
+		}
+		if name, ok := ctx.shared.metrics.lookup(recv, ctx); ok {
+			metrics = append(metrics, name)
+		}
+		return true
+	})
+	return sortedUnique(metrics)
+}
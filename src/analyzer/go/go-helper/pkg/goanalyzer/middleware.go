@@ -0,0 +1,78 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+)
+
+// unwrapChain peels middleware off a handler passed to a registration
+// call, as in
+//
+//	mux.Handle("GET /users", authMW(logMW(http.HandlerFunc(handleUsers))))
+//
+// It returns the middleware, outermost first, by node ID or else qualified
+// name, and the handler at the end, or a nil handler when expr is not such
+// a chain.
+func unwrapChain(expr ast.Expr, info *types.Info, objToNodeID map[types.Object]string) (middleware []string, handler *types.Func) {
+	wrappers, inner := peelChain(expr, info)
+	if len(wrappers) == 0 {
+		return nil, nil
+	}
+	for _, fn := range wrappers {
+		middleware = append(middleware, funcName(fn, objToNodeID))
+	}
+	handler, _ = referencedObject(inner, info).(*types.Func)
+	return middleware, handler
+}
+
+// peelChain returns the middleware wrapped around a handler, outermost
+// first, and the expression they wrap. Each call whose last argument is a
+// handler (see handlerTyped) is a middleware, and conversions such as
+// http.HandlerFunc are looked through; any other call, such as the factory
+// in users(db), ends the chain.
+func peelChain(expr ast.Expr, info *types.Info) (wrappers []*types.Func, inner ast.Expr) {
+	for {
+		expr = ast.Unparen(expr)
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return wrappers, expr
+		}
+		if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+			expr = call.Args[0]
+			continue
+		}
+		fn := calleeFunc(call, info)
+		last := call.Args[len(call.Args)-1]
+		if fn == nil || !handlerTyped(info.TypeOf(last)) {
+			return wrappers, expr
+		}
+		wrappers = append(wrappers, fn)
+		expr = last
+	}
+}
+
+// handlerTyped reports whether a value of type t can be a handler: a
+// function, or a type with a ServeHTTP method.
+func handlerTyped(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if _, ok := t.Underlying().(*types.Signature); ok {
+		return true
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "ServeHTTP")
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// routeOf returns the first constant string argument of a registration
+// call, such as the pattern of http.HandleFunc.
+func routeOf(call *ast.CallExpr, info *types.Info) string {
+	for _, arg := range call.Args {
+		if tv, ok := info.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value)
+		}
+	}
+	return ""
+}
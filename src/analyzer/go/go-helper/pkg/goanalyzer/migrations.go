@@ -0,0 +1,190 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// migrationsNodeID is the synthetic entry node migrations hang from.
+const migrationsNodeID = "migrations"
+
+// Import paths of the migration libraries, without major version suffix.
+const (
+	gooseImport   = "github.com/pressly/goose"
+	migrateImport = "github.com/golang-migrate/migrate"
+	atlasImport   = "ariga.io/atlas/sql/migrate"
+)
+
+// linkMigrations detects how the project registers database migrations
+// and links them under a synthetic "migrations" entry node, since the
+// migration tool rather than the project's code runs them:
+//
+//   - Go migrations passed to goose.AddMigration and its variants;
+//   - the files of migration directories: goose.Up(db, "migrations"),
+//     migrate.New("file://migrations", url), iofs.New(fsys, "migrations")
+//     and atlas's migrate.NewLocalDir("migrations"), each file becoming a
+//     node of Kind "migration";
+//   - the //go:embed variables (see addEmbedNodes) passed to these
+//     libraries, such as goose.SetBaseFS(migrationsFS).
+//
+// Directories are looked up under the project root and next to the
+// registering file.
+func linkMigrations(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, nodes *[]Node, edges *[]Edge, debug bool) {
+	embeds := make(map[string]string)
+	for _, n := range *nodes {
+		if n.Kind == "embed" {
+			embeds[n.SymbolID] = n.ID
+		}
+	}
+	files := make(map[string]bool)
+	linked := make(map[string]bool)
+	link := func(target string, site CallSite, at ast.Node) {
+		if linked[target] {
+			return
+		}
+		linked[target] = true
+		*edges = append(*edges, Edge{
+			Source:     migrationsNodeID,
+			Target:     target,
+			CallSite:   site,
+			Kind:       "migration",
+			IsResolved: true,
+			Provenance: newProvenance("migrations", ruleMigration, at, debug),
+		})
+	}
+
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := paths.rel(absPath)
+			if err != nil {
+				continue
+			}
+			relPath = filepath.ToSlash(relPath)
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				fn := calleeFunc(call, pkg.TypesInfo)
+				if fn == nil || fn.Pkg() == nil || !isMigrationLibrary(fn.Pkg().Path()) {
+					return true
+				}
+				pos := pkg.Fset.Position(call.Pos())
+				site := CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column}
+				for _, arg := range call.Args {
+					obj := referencedObject(arg, pkg.TypesInfo)
+					switch obj := obj.(type) {
+					case *types.Func:
+						if id, ok := objToNodeID[obj]; ok && strings.HasPrefix(fn.Name(), "Add") {
+							link(id, site, arg)
+						}
+						continue
+					case *types.Var:
+						if obj.Pkg() != nil {
+							if id, ok := embeds[obj.Pkg().Path()+"."+obj.Name()]; ok {
+								link(id, site, arg)
+							}
+						}
+						continue
+					}
+					tv, ok := pkg.TypesInfo.Types[arg]
+					if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+						continue
+					}
+					dir := strings.TrimPrefix(constant.StringVal(tv.Value), "file://")
+					for _, f := range migrationFiles(paths.root, filepath.Dir(absPath), dir) {
+						if !files[f] {
+							files[f] = true
+							*nodes = append(*nodes, migrationNode(f))
+						}
+						link(f, site, arg)
+					}
+				}
+				return true
+			})
+		}
+	}
+	if len(linked) > 0 {
+		*nodes = append(*nodes, Node{
+			ID:               migrationsNodeID,
+			Name:             migrationsNodeID,
+			QualifiedName:    migrationsNodeID,
+			Language:         "go",
+			Kind:             "migrations",
+			Visibility:       "exported",
+			IsEntryPoint:     true,
+			Parameters:       []Parameter{},
+			UnusedParameters: []string{},
+			PackageOrModule:  migrationsNodeID,
+			Status:           "entry",
+			Color:            "blue",
+		})
+	}
+}
+
+func isMigrationLibrary(pkgPath string) bool {
+	for _, lib := range []string{gooseImport, migrateImport, atlasImport} {
+		if pkgPath == lib || strings.HasPrefix(pkgPath, lib+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// migrationFiles returns the files, relative to root and slash-separated,
+// directly inside dir, tried relative to root and then to fileDir. Names
+// starting with "." or "_" are skipped.
+func migrationFiles(root, fileDir, dir string) []string {
+	if dir == "" || strings.Contains(dir, "://") {
+		return nil
+	}
+	for _, base := range []string{root, fileDir} {
+		full := filepath.Join(base, filepath.FromSlash(dir))
+		entries, err := os.ReadDir(full)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, full)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		var files []string
+		for _, e := range entries {
+			if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(e.Name(), "_") && !strings.HasSuffix(e.Name(), ".go") {
+				files = append(files, path.Join(filepath.ToSlash(rel), e.Name()))
+			}
+		}
+		sort.Strings(files)
+		return files
+	}
+	return nil
+}
+
+// migrationNode is the node of a migration file. Its ID is its path; like
+// other synthetic nodes it has no FilePath, which names Go files.
+func migrationNode(relPath string) Node {
+	language := strings.TrimPrefix(path.Ext(relPath), ".")
+	if language == "" {
+		language = "text"
+	}
+	return Node{
+		ID:               relPath,
+		Name:             path.Base(relPath),
+		QualifiedName:    relPath,
+		Language:         language,
+		Kind:             "migration",
+		Visibility:       "module",
+		Parameters:       []Parameter{},
+		UnusedParameters: []string{},
+		PackageOrModule:  path.Dir(relPath),
+	}
+}
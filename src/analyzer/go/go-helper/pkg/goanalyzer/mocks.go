@@ -0,0 +1,122 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Policies for Options.MockImplementations.
+const (
+	// MockExclude leaves mock types out of interface dispatch.
+	MockExclude = "exclude"
+	// MockTag sets Edge.IsMock on the dispatch edges to mock methods.
+	MockTag = "tag"
+)
+
+// checkMockImplementations reports an unknown Options.MockImplementations
+// policy.
+func (o Options) checkMockImplementations() error {
+	switch o.MockImplementations {
+	case "", MockExclude, MockTag:
+		return nil
+	}
+	return fmt.Errorf("unknown mockImplementations %q (want %q or %q)", o.MockImplementations, MockExclude, MockTag)
+}
+
+// findMockTypes returns the mocks among concreteTypes: types generated by
+// mockgen or mockery, declared in a mock package ("mock", "mocks",
+// "mock_store", "storemocks") or holding a *gomock.Controller or a testify
+// mock.Mock.
+func findMockTypes(pkgs []*packages.Package, concreteTypes []*types.Named) map[*types.Named]bool {
+	mockFiles := make(map[string]bool)
+	mockPkgs := make(map[*types.Package]bool)
+	for _, pkg := range pkgs {
+		if isMockPackage(pkg.Name) {
+			mockPkgs[pkg.Types] = true
+		}
+		for _, file := range pkg.Syntax {
+			if isMockFile(file) {
+				mockFiles[pkg.Fset.File(file.Pos()).Name()] = true
+			}
+		}
+	}
+	mocks := make(map[*types.Named]bool)
+	for _, named := range concreteTypes {
+		obj := named.Obj()
+		// go/packages loads every package into the same FileSet.
+		if mockPkgs[obj.Pkg()] || mockFiles[pkgs[0].Fset.File(obj.Pos()).Name()] || hasMockField(named) {
+			mocks[named] = true
+		}
+	}
+	return mocks
+}
+
+func isMockPackage(name string) bool {
+	return name == "mock" || strings.HasPrefix(name, "mock_") || strings.HasSuffix(name, "mocks")
+}
+
+// isMockFile reports whether file is generated by mockgen or mockery.
+func isMockFile(file *ast.File) bool {
+	if !ast.IsGenerated(file) {
+		return false
+	}
+	for _, cg := range file.Comments {
+		if cg.Pos() >= file.Package {
+			break
+		}
+		if text := cg.Text(); strings.Contains(text, "MockGen") || strings.Contains(text, "mockery") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMockField reports whether named is a struct with a *gomock.Controller
+// field or an embedded testify mock.Mock.
+func hasMockField(named *types.Named) bool {
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		t := st.Field(i).Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		field, ok := t.(*types.Named)
+		if !ok || field.Obj().Pkg() == nil {
+			continue
+		}
+		path, name := field.Obj().Pkg().Path(), field.Obj().Name()
+		if (strings.HasSuffix(path, "/gomock") && name == "Controller") || (path == "github.com/stretchr/testify/mock" && name == "Mock") {
+			return true
+		}
+	}
+	return false
+}
+
+// tagMockEdges sets IsMock on the dispatch edges ("interface" and
+// "provided") into the methods of mocks.
+func tagMockEdges(edges []Edge, mocks map[*types.Named]bool, objToNodeID map[types.Object]string) {
+	if len(mocks) == 0 {
+		return
+	}
+	methods := make(map[string]bool)
+	for named := range mocks {
+		mset := types.NewMethodSet(types.NewPointer(named))
+		for i := 0; i < mset.Len(); i++ {
+			if id, ok := objToNodeID[mset.At(i).Obj()]; ok {
+				methods[id] = true
+			}
+		}
+	}
+	for i := range edges {
+		if e := &edges[i]; (e.Kind == "interface" || e.Kind == "provided") && methods[e.Target] {
+			e.IsMock = true
+		}
+	}
+}
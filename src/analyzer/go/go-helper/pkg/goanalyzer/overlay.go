@@ -0,0 +1,79 @@
+package goanalyzer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// absOverlays returns Options.Overlays keyed by absolute path, as
+// packages.Config.Overlay expects.
+func (o Options) absOverlays() map[string][]byte {
+	if len(o.Overlays) == 0 {
+		return nil
+	}
+	absRoot, _ := filepath.Abs(o.ProjectRoot)
+	overlay := make(map[string][]byte, len(o.Overlays))
+	for path, content := range o.Overlays {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(absRoot, path)
+		}
+		overlay[filepath.Clean(path)] = []byte(content)
+	}
+	return overlay
+}
+
+// relOverlays returns Options.Overlays keyed like Options.Files: relative to
+// ProjectRoot and slash-separated.
+func (o Options) relOverlays() map[string][]byte {
+	if len(o.Overlays) == 0 {
+		return nil
+	}
+	absRoot, _ := filepath.Abs(o.ProjectRoot)
+	overlay := make(map[string][]byte, len(o.Overlays))
+	for path, content := range o.Overlays {
+		if filepath.IsAbs(path) {
+			rel, err := filepath.Rel(absRoot, path)
+			if err != nil {
+				continue
+			}
+			path = rel
+		}
+		overlay[cleanRel(path)] = []byte(content)
+	}
+	return overlay
+}
+
+// withOverlayFiles adds overlaid .go files that are not in Files yet, such
+// as new files that have never been saved.
+func (o Options) withOverlayFiles() Options {
+	overlay := o.relOverlays()
+	if len(overlay) == 0 {
+		return o
+	}
+	listed := make(map[string]bool, len(o.Files))
+	for _, f := range o.Files {
+		listed[cleanRel(f)] = true
+	}
+	var added []string
+	for path := range overlay {
+		if filepath.Ext(path) == ".go" && !listed[path] {
+			added = append(added, path)
+		}
+	}
+	sort.Strings(added)
+	o.Files = append(append([]string(nil), o.Files...), added...)
+	return o
+}
+
+func cleanRel(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// readSource reads a file, preferring its overlay content.
+func readSource(overlay map[string][]byte, absPath string) ([]byte, error) {
+	if content, ok := overlay[absPath]; ok {
+		return content, nil
+	}
+	return os.ReadFile(absPath)
+}
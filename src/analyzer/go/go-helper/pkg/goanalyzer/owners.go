@@ -0,0 +1,125 @@
+package goanalyzer
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ownerRule is one CODEOWNERS line: files matching the pattern belong to
+// the owners.
+type ownerRule struct {
+	match  func(string) bool
+	owners []string
+}
+
+// loadCodeOwners parses a CODEOWNERS file (GitHub/GitLab syntax). Patterns
+// follow gitignore rules: a leading "/" or an inner "/" anchors the pattern
+// at the project root, otherwise it matches at any depth, and a pattern
+// naming a directory covers everything below it. Section headers ("[Docs]")
+// are skipped. Lines without owners unassign files, as on GitHub.
+func loadCodeOwners(path string) ([]ownerRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ownerRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		rules = append(rules, ownerRule{match: codeOwnersMatcher(fields[0]), owners: fields[1:]})
+	}
+	return rules, scanner.Err()
+}
+
+// codeOwnersMatcher translates a CODEOWNERS pattern into exclude-style globs.
+func codeOwnersMatcher(pattern string) func(string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.HasPrefix(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	var globs []string
+	if !strings.HasSuffix(pattern, "*") {
+		globs = append(globs, pattern+"/**")
+	}
+	if !dirOnly {
+		globs = append(globs, pattern)
+	}
+	return globMatcher(globs)
+}
+
+// ownersOf returns the owners of a project-relative file: those of the last
+// matching rule.
+func ownersOf(rules []ownerRule, relPath string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].match(relPath) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// applyCodeOwners sets Node.Owners from the CODEOWNERS rules.
+func applyCodeOwners(g *Graph, rules []ownerRule) {
+	for i := range g.Nodes {
+		if g.Nodes[i].FilePath == "" {
+			continue
+		}
+		if owners := ownersOf(rules, g.Nodes[i].FilePath); len(owners) > 0 {
+			g.Nodes[i].Owners = owners
+		}
+	}
+}
+
+// teamDependencies counts the edges from functions of one team into
+// functions of another, for every pair of owners not shared by both sides.
+func teamDependencies(g *Graph) map[string]map[string]int {
+	owners := make(map[string][]string)
+	for _, n := range g.Nodes {
+		if len(n.Owners) > 0 {
+			owners[n.ID] = n.Owners
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	var matrix map[string]map[string]int
+	for _, e := range g.Edges {
+		for _, from := range owners[e.Source] {
+			for _, to := range owners[e.Target] {
+				if contains(owners[e.Target], from) || contains(owners[e.Source], to) {
+					continue
+				}
+				if matrix == nil {
+					matrix = make(map[string]map[string]int)
+				}
+				if matrix[from] == nil {
+					matrix[from] = make(map[string]int)
+				}
+				matrix[from][to]++
+			}
+		}
+	}
+	return matrix
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Defaults of ParameterListOptions.
+const (
+	defaultMaxParameters = 5
+	defaultMinGroupSize  = 3
+)
+
+// ParameterListOptions flags functions that would read better with a
+// parameter object: those taking more than MaxParameters parameters, and
+// groups of at least MinGroupSize parameters that sibling functions of a
+// package all take.
+type ParameterListOptions struct {
+	// MaxParameters is the most parameters a function may take before it
+	// is flagged (default 5).
+	MaxParameters int `json:"maxParameters,omitempty"`
+	// MinGroupSize is the fewest parameters, of the same names and types,
+	// that make a repeated group (default 3).
+	MinGroupSize int `json:"minGroupSize,omitempty"`
+}
+
+// checkParameterLists reports invalid Options.ParameterLists limits.
+func (o Options) checkParameterLists() error {
+	if o.ParameterLists == nil {
+		return nil
+	}
+	if o.ParameterLists.MaxParameters < 0 {
+		return fmt.Errorf("parameterLists: maxParameters must not be negative, not %d", o.ParameterLists.MaxParameters)
+	}
+	if o.ParameterLists.MinGroupSize < 0 {
+		return fmt.Errorf("parameterLists: minGroupSize must not be negative, not %d", o.ParameterLists.MinGroupSize)
+	}
+	return nil
+}
+
+// findParameterLists reports, as advisory findings, the project functions
+// of g taking more than MaxParameters parameters ("longParameterList"),
+// and the parameter groups shared by several functions of a package
+// ("parameterGroup"). A group is a set of parameters with the same names
+// and types, in any order; only the largest group shared by the same
+// functions is reported. context.Context parameters are left out of
+// groups, as they do not belong in a struct. Each finding lists its
+// functions in Nodes and the calls into them in Edges, the call sites a
+// refactoring has to update.
+func findParameterLists(g *Graph, opts ParameterListOptions) []Finding {
+	maxParams := opts.MaxParameters
+	if maxParams == 0 {
+		maxParams = defaultMaxParameters
+	}
+	minGroup := opts.MinGroupSize
+	if minGroup == 0 {
+		minGroup = defaultMinGroupSize
+	}
+	callsInto := make(map[string][]Edge)
+	for _, e := range g.Edges {
+		callsInto[e.Target] = append(callsInto[e.Target], e)
+	}
+	calls := func(ids []string) []Edge {
+		edges := []Edge{}
+		for _, id := range ids {
+			edges = append(edges, callsInto[id]...)
+		}
+		return edges
+	}
+
+	var findings []Finding
+	byPkg := make(map[string][]*Node)
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if n.Dependency || n.FilePath == "" || isTestFile(n.FilePath) || (n.Kind != "function" && n.Kind != "method") {
+			continue
+		}
+		if len(n.Parameters) > maxParams {
+			dir := path.Dir(n.FilePath)
+			findings = append(findings, Finding{
+				Kind:    "longParameterList",
+				Rule:    "parameterLists",
+				From:    dir,
+				To:      dir,
+				Message: fmt.Sprintf("%s takes %d parameters (more than %d); consider a parameter object", n.QualifiedName, len(n.Parameters), maxParams),
+				Edges:   calls([]string{n.ID}),
+				Nodes:   []string{n.ID},
+			})
+		}
+		byPkg[n.PackageOrModule] = append(byPkg[n.PackageOrModule], n)
+	}
+
+	for _, nodes := range byPkg {
+		params := make([]map[string]bool, len(nodes))
+		for i, n := range nodes {
+			params[i] = groupableParameters(*n)
+		}
+		// Candidate groups are the pairwise intersections; each is then
+		// shared by every function taking all of its parameters.
+		groups := make(map[string]parameterGroup)
+		for i := range nodes {
+			if len(params[i]) < minGroup {
+				continue
+			}
+			for j := i + 1; j < len(nodes); j++ {
+				var common []string
+				for p := range params[i] {
+					if params[j][p] {
+						common = append(common, p)
+					}
+				}
+				if len(common) < minGroup {
+					continue
+				}
+				sort.Strings(common)
+				key := strings.Join(common, ", ")
+				if _, ok := groups[key]; ok {
+					continue
+				}
+				var members []string
+				for k, n := range nodes {
+					if containsAll(params[k], common) {
+						members = append(members, n.ID)
+					}
+				}
+				sort.Strings(members)
+				groups[key] = parameterGroup{params: common, members: members}
+			}
+		}
+		for key, group := range groups {
+			if group.subsumed(groups) {
+				continue
+			}
+			dir := path.Dir(nodes[0].FilePath)
+			findings = append(findings, Finding{
+				Kind:    "parameterGroup",
+				Rule:    "parameterLists",
+				From:    dir,
+				To:      dir,
+				Message: fmt.Sprintf("%d functions of %s take (%s); consider grouping them in a struct", len(group.members), nodes[0].PackageOrModule, key),
+				Edges:   calls(group.members),
+				Nodes:   group.members,
+			})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Nodes[0] != b.Nodes[0] {
+			return a.Nodes[0] < b.Nodes[0]
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Message < b.Message
+	})
+	return findings
+}
+
+// groupableParameters returns the named parameters of n as "name type",
+// without blank, unnamed and context.Context ones.
+func groupableParameters(n Node) map[string]bool {
+	params := make(map[string]bool, len(n.Parameters))
+	for _, p := range n.Parameters {
+		if p.Name == "" || p.Name == "_" || p.Type == nil || *p.Type == "context.Context" {
+			continue
+		}
+		params[p.Name+" "+*p.Type] = true
+	}
+	return params
+}
+
+func containsAll(set map[string]bool, keys []string) bool {
+	for _, k := range keys {
+		if !set[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// parameterGroup is a set of parameters, as "name type" in sorted order,
+// and the IDs of the functions taking all of them.
+type parameterGroup struct {
+	params  []string
+	members []string
+}
+
+// subsumed reports whether a larger group of groups is shared by the same
+// functions.
+func (g parameterGroup) subsumed(groups map[string]parameterGroup) bool {
+	for _, other := range groups {
+		if len(other.params) <= len(g.params) || !slices.Equal(g.members, other.members) {
+			continue
+		}
+		set := make(map[string]bool, len(other.params))
+		for _, p := range other.params {
+			set[p] = true
+		}
+		if containsAll(set, g.params) {
+			return true
+		}
+	}
+	return false
+}
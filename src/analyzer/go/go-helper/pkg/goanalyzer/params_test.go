@@ -0,0 +1,58 @@
+package goanalyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// TestUnusedParametersTyped checks that type-aware analysis matches uses
+// of a parameter by object, not by name.
+func TestUnusedParametersTyped(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{"main.go": `package main
+
+type request struct {
+	name string
+}
+
+func trimmed(input string) string {
+	if input := "default"; input != "" {
+		return input
+	}
+	return ""
+}
+
+func requestName(name string, r request) string {
+	return r.name
+}
+
+func used(input string) string {
+	if input != "" {
+		input := input + "!"
+		return input
+	}
+	return input
+}
+
+func main() {
+	trimmed("")
+	requestName("", request{})
+	used("")
+}
+`}, goanalyzer.Options{})
+
+	unused := make(map[string][]string)
+	for _, n := range graph.Nodes {
+		unused[n.Name] = n.UnusedParameters
+	}
+	for name, want := range map[string][]string{
+		"trimmed":     {"input"},
+		"requestName": {"name"},
+		"used":        {},
+	} {
+		if got := unused[name]; !slices.Equal(got, want) {
+			t.Errorf("%s has unused parameters %v, want %v", name, got, want)
+		}
+	}
+}
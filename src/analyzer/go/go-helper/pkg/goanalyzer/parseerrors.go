@@ -0,0 +1,318 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic reports a problem with a project file that degraded its
+// analysis.
+type Diagnostic struct {
+	// Kind is "parseError" for files that do not parse, "anyDispatch" for
+	// functions over the Options.AnyDispatchLimit fan-out and
+	// "interfaceFanout" for interface calls over Options.MaxFanout,
+	// "truncated", without a file, for phases over Options.Budgets and
+	// "toolchain" when the Go toolchain cannot load the project for
+	// type-aware analysis (see checkToolchain).
+	Kind     string `json:"kind"`
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// parseErrorDiagnostic reports the first syntax error of a file.
+func parseErrorDiagnostic(relPath string, line, column int, msg string) Diagnostic {
+	return Diagnostic{Kind: "parseError", FilePath: filepath.ToSlash(relPath), Line: line, Column: column, Message: msg}
+}
+
+// diagnosticFromScanErr turns a go/parser error into a parseError diagnostic.
+func diagnosticFromScanErr(relPath string, err error) Diagnostic {
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return parseErrorDiagnostic(relPath, list[0].Pos.Line, list[0].Pos.Column, list[0].Msg)
+	}
+	return parseErrorDiagnostic(relPath, 0, 0, err.Error())
+}
+
+// brokenFile is a file of a loaded package that failed to parse.
+type brokenFile struct {
+	pkg        *packages.Package
+	absPath    string
+	diagnostic Diagnostic
+}
+
+var errorPos = regexp.MustCompile(`^(.*?):(\d+)(?::(\d+))?$`)
+
+// findBrokenFiles collects the files under absRoot with parse errors,
+// keyed by absolute path, keeping the first error of each. go list reports
+// positions relative to the package directory.
+func findBrokenFiles(pkgs []*packages.Package, absRoot string) map[string]*brokenFile {
+	broken := make(map[string]*brokenFile)
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			if e.Kind != packages.ParseError {
+				continue
+			}
+			m := errorPos.FindStringSubmatch(e.Pos)
+			if m == nil || !strings.HasSuffix(m[1], ".go") {
+				continue
+			}
+			absPath := m[1]
+			if !filepath.IsAbs(absPath) {
+				absPath = filepath.Join(packageDir(pkg, absRoot), absPath)
+			}
+			relPath, err := filepath.Rel(absRoot, absPath)
+			if err != nil || strings.HasPrefix(relPath, "..") || broken[absPath] != nil {
+				continue
+			}
+			line, _ := strconv.Atoi(m[2])
+			column, _ := strconv.Atoi(m[3])
+			broken[absPath] = &brokenFile{
+				pkg:        pkg,
+				absPath:    absPath,
+				diagnostic: parseErrorDiagnostic(relPath, line, column, e.Msg),
+			}
+		}
+	}
+	return broken
+}
+
+// isBrokenFileError reports whether e is located in one of the broken files;
+// type errors there are mostly fallout of the syntax error.
+func isBrokenFileError(e packages.Error, broken map[string]*brokenFile) bool {
+	m := errorPos.FindStringSubmatch(e.Pos)
+	return m != nil && broken[m[1]] != nil
+}
+
+func packageDir(pkg *packages.Package, fallback string) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
+		}
+	}
+	return fallback
+}
+
+// topLevelDecl matches the first line of a top-level declaration.
+var topLevelDecl = regexp.MustCompile(`^(func|type|var|const|import)\b`)
+
+// recoverFuncDecls makes a best-effort parse of a file that failed to parse.
+// go/parser gives up at the first error and folds the rest of the file into
+// the broken declaration, so every top-level func is re-parsed on its own,
+// from its "func" line to the next top-level declaration, with its original
+// line numbers. Functions already declared in partial (the parser's output,
+// may be nil) are skipped. The result holds the recovered FuncDecls, with
+// partial's imports and package name (pkgName if partial has none), or nil if
+// nothing was recovered.
+func recoverFuncDecls(fset *token.FileSet, absPath string, src []byte, partial *ast.File, pkgName string) *ast.File {
+	declared := make(map[string]bool)
+	if pkgName == "" {
+		pkgName = "main"
+	}
+	if partial != nil {
+		for _, decl := range partial.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				declared[funcDeclKey(fd)] = true
+			}
+		}
+		if hasPackageName(partial) {
+			pkgName = partial.Name.Name
+		}
+	}
+
+	recovered := &ast.File{Name: ast.NewIdent(pkgName)}
+	if partial != nil {
+		recovered.Imports = partial.Imports
+	}
+	lines := strings.Split(string(src), "\n")
+	for start := 0; start < len(lines); start++ {
+		if !strings.HasPrefix(lines[start], "func") || !topLevelDecl.MatchString(lines[start]) {
+			continue
+		}
+		end := start + 1
+		for end < len(lines) && !topLevelDecl.MatchString(lines[end]) {
+			end++
+		}
+		chunk := fmt.Sprintf("package %s;%s%s", pkgName, strings.Repeat("\n", start), strings.Join(lines[start:end], "\n"))
+		f, err := parser.ParseFile(fset, absPath, chunk, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && !declared[funcDeclKey(fd)] {
+				declared[funcDeclKey(fd)] = true
+				recovered.Decls = append(recovered.Decls, fd)
+			}
+		}
+		recovered.Comments = append(recovered.Comments, f.Comments...)
+		start = end - 1
+	}
+	if len(recovered.Decls) == 0 {
+		return nil
+	}
+	return recovered
+}
+
+// hasPackageName reports whether the parser got as far as a package name.
+func hasPackageName(f *ast.File) bool {
+	return f != nil && f.Name != nil && f.Name.Name != "" && f.Name.Name != "_"
+}
+
+// clampBrokenExtents fixes the line ranges of nodes from a file with syntax
+// errors, where the parser's partial output runs to the end of the file or
+// has no closing brace: each node ends before the next top-level
+// declaration.
+func clampBrokenExtents(nodes []Node, src []byte) {
+	lines := strings.Split(string(src), "\n")
+	for i := range nodes {
+		n := &nodes[i]
+		if n.StartLine < 1 || n.StartLine > len(lines) {
+			continue
+		}
+		end := n.StartLine
+		for end < len(lines) && !topLevelDecl.MatchString(lines[end]) {
+			end++
+		}
+		for end > n.StartLine && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		if n.EndLine < n.StartLine || n.EndLine > end {
+			n.EndLine = end
+			n.LinesOfCode = end - n.StartLine + 1
+			n.Sloc, n.CommentLines, n.BlankLines = countSourceLines(lines[n.StartLine-1 : end])
+		}
+	}
+}
+
+func funcDeclKey(fd *ast.FuncDecl) string {
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		return getReceiverTypeName(fd.Recv.List[0].Type) + "." + fd.Name.Name
+	}
+	return fd.Name.Name
+}
+
+// recoverBrokenFiles completes a type-aware graph for files with syntax
+// errors: the nodes that type-checked are flagged approximate, and functions
+// the parser lost are recovered syntactically, with call edges resolved by
+// name as in the AST-only path. It returns one diagnostic per broken file.
+func recoverBrokenFiles(broken map[string]*brokenFile, absRoot string, overlay map[string][]byte, nodes *[]Node, edges *[]Edge,
+	objToNodeID map[types.Object]string, shared *passShared, debug bool, stats *Stats) []Diagnostic {
+	paths := make([]string, 0, len(broken))
+	for p := range broken {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	funcMap := make(map[string]*Node, 2*len(*nodes))
+	for i := range *nodes {
+		n := &(*nodes)[i]
+		funcMap[n.ID] = n
+		funcMap[n.Name] = n
+	}
+
+	var diagnostics []Diagnostic
+	for _, absPath := range paths {
+		b := broken[absPath]
+		diagnostics = append(diagnostics, b.diagnostic)
+		relPath := b.diagnostic.FilePath
+		src, err := readSource(overlay, absPath)
+		if err != nil {
+			continue
+		}
+		for i := range *nodes {
+			if (*nodes)[i].FilePath == relPath {
+				(*nodes)[i].Approximate = true
+				clampBrokenExtents((*nodes)[i:i+1], src)
+			}
+		}
+
+		var partial *ast.File
+		for _, f := range b.pkg.Syntax {
+			if tf := b.pkg.Fset.File(f.FileStart); tf != nil && tf.Name() == absPath {
+				partial = f
+			}
+		}
+		rec := recoverFuncDecls(b.pkg.Fset, absPath, src, partial, b.pkg.Name)
+		if rec == nil {
+			continue
+		}
+		recovered := extractNodes(rec, b.pkg.Fset, relPath, b.pkg.Name, b.pkg.PkgPath)
+		annotateFileNodes(rec, b.pkg.Fset, recovered, shared)
+		for i := range recovered {
+			recovered[i].Approximate = true
+			funcMap[recovered[i].ID] = &recovered[i]
+			funcMap[recovered[i].Name] = &recovered[i]
+		}
+		*nodes = append(*nodes, recovered...)
+		*edges = append(*edges, extractEdges(rec, b.pkg.Fset, relPath, b.pkg.Name, funcMap, debug, stats)...)
+		*edges = append(*edges, linkRecoveredCallers(b.pkg, recovered, objToNodeID, absRoot, debug)...)
+	}
+	return diagnostics
+}
+
+// linkRecoveredCallers adds edges from the type-checked functions of pkg to
+// recovered functions they call by name, calls go/types left undefined.
+func linkRecoveredCallers(pkg *packages.Package, recovered []Node, objToNodeID map[types.Object]string, absRoot string, debug bool) []Edge {
+	byName := make(map[string]string)
+	for _, n := range recovered {
+		if n.Kind == "function" {
+			byName[n.Name] = n.ID
+		}
+	}
+	if len(byName) == 0 {
+		return nil
+	}
+
+	var edges []Edge
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			sourceID := objToNodeID[pkg.TypesInfo.Defs[fd.Name]]
+			if sourceID == "" {
+				continue
+			}
+			seen := make(map[string]bool)
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				ident, ok := call.Fun.(*ast.Ident)
+				if !ok || pkg.TypesInfo.Uses[ident] != nil {
+					return true
+				}
+				targetID, ok := byName[ident.Name]
+				if !ok || seen[targetID] {
+					return true
+				}
+				seen[targetID] = true
+				pos := pkg.Fset.Position(call.Pos())
+				relPath, _ := filepath.Rel(absRoot, pos.Filename)
+				edges = append(edges, Edge{
+					Source:     sourceID,
+					Target:     targetID,
+					CallSite:   CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column},
+					Kind:       "direct",
+					IsResolved: true,
+					Provenance: newProvenance("calls", ruleUndefinedName, call, debug),
+				})
+				return true
+			})
+		}
+	}
+	return edges
+}
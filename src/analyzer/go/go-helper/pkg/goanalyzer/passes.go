@@ -0,0 +1,506 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Optional metadata passes, enabled by name via Options.Passes. Each pass
+// inspects function bodies and records its findings in Node.Metadata; the
+// reverse indexes are assembled into Graph.Summary by buildSummary.
+const (
+	passSpans       = "spans"
+	passLogs        = "logs"
+	passMetrics     = "metrics"
+	passHTTP        = "http"
+	passConfig      = "config"
+	passAnnotations = "annotations"
+	passSQL         = "sql"
+	passQueues      = "queues"
+	passAllocs      = "allocs"
+	// passCLI records flag and subcommand definitions, and gathers those of
+	// each binary on its main function (see attachCLISurfaces).
+	passCLI = "cli"
+	// passDeadParams follows unused parameters to the calls passing them
+	// (type-aware analysis only; see deadparams.go).
+	passDeadParams = "deadparams"
+	// passLayers runs on the finished graph rather than on function bodies.
+	passLayers = "layers"
+	// passExamples collects call sites of exported functions from the
+	// finished graph (see collectUsageExamples).
+	passExamples = "examples"
+)
+
+// NodeMetadata holds the findings of optional passes for a single function.
+type NodeMetadata struct {
+	Spans      []string    `json:"spans,omitempty"`
+	Logs       []LogCall   `json:"logs,omitempty"`
+	Metrics    []string    `json:"metrics,omitempty"`
+	HTTPCalls  []HTTPCall  `json:"httpCalls,omitempty"`
+	ConfigKeys []ConfigKey `json:"configKeys,omitempty"`
+	SQLQueries []SQLQuery  `json:"sqlQueries,omitempty"`
+	QueueOps   []QueueOp   `json:"queueOps,omitempty"`
+	// AllocHotspots are the allocation-heavy patterns in loops found by
+	// the "allocs" pass.
+	AllocHotspots []AllocHotspot `json:"allocHotspots,omitempty"`
+	// Annotations are TODO/FIXME/HACK comments in the body; PanicsOnError
+	// marks must-style wrappers that turn errors into panics.
+	Annotations   []Annotation `json:"annotations,omitempty"`
+	PanicsOnError bool         `json:"panicsOnError,omitempty"`
+	// Layer is the architectural layer guessed by the "layers" pass: entry,
+	// handler, service, repository or util.
+	Layer string `json:"layer,omitempty"`
+	// RemovableParameters are the unused parameters the "deadparams" pass
+	// found safe to remove.
+	RemovableParameters []RemovableParameter `json:"removableParameters,omitempty"`
+	// CLIFlags and CLICommands are the flags and subcommands the "cli"
+	// pass found defined in the function or in the package-level
+	// variables it uses. They are not output: CLI gathers them for a
+	// binary on its main function, each with the function defining it.
+	CLIFlags    []CLIFlag    `json:"-"`
+	CLICommands []CLICommand `json:"-"`
+	CLI         *CLISurface  `json:"cli,omitempty"`
+	// UsageExamples are call sites of an exported function, found by the
+	// "examples" pass.
+	UsageExamples []UsageExample `json:"usageExamples,omitempty"`
+}
+
+// Summary holds output-wide indexes derived from node metadata.
+type Summary struct {
+	// Spans maps each tracing span name to the nodes that start it.
+	Spans map[string][]string `json:"spans,omitempty"`
+	// Metrics maps each Prometheus metric name to the nodes that touch it.
+	Metrics map[string][]string `json:"metrics,omitempty"`
+	// ConfigKeys maps "source:key" ("env:DATABASE_URL", "flag:port") to the
+	// nodes that read or define it.
+	ConfigKeys map[string][]string `json:"configKeys,omitempty"`
+	// Layers maps each guessed layer to its nodes.
+	Layers map[string][]string `json:"layers,omitempty"`
+	// TeamDependencies counts the calls from each CODEOWNERS owner's
+	// functions into each other owner's.
+	TeamDependencies map[string]map[string]int `json:"teamDependencies,omitempty"`
+	// LintSeverities counts the Node.LintIssues of each package by
+	// severity.
+	LintSeverities map[string]map[string]int `json:"lintSeverities,omitempty"`
+}
+
+type passSet map[string]bool
+
+func enabledPasses(input Options) passSet {
+	set := make(passSet, len(input.Passes))
+	for _, p := range input.Passes {
+		set[p] = true
+	}
+	return set
+}
+
+// passShared is project-wide state gathered by pre-scans before any function
+// body is inspected (e.g. which variables hold metric collectors).
+type passShared struct {
+	passes  passSet
+	metrics *metricBindings
+	cli     *cliBindings
+	config  *configBindings
+}
+
+func newPassShared(passes passSet) *passShared {
+	return &passShared{passes: passes, metrics: newMetricBindings(), cli: newCLIBindings(), config: newConfigBindings()}
+}
+
+// scanFile runs the pre-scans of the enabled passes over one file. All files
+// must be scanned before collectMetadata runs.
+func (s *passShared) scanFile(file *ast.File, ctx *passContext) {
+	if s.passes[passMetrics] {
+		scanMetricDefinitions(file, ctx)
+	}
+	if s.passes[passCLI] {
+		scanCLIDefinitions(file, ctx)
+	}
+	if s.passes[passConfig] {
+		scanConfigKeys(file, ctx)
+	}
+}
+
+// passContext carries what the passes need to interpret a function body.
+type passContext struct {
+	file *ast.File
+	fset *token.FileSet
+	info *types.Info // nil in AST-only mode
+	// imports maps local package names to import paths, so package-level
+	// calls can be recognized without type information.
+	imports map[string]string
+	// consts holds the file's package-level string constants, so AST-only
+	// mode can resolve route or URL constants declared alongside their use.
+	consts map[string]string
+	shared *passShared
+}
+
+func newPassContext(file *ast.File, fset *token.FileSet, info *types.Info, shared *passShared) *passContext {
+	imports := make(map[string]string)
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := defaultImportName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+	consts := make(map[string]string)
+	if info == nil {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, v := range vs.Values {
+					lit, ok := v.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING || i >= len(vs.Names) {
+						continue
+					}
+					if s, err := strconv.Unquote(lit.Value); err == nil {
+						consts[vs.Names[i].Name] = s
+					}
+				}
+			}
+		}
+	}
+	return &passContext{file: file, fset: fset, info: info, imports: imports, consts: consts, shared: shared}
+}
+
+// defaultImportName guesses the package name of an import path:
+// "github.com/rs/zerolog/log" → "log", "github.com/foo/bar/v2" → "bar",
+// "gopkg.in/yaml.v3" → "yaml".
+func defaultImportName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && isDigits(name[1:]) {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 && isDigits(name[i+2:]) {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// callTarget describes the callee of a call expression as far as it can be
+// determined: the declaring package path, the receiver type name for methods
+// and the function name. recvExpr is the receiver expression for method calls,
+// used by AST-only heuristics when the receiver type is unknown.
+type callTarget struct {
+	pkgPath  string
+	recv     string
+	name     string
+	recvExpr ast.Expr
+}
+
+func (c *passContext) resolveCall(call *ast.CallExpr) (callTarget, bool) {
+	switch fn := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if c.info != nil {
+			if f, ok := c.info.Uses[fn].(*types.Func); ok {
+				return typedCallTarget(f, nil), true
+			}
+		}
+		return callTarget{name: fn.Name}, true
+
+	case *ast.SelectorExpr:
+		if c.info != nil {
+			if f, ok := c.info.Uses[fn.Sel].(*types.Func); ok {
+				if _, isPkg := c.info.Uses[identOf(fn.X)].(*types.PkgName); isPkg {
+					return typedCallTarget(f, nil), true
+				}
+				return typedCallTarget(f, fn.X), true
+			}
+			return callTarget{}, false
+		}
+		if ident, ok := fn.X.(*ast.Ident); ok {
+			if path, isPkg := c.imports[ident.Name]; isPkg {
+				return callTarget{pkgPath: path, name: fn.Sel.Name}, true
+			}
+		}
+		return callTarget{name: fn.Sel.Name, recvExpr: fn.X}, true
+	}
+	return callTarget{}, false
+}
+
+func typedCallTarget(f *types.Func, recvExpr ast.Expr) callTarget {
+	t := callTarget{name: f.Name(), recvExpr: recvExpr}
+	if f.Pkg() != nil {
+		t.pkgPath = f.Pkg().Path()
+	}
+	if sig, ok := f.Type().(*types.Signature); ok && sig.Recv() != nil {
+		recv := sig.Recv().Type()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		if named, ok := recv.(*types.Named); ok {
+			t.recv = named.Obj().Name()
+		}
+	}
+	return t
+}
+
+func identOf(e ast.Expr) *ast.Ident {
+	id, _ := e.(*ast.Ident)
+	return id
+}
+
+// inPackage reports whether the callee is declared in a package whose import
+// path equals or starts with one of the given prefixes.
+func (t callTarget) inPackage(prefixes ...string) bool {
+	for _, p := range prefixes {
+		if t.pkgPath == p || strings.HasPrefix(t.pkgPath, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// recvText renders the receiver expression ("s.tracer", "otel.Tracer(...)")
+// for name-based matching in AST-only mode.
+func (t callTarget) recvText() string {
+	if t.recvExpr == nil {
+		return ""
+	}
+	return exprText(t.recvExpr)
+}
+
+func exprText(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprText(x.X) + "." + x.Sel.Name
+	case *ast.CallExpr:
+		return exprText(x.Fun) + "(...)"
+	case *ast.StarExpr:
+		return "*" + exprText(x.X)
+	case *ast.ParenExpr:
+		return exprText(x.X)
+	case *ast.IndexExpr:
+		return exprText(x.X) + "[...]"
+	}
+	return ""
+}
+
+// constString returns the value of a constant string expression: a literal or
+// file-level constant in AST-only mode, or any typed constant expression when
+// types are available.
+func (c *passContext) constString(e ast.Expr) (string, bool) {
+	if c.info != nil {
+		if tv, ok := c.info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value), true
+		}
+	}
+	switch x := ast.Unparen(e).(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.STRING {
+			s, err := strconv.Unquote(x.Value)
+			return s, err == nil
+		}
+	case *ast.Ident:
+		if c.info == nil {
+			s, ok := c.consts[x.Name]
+			return s, ok
+		}
+	}
+	return "", false
+}
+
+// constPrefix returns the constant leading part of a string expression:
+// the whole constant, the left operand of a concatenation, or the format of
+// fmt.Sprintf. For format strings the prefix stops at the first verb.
+func (c *passContext) constPrefix(e ast.Expr, formatted bool) string {
+	if s, ok := c.constString(e); ok {
+		if formatted {
+			s = formatPrefix(s)
+		}
+		return s
+	}
+	switch x := ast.Unparen(e).(type) {
+	case *ast.BinaryExpr:
+		if x.Op == token.ADD {
+			return c.constPrefix(x.X, false)
+		}
+	case *ast.CallExpr:
+		if t, ok := c.resolveCall(x); ok && t.pkgPath == "fmt" && strings.HasPrefix(t.name, "Sprint") && len(x.Args) > 0 {
+			return c.constPrefix(x.Args[0], t.name == "Sprintf")
+		}
+	}
+	return ""
+}
+
+// formatPrefix cuts a printf format at its first verb ("%%" is kept literal).
+func formatPrefix(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			b.WriteByte('%')
+			i++
+			continue
+		}
+		break
+	}
+	return b.String()
+}
+
+// collectMetadata runs the enabled passes over a function body and attaches
+// the findings to the node.
+func collectMetadata(node *Node, funcDecl *ast.FuncDecl, ctx *passContext) {
+	passes := ctx.shared.passes
+	if len(passes) == 0 || funcDecl.Body == nil {
+		return
+	}
+
+	var md NodeMetadata
+	if passes[passSpans] {
+		md.Spans = collectSpans(funcDecl.Body, ctx)
+	}
+	if passes[passLogs] {
+		md.Logs = collectLogCalls(funcDecl.Body, ctx)
+	}
+	if passes[passMetrics] {
+		md.Metrics = collectMetrics(funcDecl.Body, ctx)
+	}
+	if passes[passHTTP] {
+		md.HTTPCalls = collectHTTPCalls(funcDecl.Body, ctx)
+	}
+	if passes[passConfig] {
+		md.ConfigKeys = collectConfigKeys(funcDecl.Body, ctx)
+	}
+	if passes[passSQL] {
+		md.SQLQueries = collectSQLQueries(funcDecl.Body, ctx)
+	}
+	if passes[passQueues] {
+		md.QueueOps = collectQueueOps(funcDecl.Body, ctx)
+	}
+	if passes[passAllocs] {
+		md.AllocHotspots = collectAllocHotspots(funcDecl.Body, ctx)
+	}
+	if passes[passAnnotations] {
+		md.Annotations = collectAnnotations(funcDecl.Body, ctx)
+		md.PanicsOnError = panicsOnError(funcDecl.Body, ctx)
+	}
+	if passes[passCLI] {
+		md.CLIFlags, md.CLICommands = collectCLIDefinitions(funcDecl.Body, ctx)
+	}
+
+	if !md.isEmpty() {
+		node.Metadata = &md
+	}
+}
+
+func (md *NodeMetadata) isEmpty() bool {
+	return len(md.Spans) == 0 && len(md.Logs) == 0 && len(md.Metrics) == 0 &&
+		len(md.HTTPCalls) == 0 && len(md.ConfigKeys) == 0 &&
+		len(md.SQLQueries) == 0 && len(md.QueueOps) == 0 && len(md.AllocHotspots) == 0 &&
+		len(md.Annotations) == 0 && !md.PanicsOnError && md.Layer == "" &&
+		len(md.RemovableParameters) == 0 && len(md.CLIFlags) == 0 && len(md.CLICommands) == 0 &&
+		md.CLI == nil && len(md.UsageExamples) == 0
+}
+
+// annotateFileNodes runs collectMetadata for each function declared in file.
+// nodes must hold one entry per FuncDecl in declaration order, as produced by
+// extractNodes.
+func annotateFileNodes(file *ast.File, fset *token.FileSet, nodes []Node, shared *passShared) {
+	if len(shared.passes) == 0 {
+		return
+	}
+	ctx := newPassContext(file, fset, nil, shared)
+	i := 0
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if i >= len(nodes) {
+			return
+		}
+		collectMetadata(&nodes[i], funcDecl, ctx)
+		i++
+	}
+}
+
+// buildSummary assembles the reverse indexes over node metadata and the
+// team dependency matrix.
+func buildSummary(g *Graph) *Summary {
+	s := Summary{TeamDependencies: teamDependencies(g), LintSeverities: lintSeverities(g)}
+	for _, n := range g.Nodes {
+		if n.Metadata == nil {
+			continue
+		}
+		s.Spans = indexNode(s.Spans, n.Metadata.Spans, n.ID)
+		s.Metrics = indexNode(s.Metrics, n.Metadata.Metrics, n.ID)
+		for _, ck := range n.Metadata.ConfigKeys {
+			s.ConfigKeys = indexNode(s.ConfigKeys, []string{ck.Source + ":" + ck.Key}, n.ID)
+		}
+		if n.Metadata.Layer != "" {
+			s.Layers = indexNode(s.Layers, []string{n.Metadata.Layer}, n.ID)
+		}
+	}
+	if s.Spans == nil && s.Metrics == nil && s.ConfigKeys == nil && s.Layers == nil && s.TeamDependencies == nil && s.LintSeverities == nil {
+		return nil
+	}
+	return &s
+}
+
+// indexNode adds nodeID under each key of a reverse index, creating it lazily.
+func indexNode(index map[string][]string, keys []string, nodeID string) map[string][]string {
+	for _, k := range keys {
+		if index == nil {
+			index = make(map[string][]string)
+		}
+		index[k] = appendUnique(index[k], nodeID)
+	}
+	return index
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+// sortedUnique sorts and deduplicates a list of strings in place.
+func sortedUnique(list []string) []string {
+	if len(list) == 0 {
+		return list
+	}
+	sort.Strings(list)
+	out := list[:1]
+	for _, v := range list[1:] {
+		if v != out[len(out)-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,203 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Path modes of Options.PathMode.
+const (
+	PathModeRelative   = "relative"
+	PathModeModulePath = "modulePath"
+	PathModeAbsolute   = "absolute"
+)
+
+func (o Options) checkPathMode() error {
+	switch o.PathMode {
+	case "", PathModeRelative, PathModeAbsolute:
+		return nil
+	case PathModeModulePath:
+		if o.Module == "" {
+			return fmt.Errorf("pathMode %q needs module", o.PathMode)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown pathMode %q (want %q, %q or %q)", o.PathMode, PathModeRelative, PathModeModulePath, PathModeAbsolute)
+}
+
+// PathMapping relates the path of a directory as the orchestrator running
+// the helper sees it to its path inside the helper's environment, such as a
+// project mounted into a container or a remote host.
+type PathMapping struct {
+	Orchestrator string `json:"orchestrator"`
+	Helper       string `json:"helper"`
+}
+
+// mapPrefix replaces the longest of the from directories that contains p
+// with the matching to directory.
+func mapPrefix(p string, mappings []PathMapping, from, to func(PathMapping) string) string {
+	longest, mapped := -1, p
+	for _, m := range mappings {
+		dir := strings.TrimSuffix(filepath.ToSlash(from(m)), "/")
+		if (p == dir || strings.HasPrefix(p, dir+"/")) && len(dir) > longest {
+			longest = len(dir)
+			mapped = strings.TrimSuffix(filepath.ToSlash(to(m)), "/") + p[len(dir):]
+		}
+	}
+	return mapped
+}
+
+func orchestratorDir(m PathMapping) string { return m.Orchestrator }
+func helperDir(m PathMapping) string       { return m.Helper }
+
+// MapPaths translates the absolute paths of the options, given in the
+// orchestrator's view, into the helper's with PathMappings. Relative paths
+// are relative to ProjectRoot and need no translation. Analyze, Reanalyze
+// and WriteReports map their options; mapping them again changes nothing,
+// so paths set after MapPaths are taken to be in the helper's view.
+func (o Options) MapPaths() Options {
+	if len(o.PathMappings) == 0 || o.pathsMapped {
+		return o
+	}
+	o.pathsMapped = true
+	toHelper := func(p string) string {
+		if !filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.FromSlash(mapPrefix(filepath.ToSlash(p), o.PathMappings, orchestratorDir, helperDir))
+	}
+	each := func(paths []string) []string {
+		if paths == nil {
+			return nil
+		}
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			out[i] = toHelper(p)
+		}
+		return out
+	}
+	o.ProjectRoot = toHelper(o.ProjectRoot)
+	o.Files = each(o.Files)
+	o.FilesFrom = toHelper(o.FilesFrom)
+	o.Pprof = toHelper(o.Pprof)
+	o.LintReports = each(o.LintReports)
+	o.ExecutionLog = toHelper(o.ExecutionLog)
+	o.CodeOwners = toHelper(o.CodeOwners)
+	o.Baseline = toHelper(o.Baseline)
+	o.ReportDir = toHelper(o.ReportDir)
+	o.BuildInfo = toHelper(o.BuildInfo)
+	o.PackagesDriver = toHelper(o.PackagesDriver)
+	o.ExternalGraphs = each(o.ExternalGraphs)
+	if o.Overlays != nil {
+		overlays := make(map[string]string, len(o.Overlays))
+		for p, content := range o.Overlays {
+			overlays[toHelper(p)] = content
+		}
+		o.Overlays = overlays
+	}
+	return o
+}
+
+// filePathMapper returns the function giving the path to emit for a
+// project-relative file under PathMode, PathMappings (back to the
+// orchestrator's view) and PathPrefixes, or nil if paths are emitted as
+// they are.
+func (o Options) filePathMapper() func(string) string {
+	var base string
+	switch o.PathMode {
+	case PathModeModulePath:
+		base = o.Module
+	case PathModeAbsolute:
+		absRoot, _ := filepath.Abs(o.ProjectRoot)
+		base = filepath.ToSlash(absRoot)
+	}
+	if base == "" && len(o.PathPrefixes) == 0 {
+		return nil
+	}
+	if base != "" && len(o.PathMappings) > 0 {
+		base = mapPrefix(base, o.PathMappings, helperDir, orchestratorDir)
+	}
+	// The longest matching prefix wins.
+	prefixes := make([]string, 0, len(o.PathPrefixes))
+	for p := range o.PathPrefixes {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(a, b int) bool { return len(prefixes[a]) > len(prefixes[b]) })
+	return func(p string) string {
+		if base != "" {
+			p = path.Join(base, p)
+		}
+		for _, prefix := range prefixes {
+			if rest, ok := strings.CutPrefix(p, prefix); ok {
+				return o.PathPrefixes[prefix] + rest
+			}
+		}
+		return p
+	}
+}
+
+// mapFilePaths rewrites the file paths of the project's files in g with
+// Options.PathMode and Options.PathPrefixes. Node IDs keep their
+// project-relative paths, so graphs emitted with different modes still
+// diff and merge; the paths of Dependency nodes, which start with an
+// import path, are left alone.
+func (o Options) mapFilePaths(g *Graph) {
+	mapPath := o.filePathMapper()
+	if mapPath == nil {
+		return
+	}
+	external := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if n.Dependency {
+			external[n.FilePath] = true
+		}
+	}
+	m := func(p *string) {
+		if *p != "" && !external[*p] {
+			*p = mapPath(*p)
+		}
+	}
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		m(&n.FilePath)
+		for j := range n.EmbeddedFiles {
+			m(&n.EmbeddedFiles[j])
+		}
+	}
+	for i := range g.Edges {
+		m(&g.Edges[i].CallSite.FilePath)
+	}
+	for i := range g.Findings {
+		for j := range g.Findings[i].Edges {
+			m(&g.Findings[i].Edges[j].CallSite.FilePath)
+		}
+	}
+	for i := range g.Diagnostics {
+		m(&g.Diagnostics[i].FilePath)
+	}
+	for i := range g.References {
+		m(&g.References[i].FilePath)
+	}
+	for i := range g.Routes {
+		m(&g.Routes[i].CallSite.FilePath)
+	}
+	for i := range g.LinkerVars {
+		m(&g.LinkerVars[i].FilePath)
+	}
+	if g.InitGraph != nil {
+		for i := range g.InitGraph.Steps {
+			m(&g.InitGraph.Steps[i].FilePath)
+		}
+	}
+	if g.InterfaceGaps != nil {
+		for i := range g.InterfaceGaps.NearMisses {
+			m(&g.InterfaceGaps.NearMisses[i].FilePath)
+		}
+		for i := range g.InterfaceGaps.Unimplemented {
+			m(&g.InterfaceGaps.Unimplemented[i].FilePath)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// goPluginPath is the import path of hashicorp/go-plugin, whose Serve call
+// exposes the plugin implementations it is given over RPC.
+const goPluginPath = "github.com/hashicorp/go-plugin"
+
+// detectPlugins finds code reached only through a plugin protocol
+// (type-aware analysis only):
+//
+//   - the exported functions of a package main without a main function,
+//     built with -buildmode=plugin and looked up by name, become entry
+//     points registered by "plugin";
+//   - the exported methods of the project types passed, directly or in
+//     composite literals, to go-plugin's plugin.Serve become entry points
+//     registered by "go-plugin", with a "plugin" edge from the caller;
+//   - each (*plugin.Plugin).Lookup of a constant symbol name gets an
+//     "external:plugin:<name>" boundary node with a "plugin" edge from the
+//     caller, and an edge from the boundary node to the project plugin
+//     functions of that name.
+//
+// It returns the boundary nodes.
+func detectPlugins(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, nodes []Node, edges *[]Edge, debug bool) []Node {
+	reg := newRegistrar(nodes, objToNodeID)
+
+	exports := make(map[string][]string)
+	for _, pkg := range pkgs {
+		if pkg.Name != "main" || pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		if _, ok := scope.Lookup("main").(*types.Func); ok {
+			continue
+		}
+		for _, name := range scope.Names() {
+			if fn, ok := scope.Lookup(name).(*types.Func); ok && fn.Exported() {
+				if id, ok := objToNodeID[fn]; ok {
+					reg.register(id, "plugin")
+					exports[name] = append(exports[name], id)
+				}
+			}
+		}
+	}
+
+	boundaries := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+				sourceID := objToNodeID[pkg.TypesInfo.Defs[funcDecl.Name]]
+				if sourceID == "" {
+					continue
+				}
+				seen := make(map[string]bool)
+				addEdge := func(target, rule string, call *ast.CallExpr) {
+					if seen[target] {
+						return
+					}
+					seen[target] = true
+					pos := pkg.Fset.Position(call.Pos())
+					*edges = append(*edges, Edge{
+						Source:     sourceID,
+						Target:     target,
+						CallSite:   CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column},
+						Kind:       "plugin",
+						IsResolved: true,
+						Provenance: newProvenance("plugins", rule, call, debug),
+					})
+				}
+				ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					callee := calleeFunc(call, pkg.TypesInfo)
+					if callee == nil || callee.Pkg() == nil {
+						return true
+					}
+					switch {
+					case callee.Pkg().Path() == goPluginPath && callee.Name() == "Serve":
+						for _, arg := range call.Args {
+							for _, id := range servedMethods(arg, pkg.TypesInfo, reg) {
+								addEdge(id, rulePluginServe, call)
+							}
+						}
+					case callee.Pkg().Path() == "plugin" && callee.Name() == "Lookup" && len(call.Args) == 1:
+						tv, ok := pkg.TypesInfo.Types[call.Args[0]]
+						if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+							return true
+						}
+						name := constant.StringVal(tv.Value)
+						id := "external:plugin:" + name
+						boundaries[name] = true
+						addEdge(id, rulePluginLookup, call)
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(boundaries))
+	for name := range boundaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	boundaryNodes := make([]Node, 0, len(names))
+	for _, name := range names {
+		id := "external:plugin:" + name
+		boundaryNodes = append(boundaryNodes, externalNode(id, name, "plugin"))
+		for _, target := range exports[name] {
+			*edges = append(*edges, Edge{
+				Source:     id,
+				Target:     target,
+				Kind:       "plugin",
+				IsResolved: true,
+				Provenance: Provenance{Phase: "plugins", Rule: rulePluginSymbol},
+			})
+		}
+	}
+	return boundaryNodes
+}
+
+// servedMethods registers the exported project methods of the values in a
+// plugin.Serve argument, looking into composite literals (ServeConfig, the
+// plugin map) and the address-of values in them.
+func servedMethods(arg ast.Expr, info *types.Info, reg *registrar) []string {
+	var ids []string
+	ast.Inspect(arg, func(n ast.Node) bool {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		if _, ok := expr.(*ast.KeyValueExpr); ok {
+			return true
+		}
+		if tv, ok := info.Types[expr]; ok && tv.IsValue() {
+			ids = append(ids, reg.registerMethods(tv.Type, "go-plugin", token.IsExported)...)
+		}
+		return true
+	})
+	return ids
+}
@@ -0,0 +1,95 @@
+package goanalyzer
+
+import "fmt"
+
+// FailOn sets the thresholds of a CI policy (Options.FailOn). A check fails
+// when its measure exceeds the threshold; unset thresholds are not checked.
+type FailOn struct {
+	// NewDeadFunctions bounds the dead functions missing from
+	// Options.Baseline, or all dead functions without a baseline.
+	NewDeadFunctions *int `json:"newDeadFunctions,omitempty"`
+	// UnresolvedEdgesPercent bounds the share of calls left unresolved
+	// (Stats.UnresolvedCalls) among all resolved edges and unresolved calls.
+	UnresolvedEdgesPercent *float64 `json:"unresolvedEdgesPercent,omitempty"`
+}
+
+// Policy is the evaluation of Options.FailOn against the graph.
+type Policy struct {
+	Passed bool          `json:"passed"`
+	Checks []PolicyCheck `json:"checks"`
+}
+
+// PolicyCheck is one threshold of FailOn and the measured value.
+type PolicyCheck struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+	Message   string  `json:"message"`
+}
+
+// Policy check names, as in FailOn's JSON.
+const (
+	checkNewDeadFunctions       = "newDeadFunctions"
+	checkUnresolvedEdgesPercent = "unresolvedEdgesPercent"
+)
+
+// newPolicy returns the checks configured by f, not yet evaluated, or nil.
+func newPolicy(f *FailOn) *Policy {
+	if f == nil {
+		return nil
+	}
+	p := &Policy{Checks: []PolicyCheck{}}
+	if f.NewDeadFunctions != nil {
+		p.Checks = append(p.Checks, PolicyCheck{Name: checkNewDeadFunctions, Threshold: float64(*f.NewDeadFunctions)})
+	}
+	if f.UnresolvedEdgesPercent != nil {
+		p.Checks = append(p.Checks, PolicyCheck{Name: checkUnresolvedEdgesPercent, Threshold: *f.UnresolvedEdgesPercent})
+	}
+	return p
+}
+
+// evaluate measures each check on g. It is called again whenever the graph
+// is narrowed or updated, so it only depends on the graph.
+func (p *Policy) evaluate(g *Graph) {
+	if p == nil {
+		return
+	}
+	p.Passed = true
+	for i := range p.Checks {
+		c := &p.Checks[i]
+		switch c.Name {
+		case checkNewDeadFunctions:
+			if g.DeadCode != nil {
+				c.Actual = float64(len(g.DeadCode.New))
+				c.Message = fmt.Sprintf("%d dead functions not in the baseline (limit %g)", len(g.DeadCode.New), c.Threshold)
+			} else {
+				n := len(deadFunctions(g))
+				c.Actual = float64(n)
+				c.Message = fmt.Sprintf("%d dead functions (limit %g; no baseline)", n, c.Threshold)
+			}
+		case checkUnresolvedEdgesPercent:
+			c.Actual = unresolvedPercent(g)
+			c.Message = fmt.Sprintf("%.1f%% of calls unresolved (limit %g%%)", c.Actual, c.Threshold)
+		}
+		c.Passed = c.Actual <= c.Threshold
+		p.Passed = p.Passed && c.Passed
+	}
+}
+
+func unresolvedPercent(g *Graph) float64 {
+	if g.Stats == nil {
+		return 0
+	}
+	resolved := 0
+	for _, e := range g.Edges {
+		if e.IsResolved {
+			resolved++
+		}
+	}
+	total := resolved + g.Stats.UnresolvedCalls
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(g.Stats.UnresolvedCalls) / float64(total)
+}
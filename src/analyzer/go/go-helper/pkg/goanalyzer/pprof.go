@@ -0,0 +1,160 @@
+package goanalyzer
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// ProfileStats are pprof sample values joined onto a node or edge.
+// Times are in nanoseconds; AllocBytes comes from heap profiles (alloc_space).
+// On edges, CumTime and AllocBytes are the values of samples in which the
+// caller directly invoked the callee.
+type ProfileStats struct {
+	SelfTime   int64 `json:"selfTime,omitempty"`
+	CumTime    int64 `json:"cumTime,omitempty"`
+	AllocBytes int64 `json:"allocBytes,omitempty"`
+}
+
+// closureSuffix matches the compiler-generated suffixes of closures, go/defer
+// wrappers and numbered init functions ("F.func1.2", "init.0", "M.gowrap1").
+var closureSuffix = regexp.MustCompile(`(\.(func|gowrap|deferwrap)\d+|\.\d+)+$`)
+
+// applyProfile reads a pprof profile and joins its samples onto the output.
+// Samples in closures are attributed to the enclosing declared function.
+func applyProfile(output *Graph, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	prof, err := profile.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	timeIdx, allocIdx := -1, -1
+	for i, st := range prof.SampleType {
+		switch {
+		case st.Unit == "nanoseconds" && timeIdx < 0:
+			timeIdx = i
+		case st.Type == "alloc_space":
+			allocIdx = i
+		}
+	}
+
+	bySymbol := make(map[string]int, len(output.Nodes))
+	for i, n := range output.Nodes {
+		if n.SymbolID != "" {
+			bySymbol[n.SymbolID] = i
+		}
+	}
+	edgeIdx := make(map[[2]int]int, len(output.Edges))
+	nodeIdx := make(map[string]int, len(output.Nodes))
+	for i, n := range output.Nodes {
+		nodeIdx[n.ID] = i
+	}
+	for i, e := range output.Edges {
+		src, okS := nodeIdx[e.Source]
+		dst, okD := nodeIdx[e.Target]
+		if !okS || !okD {
+			continue
+		}
+		if _, dup := edgeIdx[[2]int{src, dst}]; !dup {
+			edgeIdx[[2]int{src, dst}] = i
+		}
+	}
+
+	nodeStats := make(map[int]*ProfileStats)
+	edgeStats := make(map[int]*ProfileStats)
+	statsFor := func(m map[int]*ProfileStats, i int) *ProfileStats {
+		if m[i] == nil {
+			m[i] = &ProfileStats{}
+		}
+		return m[i]
+	}
+
+	for _, sample := range prof.Sample {
+		var t, alloc int64
+		if timeIdx >= 0 {
+			t = sample.Value[timeIdx]
+		}
+		if allocIdx >= 0 {
+			alloc = sample.Value[allocIdx]
+		}
+
+		// Expand the stack leaf-first, including inlined frames, mapped to node
+		// indices (-1 for frames outside the project).
+		var frames []int
+		for _, loc := range sample.Location {
+			for _, line := range loc.Line {
+				if line.Function == nil {
+					continue
+				}
+				idx, ok := bySymbol[normalizeProfileSymbol(line.Function.Name)]
+				if !ok {
+					idx = -1
+				}
+				frames = append(frames, idx)
+			}
+		}
+		if len(frames) == 0 {
+			continue
+		}
+
+		if frames[0] >= 0 {
+			st := statsFor(nodeStats, frames[0])
+			st.SelfTime += t
+			st.AllocBytes += alloc
+		}
+
+		seenNode := make(map[int]bool)
+		seenEdge := make(map[int]bool)
+		for i, idx := range frames {
+			if idx < 0 {
+				continue
+			}
+			if !seenNode[idx] {
+				seenNode[idx] = true
+				statsFor(nodeStats, idx).CumTime += t
+			}
+			if i == 0 {
+				continue
+			}
+			callee := frames[i-1]
+			if callee < 0 || callee == idx {
+				continue
+			}
+			ei, ok := edgeIdx[[2]int{idx, callee}]
+			if !ok || seenEdge[ei] {
+				continue
+			}
+			seenEdge[ei] = true
+			st := statsFor(edgeStats, ei)
+			st.CumTime += t
+			st.AllocBytes += alloc
+		}
+	}
+
+	for i, st := range nodeStats {
+		if *st != (ProfileStats{}) {
+			output.Nodes[i].Profile = st
+		}
+	}
+	for i, st := range edgeStats {
+		if *st != (ProfileStats{}) {
+			output.Edges[i].Profile = st
+		}
+	}
+	return nil
+}
+
+// normalizeProfileSymbol maps a pprof function name to the linker symbol of
+// its declaring function: closure suffixes and generic type arguments are removed.
+func normalizeProfileSymbol(name string) string {
+	name = strings.ReplaceAll(name, "[...]", "")
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,84 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"unicode/utf8"
+)
+
+// Provenance records which analysis phase, and which rule within it,
+// produced an edge. Phases are named as in Stats.Phases, plus "merge" for
+// edges linked by Merge.
+type Provenance struct {
+	Phase string `json:"phase"`
+	Rule  string `json:"rule"`
+	// Snippet is the source expression that triggered the edge; it is only
+	// recorded with Options.Debug.
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Provenance rules. Type-aware call resolution ("calls" phase):
+const (
+	ruleCall              = "call"               // foo()
+	rulePackageCall       = "package-call"       // pkg.Func()
+	ruleMethodCall        = "method-call"        // x.Method() on a concrete type
+	ruleInterfaceDispatch = "interface-dispatch" // x.Method() on an interface, one edge per implementation
+	ruleTypeAssertion     = "type-assertion"     // x.(T).Method(), or v.Method() on v bound by an assertion or type switch
+	ruleInterfaceFanout   = "interface-fanout"   // x.Method() on an interface with more than Options.MaxFanout implementations
+	ruleMethodValue       = "method-value"       // x.Method passed as a value
+	ruleFuncValue         = "func-value"         // foo passed as a value
+	ruleOutOfScope        = "out-of-scope"       // Options.KeepUnresolved
+)
+
+// AST-only call resolution ("calls" phase, also used for files with syntax
+// errors and by Reanalyze):
+const (
+	ruleSameFileName = "same-file-name" // name declared in the calling file
+	ruleProjectName  = "project-name"   // name declared elsewhere in the project
+)
+
+// Other phases:
+const (
+	ruleVarInitRef       = "var-init-ref"      // "varinit": function referenced in a package-level initializer
+	ruleConcreteReturn   = "concrete-return"   // "constructors": method of the returned concrete type
+	ruleInterfaceReturn  = "interface-return"  // "constructors": method of an implementation of the returned interface
+	ruleAnyReturn        = "any-return"        // "constructors": returned empty interface (Options.AnyDispatch)
+	ruleGeneratedMethod  = "generated-method"  // "constructors": method behind a large generated type's node (Options.MaxGeneratedMethods)
+	ruleUndefinedName    = "undefined-name"    // "calls": type-checked caller of a function lost to a syntax error
+	ruleExternalEndpoint = "external-endpoint" // "postprocess": outbound HTTP call (Options.ExternalEdges)
+	ruleSymbolLink       = "symbol-link"       // "merge": unresolved edge linked by SymbolID
+	ruleRegistryCall     = "registry-call"     // "registry": function passed to a registration call (Options.RegistryRules)
+	ruleMiddlewareChain  = "middleware-chain"  // "registry" or "routes": handler at the end of a middleware chain passed to a registration call
+	rulePluginServe      = "plugin-serve"      // "plugins": method of a type served by go-plugin's plugin.Serve
+	rulePluginLookup     = "plugin-lookup"     // "plugins": plugin.Lookup of a constant symbol name
+	rulePluginSymbol     = "plugin-symbol"     // "plugins": project plugin function of a looked-up symbol name
+	ruleExternalGraph    = "external-graph"    // "calls": call into a function of Options.ExternalGraphs
+	ruleFanoutImpl       = "fanout-impl"       // "calls": implementation behind an interface node (Options.MaxFanout)
+	ruleCollapsedWrapper = "collapsed-wrapper" // "postprocess": call through a collapsed trivial wrapper (Options.TrivialWrappers)
+	ruleEmbedRead        = "embed-read"        // "embeds": read of a //go:embed variable (Options.Embeds)
+	ruleMigration        = "migration"         // "migrations": registered migration function, file or embedded FS (Options.Migrations)
+)
+
+// maxSnippet bounds Provenance.Snippet, in bytes.
+const maxSnippet = 120
+
+func newProvenance(phase, rule string, at ast.Node, debug bool) Provenance {
+	p := Provenance{Phase: phase, Rule: rule}
+	if debug {
+		if expr, ok := at.(ast.Expr); ok {
+			p.Snippet = shortSnippet(types.ExprString(expr))
+		}
+	}
+	return p
+}
+
+func shortSnippet(s string) string {
+	if len(s) <= maxSnippet {
+		return s
+	}
+	cut := maxSnippet - 3
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,56 @@
+package goanalyzer
+
+// Prune trims the graph to the nodes within maxDepth calls of roots, in
+// either direction (callers and callees), for "expand neighborhood" views
+// backed by repeated analyses. maxDepth 0 keeps everything connected to the
+// roots; with no roots, the entry points are used. Statuses are those
+// computed over the whole graph. It returns the roots that are not nodes.
+func (g *Graph) Prune(roots []string, maxDepth int) (unknown []string) {
+	index := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		index[n.ID] = true
+	}
+	depth := make(map[string]int)
+	var frontier []string
+	if len(roots) == 0 {
+		for _, n := range g.Nodes {
+			if n.IsEntryPoint {
+				roots = append(roots, n.ID)
+			}
+		}
+	}
+	for _, id := range roots {
+		if !index[id] {
+			unknown = append(unknown, id)
+			continue
+		}
+		if _, ok := depth[id]; !ok {
+			depth[id] = 0
+			frontier = append(frontier, id)
+		}
+	}
+
+	adjacent := make(map[string][]string)
+	for _, e := range g.Edges {
+		adjacent[e.Source] = append(adjacent[e.Source], e.Target)
+		adjacent[e.Target] = append(adjacent[e.Target], e.Source)
+	}
+	for d := 1; len(frontier) > 0 && (maxDepth == 0 || d <= maxDepth); d++ {
+		var next []string
+		for _, id := range frontier {
+			for _, other := range adjacent[id] {
+				if _, ok := depth[other]; !ok {
+					depth[other] = d
+					next = append(next, other)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	g.retain(func(n Node) bool {
+		_, ok := depth[n.ID]
+		return ok
+	})
+	return unknown
+}
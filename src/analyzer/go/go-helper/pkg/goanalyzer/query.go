@@ -0,0 +1,104 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FindNode returns the node named by ref: a node ID, a SymbolID, or a
+// QualifiedName or Name that only one node has.
+func (g *Graph) FindNode(ref string) (*Node, error) {
+	for i := range g.Nodes {
+		if n := &g.Nodes[i]; n.ID == ref || n.SymbolID == ref {
+			return n, nil
+		}
+	}
+	var matches []*Node
+	for i := range g.Nodes {
+		if n := &g.Nodes[i]; n.QualifiedName == ref || n.Name == ref {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no function %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	ids := make([]string, len(matches))
+	for i, n := range matches {
+		ids[i] = n.ID
+	}
+	sort.Strings(ids)
+	return nil, fmt.Errorf("%q is ambiguous: %s", ref, strings.Join(ids, ", "))
+}
+
+// Callers returns the edges into the node id.
+func (g *Graph) Callers(id string) []Edge {
+	var edges []Edge
+	for _, e := range g.Edges {
+		if e.Target == id {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Callees returns the edges out of the node id.
+func (g *Graph) Callees(id string) []Edge {
+	var edges []Edge
+	for _, e := range g.Edges {
+		if e.Source == id {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// DeadFunctions returns the nodes with status "dead" (see markLiveness).
+func (g *Graph) DeadFunctions() []Node {
+	var dead []Node
+	for _, n := range g.Nodes {
+		if n.Status == "dead" {
+			dead = append(dead, n)
+		}
+	}
+	return dead
+}
+
+// PathBetween returns a shortest chain of calls from the node from to the
+// node to, as the node IDs along it, both included; nil if to is not
+// reachable from from.
+func (g *Graph) PathBetween(from, to string) []string {
+	callees := make(map[string][]string)
+	for _, e := range g.Edges {
+		callees[e.Source] = append(callees[e.Source], e.Target)
+	}
+	prev := map[string]string{from: ""}
+	frontier := []string{from}
+	for len(frontier) > 0 {
+		var next []string
+		for _, id := range frontier {
+			if id == to {
+				var path []string
+				for ; id != from; id = prev[id] {
+					path = append(path, id)
+				}
+				path = append(path, from)
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			for _, callee := range callees[id] {
+				if _, ok := prev[callee]; !ok {
+					prev[callee] = id
+					next = append(next, callee)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// QueueOp is a message published to or consumed from a topic, subject or
+// queue by a function.
+type QueueOp struct {
+	// Op is "publish" or "consume".
+	Op    string `json:"op"`
+	Topic string `json:"topic"`
+	Line  int    `json:"line"`
+}
+
+// queueFuncs are the methods of NATS, AMQP, Google Pub/Sub, Kafka and
+// Redis clients that name a topic, by the operation they stand for. The
+// topic is their first non-empty constant string argument.
+var queueFuncs = map[string]string{
+	"Publish": "publish", "PublishMsg": "publish", "PublishAsync": "publish",
+	"PublishWithContext": "publish", "Produce": "publish", "Topic": "publish",
+	"Subscribe": "consume", "QueueSubscribe": "consume", "ChanSubscribe": "consume",
+	"SubscribeSync": "consume", "QueueSubscribeSync": "consume", "PullSubscribe": "consume",
+	"Consume": "consume", "Subscription": "consume", "PSubscribe": "consume",
+}
+
+// collectQueueOps returns the topics body publishes to or consumes from:
+// calls of queueFuncs, and struct literals with a constant Topic field
+// (kafka.Message, sarama.ProducerMessage, kafka.ReaderConfig), which
+// consume when their type is named like a reader or consumer.
+func collectQueueOps(body *ast.BlockStmt, ctx *passContext) []QueueOp {
+	var ops []QueueOp
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			target, ok := ctx.resolveCall(x)
+			if !ok || target.pkgPath == "" && target.recvExpr == nil {
+				return true
+			}
+			op, ok := queueFuncs[target.name]
+			if !ok {
+				return true
+			}
+			for _, arg := range x.Args {
+				if topic, ok := ctx.constString(arg); ok && topic != "" {
+					ops = append(ops, QueueOp{Op: op, Topic: topic, Line: ctx.fset.Position(x.Pos()).Line})
+					break
+				}
+			}
+		case *ast.CompositeLit:
+			for _, elt := range x.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Topic" {
+					continue
+				}
+				topic, ok := ctx.constString(kv.Value)
+				if !ok || topic == "" {
+					continue
+				}
+				op := "publish"
+				if name := strings.ToLower(exprText(x.Type)); strings.Contains(name, "reader") || strings.Contains(name, "consumer") {
+					op = "consume"
+				}
+				ops = append(ops, QueueOp{Op: op, Topic: topic, Line: ctx.fset.Position(x.Pos()).Line})
+			}
+		}
+		return true
+	})
+	return ops
+}
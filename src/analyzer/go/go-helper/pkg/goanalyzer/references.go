@@ -0,0 +1,151 @@
+package goanalyzer
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Reference is one use of a function or method in the source: a call, or
+// the function used as a value (assigned, compared, stored in a map, passed
+// to a test helper). Graph.References lists them with Options.References.
+type Reference struct {
+	// Target is the node ID of the referenced function.
+	Target   string `json:"target"`
+	FilePath string `json:"filePath"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	// Kind is "call" or "value".
+	Kind string `json:"kind"`
+	// Source is the node ID of the function containing the reference, if
+	// it is in the graph; references from tests have none.
+	Source string `json:"source,omitempty"`
+	// Test is set for references in _test.go files.
+	Test bool `json:"test,omitempty"`
+}
+
+// collectReferences finds every reference to the functions of g, including
+// from the project's tests. The packages are loaded again with their tests,
+// so functions are matched to nodes by SymbolID.
+func collectReferences(ctx context.Context, input Options, g *Graph) error {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedSyntax |
+			packages.NeedTypes |
+			packages.NeedTypesInfo,
+		Dir:     input.ProjectRoot,
+		Overlay: input.absOverlays(),
+		Env:     input.packagesEnv(),
+		Tests:   true,
+	}
+	pkgs, err := packages.Load(cfg, input.projectPatterns()...)
+	if err != nil {
+		return err
+	}
+	absRoot, _ := filepath.Abs(input.ProjectRoot)
+
+	bySymbol := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.SymbolID != "" {
+			bySymbol[n.SymbolID] = n.ID
+		}
+	}
+	nodeID := func(obj types.Object) string {
+		fn, ok := obj.(*types.Func)
+		if !ok || fn.Pkg() == nil {
+			return ""
+		}
+		return bySymbol[funcSymbol(fn.Origin())]
+	}
+
+	refs := []Reference{}
+	// A package and its test variant share files; visit each file once.
+	visited := make(map[string]bool)
+	for _, pkg := range filterProjectPackages(pkgs, absRoot) {
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := filepath.Rel(absRoot, absPath)
+			if err != nil || visited[absPath] {
+				continue
+			}
+			visited[absPath] = true
+			test := strings.HasSuffix(relPath, "_test.go")
+			for _, decl := range file.Decls {
+				source := ""
+				if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+					source = nodeID(pkg.TypesInfo.Defs[funcDecl.Name])
+				}
+				calls := make(map[*ast.Ident]bool)
+				ast.Inspect(decl, func(n ast.Node) bool {
+					switch n := n.(type) {
+					case *ast.CallExpr:
+						if id := calledIdent(n); id != nil {
+							calls[id] = true
+						}
+					case *ast.Ident:
+						target := nodeID(pkg.TypesInfo.Uses[n])
+						if target == "" {
+							return true
+						}
+						pos := pkg.Fset.Position(n.Pos())
+						kind := "value"
+						if calls[n] {
+							kind = "call"
+						}
+						refs = append(refs, Reference{
+							Target:   target,
+							FilePath: relPath,
+							Line:     pos.Line,
+							Column:   pos.Column,
+							Kind:     kind,
+							Source:   source,
+							Test:     test,
+						})
+					}
+					return true
+				})
+			}
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	g.References = refs
+	return nil
+}
+
+// calledIdent returns the identifier naming the function called by call
+// (foo, pkg.Foo, x.Method, or a generic instantiation of one), or nil.
+func calledIdent(call *ast.CallExpr) *ast.Ident {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	case *ast.IndexExpr: // generic instantiation
+		if sel, ok := fun.X.(*ast.SelectorExpr); ok {
+			return sel.Sel
+		} else if id, ok := fun.X.(*ast.Ident); ok {
+			return id
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// RegistryRule makes entry points of functions that a framework discovers
+// at run time rather than calls directly: handlers registered by name
+// (registry.Register("name", fn)) or types wired up through struct tags.
+// Type-aware analysis only.
+type RegistryRule struct {
+	// Name identifies the rule in Node.RegisteredBy; it defaults to Call
+	// or Tag.
+	Name string `json:"name,omitempty"`
+	// Call is a glob matching registration functions, by package name
+	// ("registry.Register", "http.*Handle*", "mux.Router.HandleFunc") or
+	// by linker symbol ("example.com/app/registry.Register"). The project
+	// functions and method values passed to a matching call become entry
+	// points, with a "registry" edge from the calling function; for
+	// arguments of a project type, its methods do.
+	Call string `json:"call,omitempty"`
+	// Tag is a struct tag key: the methods of the types of struct fields
+	// carrying it become entry points.
+	Tag string `json:"tag,omitempty"`
+	// Methods restricts the methods that become entry points (default:
+	// every exported method).
+	Methods []string `json:"methods,omitempty"`
+}
+
+func (r RegistryRule) name() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Call != "":
+		return r.Call
+	}
+	return r.Tag
+}
+
+// wantsMethod reports whether the rule registers the method named name.
+func (r RegistryRule) wantsMethod(name string) bool {
+	if len(r.Methods) == 0 {
+		return token.IsExported(name)
+	}
+	for _, m := range r.Methods {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registrar marks nodes as entry points registered with a framework.
+type registrar struct {
+	nodes       []Node
+	index       map[string]int
+	objToNodeID map[types.Object]string
+}
+
+func newRegistrar(nodes []Node, objToNodeID map[types.Object]string) *registrar {
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+	return &registrar{nodes: nodes, index: index, objToNodeID: objToNodeID}
+}
+
+// register makes the node id an entry point registered by by.
+func (r *registrar) register(id, by string) {
+	if i, ok := r.index[id]; ok && r.nodes[i].RegisteredBy == "" {
+		r.nodes[i].IsEntryPoint = true
+		r.nodes[i].RegisteredBy = by
+	}
+}
+
+// registerMethods registers the project methods of t (or of the type t
+// points to) selected by want, and returns their node IDs.
+func (r *registrar) registerMethods(t types.Type, by string, want func(string) bool) []string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	named = named.Origin()
+	var ids []string
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		if id, ok := r.objToNodeID[m]; ok && want(m.Name()) {
+			r.register(id, by)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// applyRegistryRules marks the functions registered per rules as entry
+// points and adds the "registry" edges of registration calls.
+func applyRegistryRules(rules []RegistryRule, pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, nodes []Node, edges *[]Edge, debug bool) {
+	if len(rules) == 0 {
+		return
+	}
+	reg := newRegistrar(nodes, objToNodeID)
+
+	calls := make([]*regexp.Regexp, len(rules))
+	for i, r := range rules {
+		if r.Call != "" {
+			calls[i] = globRegexp(r.Call)
+		}
+	}
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.GenDecl:
+					for _, rule := range rules {
+						if rule.Tag != "" {
+							tagRule(decl, pkg.TypesInfo, rule, func(t types.Type) { reg.registerMethods(t, rule.name(), rule.wantsMethod) })
+						}
+					}
+				case *ast.FuncDecl:
+					sourceID := objToNodeID[pkg.TypesInfo.Defs[decl.Name]]
+					if sourceID == "" || decl.Body == nil {
+						continue
+					}
+					ast.Inspect(decl.Body, func(n ast.Node) bool {
+						call, ok := n.(*ast.CallExpr)
+						if !ok {
+							return true
+						}
+						callee := calleeFunc(call, pkg.TypesInfo)
+						if callee == nil {
+							return true
+						}
+						for ri, rule := range rules {
+							if calls[ri] == nil || !matchesCallee(calls[ri], callee) {
+								continue
+							}
+							for _, arg := range call.Args {
+								if middleware, handler := unwrapChain(arg, pkg.TypesInfo, objToNodeID); handler != nil {
+									if id, ok := objToNodeID[handler]; ok {
+										reg.register(id, rule.name())
+										pos := pkg.Fset.Position(arg.Pos())
+										*edges = append(*edges, Edge{
+											Source:     sourceID,
+											Target:     id,
+											CallSite:   CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column},
+											Kind:       "chain",
+											IsResolved: true,
+											Provenance: newProvenance("registry", ruleMiddlewareChain, arg, debug),
+											Route:      routeOf(call, pkg.TypesInfo),
+											Middleware: middleware,
+										})
+									}
+									continue
+								}
+								var targets []string
+								if fn, ok := referencedObject(arg, pkg.TypesInfo).(*types.Func); ok {
+									if id, ok := objToNodeID[fn]; ok {
+										reg.register(id, rule.name())
+										targets = append(targets, id)
+									}
+								} else if tv, ok := pkg.TypesInfo.Types[arg]; ok && tv.IsValue() {
+									targets = reg.registerMethods(tv.Type, rule.name(), rule.wantsMethod)
+								}
+								pos := pkg.Fset.Position(arg.Pos())
+								for _, target := range targets {
+									*edges = append(*edges, Edge{
+										Source:     sourceID,
+										Target:     target,
+										CallSite:   CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column},
+										Kind:       "registry",
+										IsResolved: true,
+										Provenance: newProvenance("registry", ruleRegistryCall, call, debug),
+									})
+								}
+							}
+							break
+						}
+						return true
+					})
+				}
+			}
+		}
+	}
+}
+
+// tagRule calls register with the type of each struct field in decl whose
+// tag has the rule's key.
+func tagRule(decl *ast.GenDecl, info *types.Info, rule RegistryRule, register func(types.Type)) {
+	if decl.Tok != token.TYPE {
+		return
+	}
+	ast.Inspect(decl, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			value, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+			if _, ok := reflect.StructTag(value).Lookup(rule.Tag); ok {
+				register(info.TypeOf(field.Type))
+			}
+		}
+		return true
+	})
+}
+
+// calleeFunc returns the function or method statically called by call.
+func calleeFunc(call *ast.CallExpr, info *types.Info) *types.Func {
+	fn, _ := referencedObject(call.Fun, info).(*types.Func)
+	return fn
+}
+
+// referencedObject returns the object named by an identifier or selector
+// expression, or nil.
+func referencedObject(expr ast.Expr, info *types.Info) types.Object {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return info.Uses[e]
+	case *ast.SelectorExpr:
+		return info.Uses[e.Sel]
+	}
+	return nil
+}
+
+// matchesCallee matches a registration glob against the callee's short
+// name ("pkg.Func", "pkg.Type.Method") and linker symbol.
+func matchesCallee(re *regexp.Regexp, fn *types.Func) bool {
+	if fn.Pkg() == nil {
+		return false
+	}
+	short := fn.Pkg().Name() + "." + fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			short = fn.Pkg().Name() + "." + named.Obj().Name() + "." + fn.Name()
+		}
+	}
+	return re.MatchString(short) || re.MatchString(funcSymbol(fn))
+}
@@ -0,0 +1,160 @@
+package goanalyzer
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Report kinds for Options.Reports.
+const (
+	// ReportHTML is a standalone HTML page (codegraph-report.html) with a
+	// searchable function table, the dead-code list and a zoomable view of
+	// each function's neighborhood. It needs no server or network access.
+	ReportHTML = "html"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+// reportData is what the HTML report embeds: a compact copy of the graph.
+type reportData struct {
+	Title    string
+	Summary  reportSummary
+	Nodes    []reportNode
+	Edges    [][2]int
+	DeadCode *DeadCodeReport
+}
+
+type reportSummary struct {
+	Functions, Edges, Entry, Live, Dead, TestOnly int
+	GraphHash                                     string
+}
+
+type reportNode struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Package string `json:"pkg"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	LOC     int    `json:"loc"`
+	Kind    string `json:"kind"`
+	Status  string `json:"status"`
+	Color   string `json:"color"`
+	FanIn   int    `json:"fanIn"`
+	FanOut  int    `json:"fanOut"`
+	Kept    string `json:"kept,omitempty"`
+}
+
+// WriteReports writes the reports named by opts.Reports into opts.ReportDir,
+// creating it if needed, and returns the paths written.
+func WriteReports(g *Graph, opts Options) ([]string, error) {
+	opts = opts.MapPaths()
+	dir := resolvePath(opts.ProjectRoot, opts.ReportDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, kind := range opts.Reports {
+		var err error
+		switch kind {
+		case ReportHTML:
+			err = writeReportFile(dir, "codegraph-report.html", &written, func(w io.Writer) error { return WriteHTMLReport(w, g) })
+		case ReportCSV, ReportTSV:
+			sep := ','
+			if kind == ReportTSV {
+				sep = '\t'
+			}
+			err = writeReportFile(dir, "codegraph-nodes."+kind, &written, func(w io.Writer) error { return WriteNodeTable(w, g, sep) })
+			if err == nil {
+				err = writeReportFile(dir, "codegraph-edges."+kind, &written, func(w io.Writer) error { return WriteEdgeTable(w, g, sep) })
+			}
+		case ReportZip:
+			err = writeReportFile(dir, "codegraph-tables.zip", &written, func(w io.Writer) error { return writeTableZip(w, g) })
+		default:
+			err = fmt.Errorf("unknown report %q", kind)
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// writeReportFile creates name in dir with write and adds it to written.
+func writeReportFile(dir, name string, written *[]string, write func(io.Writer) error) error {
+	file := filepath.Join(dir, name)
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	*written = append(*written, file)
+	return nil
+}
+
+// WriteHTMLReport renders the ReportHTML page for g.
+func WriteHTMLReport(w io.Writer, g *Graph) error {
+	data := reportData{Title: "CodeGraph report", DeadCode: g.DeadCode, Nodes: []reportNode{}, Edges: [][2]int{}}
+	index := make(map[string]int, len(g.Nodes))
+	for _, n := range g.Nodes {
+		index[n.ID] = len(data.Nodes)
+		data.Nodes = append(data.Nodes, reportNode{
+			ID:      n.ID,
+			Name:    n.Name,
+			Package: n.PackageOrModule,
+			File:    n.FilePath,
+			Line:    n.StartLine,
+			LOC:     n.LinesOfCode,
+			Kind:    n.Kind,
+			Status:  n.Status,
+			Color:   n.Color,
+			Kept:    n.KeptBy,
+		})
+		switch n.Status {
+		case "entry":
+			data.Summary.Entry++
+		case "live":
+			data.Summary.Live++
+		case "dead":
+			data.Summary.Dead++
+		case "test-only":
+			data.Summary.TestOnly++
+		}
+	}
+	data.Summary.Functions = len(g.Nodes)
+	data.Summary.GraphHash = g.GraphHash
+
+	seen := make(map[[2]int]bool)
+	for _, e := range g.Edges {
+		src, ok := index[e.Source]
+		dst, ok2 := index[e.Target]
+		if !ok || !ok2 || seen[[2]int{src, dst}] {
+			continue
+		}
+		seen[[2]int{src, dst}] = true
+		data.Edges = append(data.Edges, [2]int{src, dst})
+		data.Nodes[src].FanOut++
+		data.Nodes[dst].FanIn++
+	}
+	data.Summary.Edges = len(data.Edges)
+	sort.Slice(data.Edges, func(i, j int) bool {
+		if data.Edges[i][0] != data.Edges[j][0] {
+			return data.Edges[i][0] < data.Edges[j][0]
+		}
+		return data.Edges[i][1] < data.Edges[j][1]
+	})
+	return reportTemplate.Execute(w, data)
+}
@@ -0,0 +1,252 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Route is an HTTP route or gRPC method the project registers, with the
+// function serving it.
+type Route struct {
+	// Kind is "http" or "grpc".
+	Kind string `json:"kind"`
+	// Method is the HTTP method, from the registration function (r.Get,
+	// e.POST) or a Go 1.22 pattern ("GET /users"), or "" for any method.
+	Method string `json:"method,omitempty"`
+	// Path is the HTTP path pattern or the gRPC "Service/Method".
+	Path string `json:"path"`
+	// Handler is the node ID of the serving function. A function literal
+	// is served by the function it is written in, and a handler built by a
+	// call, as in users(db), by the function called.
+	Handler string `json:"handler"`
+	// Middleware wraps the handler, outermost first, by node ID or else
+	// qualified name (see unwrapChain).
+	Middleware []string `json:"middleware,omitempty"`
+	// RegisteredIn is the node ID of the function registering the route.
+	RegisteredIn string   `json:"registeredIn"`
+	CallSite     CallSite `json:"callSite"`
+}
+
+// routeKey identifies a route by its registration.
+type routeKey struct {
+	kind, method, path, handler string
+	site                        CallSite
+}
+
+func (r Route) key() routeKey {
+	return routeKey{r.Kind, r.Method, r.Path, r.Handler, r.CallSite}
+}
+
+// httpMethods maps the names of method-specific registration functions
+// (chi's Get, gin's GET, echo's POST, fiber's Delete) to HTTP methods.
+var httpMethods = map[string]string{
+	"Get": "GET", "Head": "HEAD", "Post": "POST", "Put": "PUT", "Patch": "PATCH", "Delete": "DELETE", "Options": "OPTIONS",
+	"GET": "GET", "HEAD": "HEAD", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH", "DELETE": "DELETE", "OPTIONS": "OPTIONS",
+}
+
+// grpcRegister matches the registration functions protoc-gen-go-grpc
+// generates: RegisterGreeterServer(s, srv).
+var grpcRegister = regexp.MustCompile(`^Register(\w+)Server$`)
+
+// extractRoutes finds the route registrations in the project's function
+// bodies: calls of Handle, HandleFunc, Any or an HTTP method name with a
+// constant path starting with "/" (or a "METHOD /path" pattern) and a
+// handler served by the project, after any middleware (see routeHandler),
+// and calls of generated gRPC
+// Register...Server functions, whose service methods become routes served
+// by the implementation's methods. An HTTP handler wrapped in middleware
+// also gets a "chain" edge from the registering function, unless a
+// registry rule added it already.
+func extractRoutes(pkgs []*packages.Package, paths *sourcePaths, objToNodeID map[types.Object]string, edges *[]Edge, debug bool) []Route {
+	routes := []Route{}
+	type chainKey struct {
+		source, target string
+		site           CallSite
+	}
+	chained := make(map[chainKey]bool)
+	for _, e := range *edges {
+		if e.Kind == "chain" {
+			chained[chainKey{e.Source, e.Target, e.CallSite}] = true
+		}
+	}
+	for _, pkg := range pkgs {
+		for i, file := range pkg.Syntax {
+			relPath, err := paths.rel(pkg.CompiledGoFiles[i])
+			if err != nil {
+				continue
+			}
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+				sourceID := objToNodeID[pkg.TypesInfo.Defs[funcDecl.Name]]
+				if sourceID == "" {
+					continue
+				}
+				ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					callee := calleeFunc(call, pkg.TypesInfo)
+					if callee == nil {
+						return true
+					}
+					pos := pkg.Fset.Position(call.Pos())
+					site := CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column}
+					if m := grpcRegister.FindStringSubmatch(callee.Name()); m != nil && len(call.Args) == 2 {
+						routes = append(routes, grpcRoutes(m[1], callee, call.Args[1], pkg.TypesInfo, objToNodeID, sourceID, site)...)
+						return true
+					}
+					r, arg, ok := httpRoute(callee.Name(), call, pkg.TypesInfo, objToNodeID, sourceID)
+					if !ok {
+						return true
+					}
+					r.RegisteredIn, r.CallSite = sourceID, site
+					routes = append(routes, r)
+					pos = pkg.Fset.Position(arg.Pos())
+					key := chainKey{sourceID, r.Handler, CallSite{FilePath: relPath, Line: pos.Line, Column: pos.Column}}
+					if len(r.Middleware) > 0 && r.Handler != sourceID && !chained[key] {
+						chained[key] = true
+						*edges = append(*edges, Edge{
+							Source:     key.source,
+							Target:     key.target,
+							CallSite:   key.site,
+							Kind:       "chain",
+							IsResolved: true,
+							Provenance: newProvenance("routes", ruleMiddlewareChain, arg, debug),
+							Route:      routeOf(call, pkg.TypesInfo),
+							Middleware: r.Middleware,
+						})
+					}
+					return true
+				})
+			}
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return routes
+}
+
+// httpRoute reads an HTTP route registration in the function enclosing,
+// and returns the argument holding the handler. The handler is the last
+// handler argument; the handler arguments before it (gin, echo) and the
+// wrappers around it are middleware.
+func httpRoute(name string, call *ast.CallExpr, info *types.Info, objToNodeID map[types.Object]string, enclosing string) (Route, ast.Expr, bool) {
+	method, verb := httpMethods[name]
+	if !verb && name != "Handle" && name != "HandleFunc" && name != "Any" {
+		return Route{}, nil, false
+	}
+	path := routeOf(call, info)
+	if !verb {
+		if m, p, ok := strings.Cut(path, " "); ok && strings.HasPrefix(strings.TrimSpace(p), "/") {
+			method, path = m, strings.TrimSpace(p)
+		}
+	}
+	if !strings.HasPrefix(path, "/") {
+		return Route{}, nil, false
+	}
+	var middleware []string
+	var handler string
+	var handlerArg ast.Expr
+	var served bool
+	for _, arg := range call.Args {
+		wrappers, inner := peelChain(arg, info)
+		name, node := routeHandler(inner, info, objToNodeID, enclosing)
+		if name == "" {
+			continue
+		}
+		if handler != "" {
+			middleware = append(middleware, handler)
+		}
+		for _, fn := range wrappers {
+			middleware = append(middleware, funcName(fn, objToNodeID))
+		}
+		handler, handlerArg, served = name, arg, node
+	}
+	if !served {
+		return Route{}, nil, false
+	}
+	return Route{Kind: "http", Method: method, Path: path, Handler: handler, Middleware: middleware}, handlerArg, true
+}
+
+// routeHandler names the function serving a handler expression, unwrapped
+// by peelChain, by node ID or else qualified name, and reports whether it
+// is a project node: a function value names itself, a function literal
+// the function enclosing it, and a call returning a handler, such as
+// users(db), the function called. It returns "" for other expressions.
+func routeHandler(expr ast.Expr, info *types.Info, objToNodeID map[types.Object]string, enclosing string) (string, bool) {
+	var fn *types.Func
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		return enclosing, true
+	case *ast.CallExpr:
+		if !handlerTyped(info.TypeOf(e)) {
+			return "", false
+		}
+		fn = calleeFunc(e, info)
+	default:
+		fn, _ = referencedObject(e, info).(*types.Func)
+	}
+	if fn == nil {
+		return "", false
+	}
+	id, ok := objToNodeID[fn]
+	if !ok {
+		return fn.FullName(), false
+	}
+	return id, true
+}
+
+// funcName names fn by node ID or else qualified name, as unwrapChain
+// names middleware.
+func funcName(fn *types.Func, objToNodeID map[types.Object]string) string {
+	if id, ok := objToNodeID[fn]; ok {
+		return id
+	}
+	return fn.FullName()
+}
+
+// grpcRoutes returns a route per method of the service interface taken by
+// a Register...Server function, served by the method of impl's type.
+func grpcRoutes(service string, register *types.Func, impl ast.Expr, info *types.Info, objToNodeID map[types.Object]string, sourceID string, site CallSite) []Route {
+	params := register.Type().(*types.Signature).Params()
+	if params.Len() != 2 {
+		return nil
+	}
+	iface, ok := params.At(1).Type().Underlying().(*types.Interface)
+	implType := info.TypeOf(impl)
+	if !ok || implType == nil {
+		return nil
+	}
+	var routes []Route
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		if !m.Exported() {
+			continue
+		}
+		obj, _, _ := types.LookupFieldOrMethod(implType, true, m.Pkg(), m.Name())
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		id, ok := objToNodeID[fn.Origin()]
+		if !ok {
+			continue
+		}
+		routes = append(routes, Route{Kind: "grpc", Path: service + "/" + m.Name(), Handler: id, RegisteredIn: sourceID, CallSite: site})
+	}
+	return routes
+}
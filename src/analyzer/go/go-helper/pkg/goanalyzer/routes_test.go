@@ -0,0 +1,113 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// analyzeTyped analyzes the project example.com/app of the given files,
+// by path, with type information, skipping the test when that is
+// unavailable.
+func analyzeTyped(t *testing.T, files map[string]string, opts goanalyzer.Options) goanalyzer.Graph {
+	t.Helper()
+	requireTypedAnalysis(t)
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example.com/app\n\ngo 1.24\n")
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, root, name, src)
+	}
+	opts.ProjectRoot, opts.Module = root, "example.com/app"
+	graph, err := goanalyzer.Analyze(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Stats.Algorithm != goanalyzer.AlgorithmTypes {
+		t.Skip("type-aware analysis unavailable in this environment")
+	}
+	return graph
+}
+
+const routesSrc = `package main
+
+import (
+	"database/sql"
+	"net/http"
+)
+
+func auth(next http.Handler) http.Handler { return next }
+
+func users(db *sql.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func items() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {}
+}
+
+func routes(mux *http.ServeMux, db *sql.DB) {
+	mux.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/u", users(db))
+	mux.Handle("/admin/u", auth(users(db)))
+	mux.Handle("/i", items())
+}
+
+func main() {
+	mux := http.NewServeMux()
+	routes(mux, nil)
+	http.ListenAndServe(":8080", mux)
+}
+`
+
+func TestRoutesClosuresAndFactories(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{"main.go": routesSrc}, goanalyzer.Options{Routes: true})
+	got := make(map[string]goanalyzer.Route)
+	for _, r := range graph.Routes {
+		got[r.Path] = r
+	}
+	for _, want := range []struct {
+		path, handler string
+		middleware    []string
+	}{
+		{"/x", "main.go:routes", nil},
+		{"/u", "main.go:users", nil},
+		{"/admin/u", "main.go:users", []string{"main.go:auth"}},
+		{"/i", "main.go:items", nil},
+	} {
+		r, ok := got[want.path]
+		if !ok {
+			t.Errorf("no route %s in %+v", want.path, graph.Routes)
+			continue
+		}
+		if r.Handler != want.handler || !slices.Equal(r.Middleware, want.middleware) {
+			t.Errorf("route %s served by %s through %v, want %s through %v", want.path, r.Handler, r.Middleware, want.handler, want.middleware)
+		}
+	}
+}
+
+func TestChainEdgesWithoutRegistryRules(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{"main.go": routesSrc}, goanalyzer.Options{})
+	if graph.Routes != nil {
+		t.Errorf("routes listed without Options.Routes: %+v", graph.Routes)
+	}
+	var chains []goanalyzer.Edge
+	for _, e := range graph.Edges {
+		if e.Kind == "chain" {
+			chains = append(chains, e)
+		}
+	}
+	if len(chains) != 1 {
+		t.Fatalf("got chain edges %+v, want one for /admin/u", chains)
+	}
+	e := chains[0]
+	if e.Source != "main.go:routes" || e.Target != "main.go:users" || e.Route != "/admin/u" || !slices.Equal(e.Middleware, []string{"main.go:auth"}) {
+		t.Errorf("chain edge %s → %s for %q through %v, want main.go:routes → main.go:users for \"/admin/u\" through [main.go:auth]", e.Source, e.Target, e.Route, e.Middleware)
+	}
+}
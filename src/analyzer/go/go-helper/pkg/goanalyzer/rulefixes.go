@@ -0,0 +1,301 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxMovedFunctions bounds the functions a move suggestion may relocate;
+// past it, moving code is no longer a minimal fix.
+const maxMovedFunctions = 20
+
+// FixSuggestion is a way to resolve a dependency violation, found by
+// replaying it on the call graph.
+type FixSuggestion struct {
+	// Kind is "moveCallees" to move the called functions into the calling
+	// package, "moveCallers" to move the calling functions into the called
+	// package, or "invert" to have the calling package declare an
+	// interface that the called package implements.
+	Kind string `json:"kind"`
+	// Nodes are the functions to move, or those the interface abstracts.
+	// A method only moves with its receiver type, so a move lists every
+	// method of the type.
+	Nodes []string `json:"nodes"`
+	// Package is where Nodes move to, or where the interface is declared.
+	Package string `json:"package"`
+	Message string `json:"message"`
+}
+
+// fixPlanner replays fixes of dependency violations on the call graph.
+type fixPlanner struct {
+	nodes   map[string]*Node
+	pkgOf   map[string]string // project node ID → package directory
+	edges   []Edge
+	edgesOf map[string][]Edge // calls into and out of each node
+	// methodsOf maps a project method to the methods of its receiver type,
+	// itself included.
+	methodsOf map[string][]string
+	// violates reports whether a call between two packages breaks a rule.
+	violates func(from, to string) bool
+}
+
+func newFixPlanner(g *Graph, pkgOf map[string]string, violates func(from, to string) bool) *fixPlanner {
+	p := &fixPlanner{
+		nodes:     make(map[string]*Node, len(g.Nodes)),
+		pkgOf:     pkgOf,
+		edges:     g.Edges,
+		edgesOf:   make(map[string][]Edge),
+		methodsOf: make(map[string][]string),
+		violates:  violates,
+	}
+	byType := make(map[string][]string)
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		p.nodes[n.ID] = n
+		if recv := p.receiver(n.ID); recv != "" {
+			byType[recv] = append(byType[recv], n.ID)
+		}
+	}
+	for _, methods := range byType {
+		for _, id := range methods {
+			p.methodsOf[id] = methods
+		}
+	}
+	for _, e := range g.Edges {
+		p.edgesOf[e.Source] = append(p.edgesOf[e.Source], e)
+		if e.Target != e.Source {
+			p.edgesOf[e.Target] = append(p.edgesOf[e.Target], e)
+		}
+	}
+	return p
+}
+
+// suggest proposes, smallest first, the ways to resolve f, a dependency
+// violation from package f.From to package f.To. A move is grown until no
+// call into or out of the moved functions breaks a rule, taking along the
+// functions of the package they leave that would otherwise be stranded and
+// the receiver types of moved methods with all their methods; it is left
+// out when that fails, needs more than maxMovedFunctions functions or would
+// create an import cycle. Inverting the dependency is proposed for the
+// static calls of f: those dispatched through an interface already go
+// through one.
+func (p *fixPlanner) suggest(f Finding) []FixSuggestion {
+	var callers, callees, static []string
+	seen := make(map[string]bool)
+	seenStatic := make(map[string]bool)
+	for _, e := range f.Edges {
+		if !seen[e.Source] {
+			seen[e.Source] = true
+			callers = append(callers, e.Source)
+		}
+		if !seen[e.Target] {
+			seen[e.Target] = true
+			callees = append(callees, e.Target)
+		}
+		if !dispatched(e) && !seenStatic[e.Target] {
+			seenStatic[e.Target] = true
+			static = append(static, e.Target)
+		}
+	}
+	sort.Strings(callers)
+	sort.Strings(callees)
+	sort.Strings(static)
+
+	var suggestions []FixSuggestion
+	if moved := p.move(callees, f.To, f.From); moved != nil {
+		suggestions = append(suggestions, FixSuggestion{
+			Kind:    "moveCallees",
+			Nodes:   moved,
+			Package: f.From,
+			Message: fmt.Sprintf("move %s from %s to %s", p.movedNames(moved), f.To, f.From),
+		})
+	}
+	if moved := p.move(callers, f.From, f.To); moved != nil {
+		suggestions = append(suggestions, FixSuggestion{
+			Kind:    "moveCallers",
+			Nodes:   moved,
+			Package: f.To,
+			Message: fmt.Sprintf("move %s from %s to %s", p.movedNames(moved), f.From, f.To),
+		})
+	}
+	if len(static) > 0 {
+		what := "an interface"
+		for _, id := range static {
+			if n := p.nodes[id]; n == nil || n.Kind != "method" {
+				what = "an interface or function types"
+				break
+			}
+		}
+		suggestions = append(suggestions, FixSuggestion{
+			Kind:    "invert",
+			Nodes:   static,
+			Package: f.From,
+			Message: fmt.Sprintf("declare %s for %s in %s and have %s supply the implementation", what, p.names(static), f.From, f.To),
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].Nodes) < len(suggestions[j].Nodes)
+	})
+	return suggestions
+}
+
+// move returns the functions that must move from package from to package
+// to along with seed so that no call touching them breaks a rule, or nil if
+// there are none such or too many.
+func (p *fixPlanner) move(seed []string, from, to string) []string {
+	moved := make(map[string]bool, len(seed))
+	pkg := func(id string) string {
+		if moved[id] {
+			return to
+		}
+		return p.pkgOf[id]
+	}
+	var queue []string
+	// add moves id, with the other methods of its receiver type.
+	add := func(id string) bool {
+		for _, m := range append([]string{id}, p.methodsOf[id]...) {
+			if moved[m] {
+				continue
+			}
+			if p.pkgOf[m] != from || len(moved) == maxMovedFunctions {
+				return false
+			}
+			moved[m] = true
+			queue = append(queue, m)
+		}
+		return true
+	}
+	for _, id := range seed {
+		if !add(id) {
+			return nil
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range p.edgesOf[id] {
+			other := e.Target
+			if other == id {
+				other = e.Source
+			}
+			if _, ok := p.pkgOf[other]; !ok || !p.violates(pkg(e.Source), pkg(e.Target)) {
+				continue
+			}
+			if moved[other] || !add(other) {
+				return nil
+			}
+		}
+	}
+	if p.onCycle(to, pkg) && !p.onCycle(to, func(id string) string { return p.pkgOf[id] }) {
+		return nil
+	}
+	ids := make([]string, 0, len(moved))
+	for id := range moved {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// onCycle reports whether the calls between packages, with functions placed
+// by pkg, lead from package dir back to itself.
+func (p *fixPlanner) onCycle(dir string, pkg func(id string) string) bool {
+	calls := make(map[string]map[string]bool)
+	for _, e := range p.edges {
+		from, to := pkg(e.Source), pkg(e.Target)
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		if calls[from] == nil {
+			calls[from] = make(map[string]bool)
+		}
+		calls[from][to] = true
+	}
+	seen := make(map[string]bool)
+	stack := []string{dir}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for next := range calls[cur] {
+			if next == dir {
+				return true
+			}
+			if !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
+
+// dispatched reports whether e is a call through an interface, to one of
+// its implementations.
+func dispatched(e Edge) bool {
+	return e.Kind == "interface" || e.Kind == "provided"
+}
+
+// receiver returns the receiver type of the project method id, as its
+// package directory and type name, or "" for other nodes.
+func (p *fixPlanner) receiver(id string) string {
+	n := p.nodes[id]
+	dir, ok := p.pkgOf[id]
+	if n == nil || !ok || n.Kind != "method" {
+		return ""
+	}
+	_, qualified, _ := strings.Cut(n.ID, ":")
+	recv, _, ok := strings.Cut(qualified, ".")
+	if !ok {
+		return ""
+	}
+	return dir + "." + recv
+}
+
+// movedNames lists the functions of a move for a message, naming the
+// receiver types that move along with their methods.
+func (p *fixPlanner) movedNames(ids []string) string {
+	var types, funcs []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		recv := p.receiver(id)
+		if recv == "" {
+			funcs = append(funcs, id)
+			continue
+		}
+		if !seen[recv] {
+			seen[recv] = true
+			types = append(types, recv)
+		}
+	}
+	if len(types) == 0 {
+		return p.names(funcs)
+	}
+	s := "type " + types[0] + " with its methods"
+	if len(types) > 1 {
+		s = "types " + strings.Join(types, ", ") + " with their methods"
+	}
+	if len(funcs) > 0 {
+		s = p.names(funcs) + " and " + s
+	}
+	return s
+}
+
+// names lists the qualified names of ids for a message, eliding all but
+// the first few.
+func (p *fixPlanner) names(ids []string) string {
+	const shown = 3
+	names := make([]string, 0, min(len(ids), shown))
+	for _, id := range ids[:min(len(ids), shown)] {
+		if n := p.nodes[id]; n != nil {
+			names = append(names, n.QualifiedName)
+		} else {
+			names = append(names, id)
+		}
+	}
+	s := strings.Join(names, ", ")
+	if len(ids) > shown {
+		s += fmt.Sprintf(" and %d more", len(ids)-shown)
+	}
+	return s
+}
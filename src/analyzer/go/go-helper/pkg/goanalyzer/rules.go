@@ -0,0 +1,154 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DependencyRule declares which packages the packages matching From may call.
+// Packages are named by their directory relative to the project root ("." for
+// the root) and matched with the same globs as exclude patterns, so
+// "internal/api/**" covers internal/api and everything below it. A call from
+// a From package to a package matching Deny is a violation; when Allow is
+// set, so is a call to any package outside it. Calls within a package are
+// always allowed. The first rule whose From matches the caller applies.
+type DependencyRule struct {
+	Name  string   `json:"name,omitempty"`
+	From  string   `json:"from"`
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// Finding is a problem found in the finished graph, such as a call that
+// breaks a dependency rule.
+type Finding struct {
+	// Kind is "dependencyViolation" for calls that break a DependencyRule,
+	// "duplicateSymbol" for functions that look copied between packages
+	// (see findDuplicates), or "longParameterList" and "parameterGroup" for
+	// candidates for a parameter object (see findParameterLists).
+	Kind string `json:"kind"`
+	// Rule is the name of the broken rule, or its From pattern if unnamed.
+	Rule string `json:"rule"`
+	// From and To are the package directories involved: the calling and
+	// called ones, or for declarations, those of the first and second
+	// node.
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+	// Edges are the offending calls.
+	Edges []Edge `json:"edges"`
+	// Nodes are the offending functions, for findings about declarations
+	// rather than calls.
+	Nodes []string `json:"nodes,omitempty"`
+	// Suggestions are the ways to resolve a dependency violation, smallest
+	// first (see fixPlanner.suggest).
+	Suggestions []FixSuggestion `json:"suggestions,omitempty"`
+}
+
+// checkDependencyRules reports, per pair of packages, the edges that break
+// the first rule matching their caller's package, with suggested fixes
+// (see fixPlanner.suggest).
+func checkDependencyRules(g *Graph, rules []DependencyRule) []Finding {
+	pkgOf := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.FilePath != "" {
+			pkgOf[n.ID] = path.Dir(n.FilePath)
+		}
+	}
+
+	compiled := make([]compiledRule, len(rules))
+	for i, r := range rules {
+		compiled[i] = compiledRule{
+			rule:  r,
+			from:  globMatcher([]string{r.From}),
+			allow: globMatcher(r.Allow),
+			deny:  globMatcher(r.Deny),
+		}
+	}
+
+	byPair := make(map[[2]string]*Finding)
+	for _, e := range g.Edges {
+		from, ok := pkgOf[e.Source]
+		to, ok2 := pkgOf[e.Target]
+		if !ok || !ok2 || from == to {
+			continue
+		}
+		for _, r := range compiled {
+			if !matchesPackage(r.from, from) {
+				continue
+			}
+			if msg := r.violation(to); msg != "" {
+				key := [2]string{from, to}
+				f := byPair[key]
+				if f == nil {
+					f = &Finding{
+						Kind:    "dependencyViolation",
+						Rule:    r.name(),
+						From:    from,
+						To:      to,
+						Message: fmt.Sprintf("package %s may not call package %s: %s", from, to, msg),
+					}
+					byPair[key] = f
+				}
+				f.Edges = append(f.Edges, e)
+			}
+			break
+		}
+	}
+
+	violates := func(from, to string) bool {
+		if from == to {
+			return false
+		}
+		for _, r := range compiled {
+			if matchesPackage(r.from, from) {
+				return r.violation(to) != ""
+			}
+		}
+		return false
+	}
+	planner := newFixPlanner(g, pkgOf, violates)
+	findings := make([]Finding, 0, len(byPair))
+	for _, f := range byPair {
+		f.Suggestions = planner.suggest(*f)
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].From != findings[j].From {
+			return findings[i].From < findings[j].From
+		}
+		return findings[i].To < findings[j].To
+	})
+	return findings
+}
+
+type compiledRule struct {
+	rule              DependencyRule
+	from, allow, deny func(string) bool
+}
+
+func (r compiledRule) name() string {
+	if r.rule.Name != "" {
+		return r.rule.Name
+	}
+	return r.rule.From
+}
+
+// violation explains why a call into package to breaks the rule, or returns
+// "" if it is allowed.
+func (r compiledRule) violation(to string) string {
+	if len(r.rule.Deny) > 0 && matchesPackage(r.deny, to) {
+		return "denied by " + strings.Join(r.rule.Deny, ", ")
+	}
+	if len(r.rule.Allow) > 0 && !matchesPackage(r.allow, to) {
+		return "not in " + strings.Join(r.rule.Allow, ", ")
+	}
+	return ""
+}
+
+// matchesPackage matches a package directory, letting "dir/**" cover dir.
+func matchesPackage(match func(string) bool, dir string) bool {
+	return match(dir) || match(dir+"/")
+}
@@ -0,0 +1,67 @@
+package goanalyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestFixSuggestions(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"example.com/app/domain"
+	"example.com/app/store"
+)
+
+func main() {
+	domain.Save(store.DB{})
+	domain.Load(store.DB{})
+}
+`,
+		"domain/domain.go": `package domain
+
+import "example.com/app/store"
+
+type Namer interface{ Name() string }
+
+func Save(db store.DB) {
+	store.Open()
+	db.Get()
+}
+
+func Load(n Namer) { n.Name() }
+`,
+		"store/store.go": `package store
+
+type DB struct{}
+
+func Open() {}
+
+func (DB) Get() {}
+
+func (DB) Put() {}
+
+func (DB) Name() string { return "db" }
+`,
+	}, goanalyzer.Options{DependencyRules: []goanalyzer.DependencyRule{{From: "domain", Deny: []string{"store"}}}})
+
+	if len(graph.Findings) != 1 {
+		t.Fatalf("got findings %+v, want one dependency violation", graph.Findings)
+	}
+	byKind := make(map[string]goanalyzer.FixSuggestion)
+	for _, s := range graph.Findings[0].Suggestions {
+		byKind[s.Kind] = s
+	}
+	// The called methods of DB move with DB, and so does DB.Put.
+	if got, want := byKind["moveCallees"].Nodes, []string{"store/store.go:DB.Get", "store/store.go:DB.Name", "store/store.go:DB.Put", "store/store.go:Open"}; !slices.Equal(got, want) {
+		t.Errorf("moveCallees moves %v, want %v", got, want)
+	}
+	// Load already calls DB.Name through an interface; only Save's calls
+	// are inverted.
+	if got, want := byKind["invert"].Nodes, []string{"store/store.go:DB.Get", "store/store.go:Open"}; !slices.Equal(got, want) {
+		t.Errorf("invert abstracts %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,424 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a JSON Schema (draft 2020-12) document or subschema. Only the
+// keywords needed to describe Options and Graph are used.
+type Schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       []string           `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties is false for structs and the value schema for
+	// maps.
+	AdditionalProperties any     `json:"additionalProperties,omitempty"`
+	Items                *Schema `json:"items,omitempty"`
+	// Ref points into Defs for recursive types such as Container.
+	Ref  string             `json:"$ref,omitempty"`
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+
+	// target is the definition Ref points to.
+	target *Schema
+}
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// InputSchema describes Options, the helper's input. No field is required.
+func InputSchema() *Schema {
+	s := newSchemaBuilder(false).root(reflect.TypeFor[Options]())
+	s.Schema, s.Title = schemaDialect, "CodeGraph Go helper options (schema "+SchemaVersion+")"
+	return s
+}
+
+// OutputSchema describes Graph, the helper's output. Fields the helper
+// always writes are required.
+func OutputSchema() *Schema {
+	s := newSchemaBuilder(true).root(reflect.TypeFor[Graph]())
+	s.Schema, s.Title = schemaDialect, "CodeGraph Go helper graph (schema "+SchemaVersion+")"
+	return s
+}
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// schemaBuilder derives schemas from Go types. Pointers may also be null;
+// with required set, struct fields without omitempty are required.
+type schemaBuilder struct {
+	required bool
+	// building holds the structs being derived, to detect recursion, and
+	// recursive the definitions of those that refer to themselves.
+	building  map[reflect.Type]bool
+	recursive map[reflect.Type]*Schema
+}
+
+func newSchemaBuilder(required bool) *schemaBuilder {
+	return &schemaBuilder{required: required, building: make(map[reflect.Type]bool), recursive: make(map[reflect.Type]*Schema)}
+}
+
+// root derives the schema of t with the definitions it refers to.
+func (b *schemaBuilder) root(t reflect.Type) *Schema {
+	s := b.schemaOf(t)
+	for rt, def := range b.recursive {
+		if s.Defs == nil {
+			s.Defs = make(map[string]*Schema)
+		}
+		s.Defs[rt.Name()] = def
+	}
+	return s
+}
+
+// ref returns a reference to the definition of the recursive struct t.
+func (b *schemaBuilder) ref(t reflect.Type) *Schema {
+	def := b.recursive[t]
+	if def == nil {
+		def = &Schema{}
+		b.recursive[t] = def
+	}
+	return &Schema{Ref: "#/$defs/" + t.Name(), target: def}
+}
+
+// schemaOf derives the schema of the JSON encoding of t.
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	if t == rawMessageType {
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schemaOf(t.Elem())
+		if s.Ref != "" {
+			// A reference cannot be widened; the validator allows null
+			// everywhere anyway.
+			return s
+		}
+		if s.Type != nil {
+			s.Type = append(s.Type, "null")
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: []string{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: []string{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: []string{"number"}}
+	case reflect.String:
+		return &Schema{Type: []string{"string"}}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes nil slices as null.
+		return &Schema{Type: []string{"array", "null"}, Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if b.building[t] {
+			return b.ref(t)
+		}
+		b.building[t] = true
+		defer delete(b.building, t)
+		s := &Schema{Type: []string{"object"}, Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			s.Properties[name] = b.schemaOf(f.Type)
+			if b.required && !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, name)
+			}
+		}
+		if def := b.recursive[t]; def != nil {
+			*def = *s
+			return b.ref(t)
+		}
+		return s
+	default:
+		// Interfaces and other kinds accept anything.
+		return &Schema{}
+	}
+}
+
+// ParseOptions decodes Options from JSON, rejecting input that does not
+// match InputSchema: unknown (often misspelled) fields and values of the
+// wrong type are reported with their path, e.g.
+//
+//	options.projcetRoot: unknown field (did you mean "projectRoot"?)
+//	options.failOn.newDeadFunctions: expected integer, got string
+func ParseOptions(data []byte) (Options, error) {
+	var opts Options
+	problems, err := InputSchema().check(data, "options")
+	if err != nil {
+		return opts, err
+	}
+	if len(problems) > 0 {
+		return opts, errors.New(strings.Join(problems, "\n"))
+	}
+	err = json.Unmarshal(data, &opts)
+	return opts, err
+}
+
+// DecodeOptions is ParseOptions reading from r, for input too large to
+// hold twice: the entries of "files", which may number in the hundreds of
+// thousands, are decoded one at a time straight into Options.Files, and
+// only the other fields are buffered and validated as a whole.
+func DecodeOptions(r io.Reader) (Options, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{', "options"); err != nil {
+		return Options{}, err
+	}
+	var files []string
+	rest := make(map[string]json.RawMessage)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return Options{}, err
+		}
+		key := t.(string)
+		if key == "files" {
+			if files, err = decodeStrings(dec, "options.files"); err != nil {
+				return Options{}, err
+			}
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return Options{}, err
+		}
+		rest[key] = raw
+	}
+	if _, err := dec.Token(); err != nil {
+		return Options{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Options{}, errors.New("options: unexpected data after the object")
+	}
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return Options{}, err
+	}
+	opts, err := ParseOptions(data)
+	opts.Files = files
+	return opts, err
+}
+
+// expectDelim reads the opening delimiter of an object or array, reporting
+// anything else in the form of InputSchema's problems.
+func expectDelim(dec *json.Decoder, delim json.Delim, path string) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		want := map[json.Delim]string{'{': "object", '[': "array"}[delim]
+		return fmt.Errorf("%s: expected %s, got %s", path, want, tokenType(t))
+	}
+	return nil
+}
+
+// decodeStrings reads an array of strings, or null, from dec.
+func decodeStrings(dec *json.Decoder, path string) ([]string, error) {
+	t, err := dec.Token()
+	if err != nil || t == nil {
+		return nil, err
+	}
+	if t != json.Delim('[') {
+		return nil, fmt.Errorf("%s: expected array, got %s", path, tokenType(t))
+	}
+	list := []string{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d]: expected string, got %s", path, len(list), tokenType(t))
+		}
+		list = append(list, s)
+	}
+	_, err = dec.Token()
+	return list, err
+}
+
+// tokenType names the JSON type of a json.Decoder token.
+func tokenType(t json.Token) string {
+	switch t.(type) {
+	case json.Delim:
+		if t == json.Delim('{') {
+			return "object"
+		}
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// ValidateGraph checks a JSON graph against OutputSchema and returns the
+// problems found, in the form ParseOptions reports them.
+func ValidateGraph(data []byte) []string {
+	problems, err := OutputSchema().check(data, "graph")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return problems
+}
+
+func (s *Schema) check(data []byte, root string) ([]string, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var problems []string
+	s.validate(v, root, &problems)
+	return problems, nil
+}
+
+// validate appends to problems each way v breaks the schema.
+func (s *Schema) validate(v any, path string, problems *[]string) {
+	if v == nil {
+		// encoding/json accepts null for any field and leaves it unset.
+		return
+	}
+	if s.target != nil {
+		s = s.target
+	}
+	if got := jsonType(v); len(s.Type) > 0 && !s.allows(got) {
+		want := strings.Join(slicesWithout(s.Type, "null"), " or ")
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, want, got))
+		return
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range s.Required {
+			if _, ok := v[k]; !ok {
+				*problems = append(*problems, path+"."+k+": missing")
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(v[k], path+"."+k, problems)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case *Schema:
+				extra.validate(v[k], path+"["+strconv.Quote(k)+"]", problems)
+			case bool:
+				msg := path + "." + k + ": unknown field"
+				if guess := s.closestProperty(k); guess != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", guess)
+				}
+				*problems = append(*problems, msg)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	}
+}
+
+func (s *Schema) allows(typ string) bool {
+	for _, t := range s.Type {
+		if t == typ || t == "number" && typ == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a value decoded with UseNumber.
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
+
+func slicesWithout(list []string, drop string) []string {
+	var out []string
+	for _, x := range list {
+		if x != drop {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// closestProperty suggests the property a misspelled field name was
+// probably meant to be: one differing only in case, or within two edits.
+func (s *Schema) closestProperty(name string) string {
+	best, bestDist := "", 3
+	for prop := range s.Properties {
+		if strings.EqualFold(prop, name) {
+			return prop
+		}
+		if d := editDistance(strings.ToLower(prop), strings.ToLower(name)); d < bestDist || d == bestDist && prop < best {
+			best, bestDist = prop, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment)
+// distance, so that a swap of adjacent letters counts as one edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
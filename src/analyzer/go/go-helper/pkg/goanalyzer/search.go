@@ -0,0 +1,77 @@
+package goanalyzer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchIndex is a trigram index over node names (Options.SearchIndex), so
+// a UI can search hundreds of thousands of symbols without indexing them on
+// load. For a query, intersect the posting lists of its lowercase trigrams
+// and check the remaining candidates' names; prefix the query with "^" to
+// anchor it at the start of a name, which also makes two-letter queries
+// usable ("^ab").
+type SearchIndex struct {
+	// Fields lists what was indexed for each node.
+	Fields []string `json:"fields"`
+	// Trigrams maps each trigram to the positions in Graph.Nodes of the
+	// nodes containing it, in increasing order.
+	Trigrams map[string][]int `json:"trigrams"`
+}
+
+// searchFields are the indexed names: the function name, its qualified
+// name without the file ("Server.Handle") and its package.
+var searchFields = []string{"name", "qualifiedName", "packageOrModule"}
+
+func searchKeys(n Node) []string {
+	qualified := n.QualifiedName
+	if _, after, ok := strings.Cut(qualified, ":"); ok {
+		qualified = after
+	}
+	return []string{n.Name, qualified, n.PackageOrModule}
+}
+
+// buildSearchIndex indexes g's nodes in their current order.
+func buildSearchIndex(g *Graph) *SearchIndex {
+	idx := &SearchIndex{Fields: searchFields, Trigrams: make(map[string][]int)}
+	for i, n := range g.Nodes {
+		for _, key := range searchKeys(n) {
+			if key == "" {
+				continue
+			}
+			for _, tri := range trigrams("^" + strings.ToLower(key)) {
+				list := idx.Trigrams[tri]
+				if len(list) == 0 || list[len(list)-1] != i {
+					idx.Trigrams[tri] = append(list, i)
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// trigrams returns the three-rune substrings of s.
+func trigrams(s string) []string {
+	var out []string
+	starts := make([]int, 0, len(s))
+	for i := range s {
+		starts = append(starts, i)
+	}
+	starts = append(starts, len(s))
+	for i := 0; i+3 < len(starts); i++ {
+		out = append(out, s[starts[i]:starts[i+3]])
+	}
+	if len(out) == 0 && utf8.RuneCountInString(s) > 0 {
+		// Names shorter than a trigram are indexed whole.
+		out = append(out, s)
+	}
+	return out
+}
+
+// refreshSearchIndex rebuilds the index if the graph has one, after nodes
+// were added, removed or reordered.
+func (g *Graph) refreshSearchIndex() {
+	if g.SearchIndex != nil {
+		g.SearchIndex = buildSearchIndex(g)
+	}
+}
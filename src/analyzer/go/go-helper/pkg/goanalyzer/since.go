@@ -0,0 +1,104 @@
+package goanalyzer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// checkSinceRef reports an Options.SinceRef that is not a commit of the
+// project's repository, rather than falling back to a full AST analysis.
+func (o Options) checkSinceRef(ctx context.Context) error {
+	if o.SinceRef == "" {
+		return nil
+	}
+	if strings.HasPrefix(o.SinceRef, "-") {
+		return fmt.Errorf("sinceRef: %q is not a revision", o.SinceRef)
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", o.ProjectRoot, "rev-parse", "--verify", o.SinceRef+"^{commit}")
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("sinceRef: %w", gitError(err))
+	}
+	return nil
+}
+
+// changedGoFiles returns the absolute paths of the .go files under root
+// that differ from rev in the working tree, including untracked ones.
+// Deleted files are included, so that their packages count as changed.
+func changedGoFiles(ctx context.Context, root, rev string) ([]string, error) {
+	diff, err := exec.CommandContext(ctx, "git", "-C", root, "diff", "--name-only", "--relative", rev, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s: %w", rev, gitError(err))
+	}
+	untracked, err := exec.CommandContext(ctx, "git", "-C", root, "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files: %w", gitError(err))
+	}
+	var files []string
+	for _, name := range strings.Split(string(diff)+string(untracked), "\n") {
+		if strings.HasSuffix(name, ".go") {
+			files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// affectedDirs returns the directories, relative to absRoot, of the
+// project packages with a .go file changed since Options.SinceRef, and of
+// the project packages importing one of them directly. A package is
+// matched by directory, so that deleting one of its files changes it too.
+func (o Options) affectedDirs(ctx context.Context, cfg *packages.Config, absRoot string) (map[string]bool, error) {
+	files, err := changedGoFiles(ctx, absRoot, o.SinceRef)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	changedDirs := make(map[string]bool, len(files))
+	for _, f := range files {
+		changedDirs[filepath.Dir(f)] = true
+	}
+
+	list := *cfg
+	list.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports
+	pkgs, err := packages.Load(&list, o.projectPatterns()...)
+	if err != nil {
+		return nil, err
+	}
+	pkgs = filterProjectPackages(pkgs, absRoot)
+	changed := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.GoFiles {
+			if changedDirs[filepath.Dir(f)] {
+				changed[pkg.PkgPath] = true
+				break
+			}
+		}
+	}
+	affected := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		in := changed[pkg.PkgPath]
+		for path := range pkg.Imports {
+			in = in || changed[path]
+		}
+		if in {
+			dir, err := filepath.Rel(absRoot, filepath.Dir(pkg.GoFiles[0]))
+			if err != nil {
+				return nil, err
+			}
+			affected[dir] = true
+		}
+	}
+	return affected, nil
+}
+
+// restrictToDirs drops the nodes declared outside dirs, keeping synthetic
+// nodes without a file and Dependency nodes, as RestrictToFiles does.
+func (g *Graph) restrictToDirs(dirs map[string]bool) {
+	g.retain(func(n Node) bool { return n.FilePath == "" || n.Dependency || dirs[filepath.Dir(n.FilePath)] })
+}
@@ -0,0 +1,66 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestSinceRefKeepsTransitiveCallersLive(t *testing.T) {
+	requireTypedAnalysis(t)
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	root := t.TempDir()
+	for name, src := range map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.24\n",
+		"main.go":    "package main\n\nimport \"example.com/app/mid\"\n\nfunc main() { mid.Run() }\n",
+		"mid/mid.go": "package mid\n\nimport \"example.com/app/lib\"\n\nfunc Run() { lib.Used() }\n",
+		"lib/lib.go": "package lib\n\nfunc Used() {}\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, root, name, src)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	writeFile(t, root, "lib/lib.go", "package lib\n\nfunc Used() {}\n\nfunc Unused() {}\n")
+
+	graph, err := goanalyzer.Analyze(context.Background(), goanalyzer.Options{ProjectRoot: root, Module: "example.com/app", SinceRef: "HEAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if graph.Stats.Algorithm != goanalyzer.AlgorithmTypes {
+		t.Skip("type-aware analysis unavailable in this environment")
+	}
+	status := make(map[string]string)
+	for _, n := range graph.Nodes {
+		status[n.ID] = n.Status
+	}
+	for id, want := range map[string]string{
+		"lib/lib.go:Used":   "live",
+		"lib/lib.go:Unused": "dead",
+		"mid/mid.go:Run":    "live",
+	} {
+		if got := status[id]; got != want {
+			t.Errorf("%s is %q, want %q", id, got, want)
+		}
+	}
+	if _, ok := status["main.go:main"]; ok {
+		t.Error("main.go:main emitted, though its package is not affected")
+	}
+}
@@ -0,0 +1,77 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SliceOptions extracts a feature's view of the graph: the functions whose
+// name, qualified name, file or package matches NameRegex, and the
+// functions within Depth calls of them in either direction.
+type SliceOptions struct {
+	NameRegex string `json:"nameRegex"`
+	// Depth is the number of hops of context kept around the matches
+	// (default 1).
+	Depth int `json:"depth,omitempty"`
+}
+
+func (s SliceOptions) compile() (*regexp.Regexp, error) {
+	if s.NameRegex == "" {
+		return nil, fmt.Errorf("slice: nameRegex is required")
+	}
+	if s.Depth < 0 {
+		return nil, fmt.Errorf("slice: depth must not be negative, not %d", s.Depth)
+	}
+	re, err := regexp.Compile(s.NameRegex)
+	if err != nil {
+		return nil, fmt.Errorf("slice: %v", err)
+	}
+	return re, nil
+}
+
+// checkSlice reports an invalid Options.Slice.
+func (o Options) checkSlice() error {
+	if o.Slice == nil {
+		return nil
+	}
+	_, err := o.Slice.compile()
+	return err
+}
+
+// slice applies Slice to g.
+func (o Options) slice(g *Graph) {
+	if o.Slice == nil {
+		return
+	}
+	re, err := o.Slice.compile()
+	if err != nil {
+		o.warnf("%v", err)
+		return
+	}
+	depth := o.Slice.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	if g.Slice(re, depth) == 0 {
+		o.warnf("slice %q matches no function", o.Slice.NameRegex)
+	}
+}
+
+// Slice trims the graph to the union of the neighborhoods, depth calls
+// deep in either direction, of the nodes whose Name, QualifiedName,
+// FilePath or PackageOrModule matches re. It returns the number of
+// matching nodes; with none, the graph is emptied.
+func (g *Graph) Slice(re *regexp.Regexp, depth int) int {
+	var matches []string
+	for _, n := range g.Nodes {
+		if re.MatchString(n.Name) || re.MatchString(n.QualifiedName) || re.MatchString(n.FilePath) || re.MatchString(n.PackageOrModule) {
+			matches = append(matches, n.ID)
+		}
+	}
+	if len(matches) == 0 {
+		g.retain(func(Node) bool { return false })
+		return 0
+	}
+	g.Prune(matches, depth)
+	return len(matches)
+}
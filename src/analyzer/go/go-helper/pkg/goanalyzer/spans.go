@@ -0,0 +1,74 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// Tracing libraries whose span-starting calls take the span name as an
+// argument. argIndex is the position of the name.
+var spanStarters = []struct {
+	pkgPrefix string
+	recv      string // "" for package-level functions
+	name      string
+	argIndex  int
+}{
+	{"go.opentelemetry.io/otel", "Tracer", "Start", 1},
+	{"go.opentelemetry.io/otel", "Span", "SetName", 0},
+	{"go.opencensus.io/trace", "", "StartSpan", 1},
+	{"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer", "", "StartSpan", 0},
+	{"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer", "", "StartSpanFromContext", 1},
+	{"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer", "", "StartSpan", 0},
+	{"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer", "", "StartSpanFromContext", 1},
+	{"github.com/opentracing/opentracing-go", "", "StartSpanFromContext", 1},
+	{"github.com/opentracing/opentracing-go", "Tracer", "StartSpan", 0},
+}
+
+// collectSpans returns the constant span names started or assigned in body.
+func collectSpans(body *ast.BlockStmt, ctx *passContext) []string {
+	var spans []string
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		target, ok := ctx.resolveCall(call)
+		if !ok {
+			return true
+		}
+		idx := spanNameArg(target, ctx.info != nil)
+		if idx < 0 || idx >= len(call.Args) {
+			return true
+		}
+		if name, ok := ctx.constString(call.Args[idx]); ok {
+			spans = append(spans, name)
+		}
+		return true
+	})
+	return sortedUnique(spans)
+}
+
+// spanNameArg returns the index of the span-name argument if target starts
+// or names a span, or -1. Without type information, method calls are matched
+// by name when the receiver expression looks like a tracer or span.
+func spanNameArg(target callTarget, typed bool) int {
+	for _, s := range spanStarters {
+		if target.name != s.name {
+			continue
+		}
+		if target.pkgPath != "" {
+			if target.inPackage(s.pkgPrefix) && (s.recv == "" || s.recv == target.recv) {
+				return s.argIndex
+			}
+			continue
+		}
+		if typed || s.recv == "" {
+			continue
+		}
+		recv := strings.ToLower(target.recvText())
+		if strings.Contains(recv, strings.ToLower(s.recv)) {
+			return s.argIndex
+		}
+	}
+	return -1
+}
@@ -0,0 +1,86 @@
+package goanalyzer
+
+import (
+	"go/ast"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SQLQuery is a SQL statement run or prepared by a function.
+type SQLQuery struct {
+	// Op is the statement's leading keyword: SELECT, INSERT, UPDATE, ...
+	Op string `json:"op"`
+	// Tables are the tables named after FROM, JOIN, INTO, UPDATE or TABLE
+	// in the constant part of the statement.
+	Tables []string `json:"tables,omitempty"`
+	Line   int      `json:"line"`
+}
+
+// sqlFuncs are the methods of database/sql, sqlx, pgx and gorm that take a
+// statement. The statement is recognized by its shape rather than by the
+// receiver, so that wrappers of these libraries are covered too.
+var sqlFuncs = map[string]bool{
+	"Query": true, "QueryContext": true, "QueryRow": true, "QueryRowContext": true,
+	"Exec": true, "ExecContext": true, "Prepare": true, "PrepareContext": true,
+	"Select": true, "SelectContext": true, "Get": true, "GetContext": true,
+	"NamedExec": true, "NamedExecContext": true, "NamedQuery": true, "NamedQueryContext": true,
+	"Queryx": true, "QueryRowx": true, "MustExec": true, "Raw": true,
+}
+
+var (
+	sqlStatement = regexp.MustCompile(`(?is)^\s*(SELECT|INSERT|UPDATE|DELETE|WITH|REPLACE|MERGE|UPSERT|CREATE|ALTER|DROP|TRUNCATE)\b`)
+	sqlTable     = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE|TABLE)\s+(?:IF\s+(?:NOT\s+)?EXISTS\s+)?([A-Za-z_"` + "`" + `][\w."` + "`" + `]*)`)
+)
+
+// sqlKeywords can follow the words sqlTable looks for without naming a
+// table: "ON CONFLICT DO UPDATE SET", "FROM LATERAL", "FROM ONLY".
+var sqlKeywords = map[string]bool{"SET": true, "SELECT": true, "LATERAL": true, "ONLY": true}
+
+// collectSQLQueries returns the SQL statements passed to sqlFuncs in body
+// whose constant part (see constPrefix) starts with a SQL keyword.
+func collectSQLQueries(body *ast.BlockStmt, ctx *passContext) []SQLQuery {
+	var queries []SQLQuery
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		target, ok := ctx.resolveCall(call)
+		if !ok || !sqlFuncs[target.name] {
+			return true
+		}
+		for _, arg := range call.Args {
+			stmt := ctx.constPrefix(arg, false)
+			m := sqlStatement.FindStringSubmatch(stmt)
+			if m == nil {
+				continue
+			}
+			queries = append(queries, SQLQuery{
+				Op:     strings.ToUpper(m[1]),
+				Tables: sqlTables(stmt),
+				Line:   ctx.fset.Position(call.Pos()).Line,
+			})
+			break
+		}
+		return true
+	})
+	return queries
+}
+
+// sqlTables returns the sorted, distinct tables a statement names, without
+// quotes.
+func sqlTables(stmt string) []string {
+	seen := make(map[string]bool)
+	var tables []string
+	for _, m := range sqlTable.FindAllStringSubmatch(stmt, -1) {
+		t := strings.Trim(strings.NewReplacer(`"`, "", "`", "").Replace(m[1]), ".")
+		if t == "" || seen[t] || sqlKeywords[strings.ToUpper(t)] {
+			continue
+		}
+		seen[t] = true
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
@@ -0,0 +1,117 @@
+package goanalyzer
+
+import (
+	"runtime"
+	"time"
+)
+
+// Stats describes an analysis run, for tuning large-repo runs and tracking
+// analyzer performance across versions.
+type Stats struct {
+	// Algorithm is the one that produced the graph; AlgorithmAST when the
+	// type-aware analysis fell back.
+	Algorithm      string `json:"algorithm"`
+	PackagesLoaded int    `json:"packagesLoaded"`
+	FilesParsed    int    `json:"filesParsed"`
+	// LoadCached reports that the packages were reused from Options.Cache
+	// rather than loaded.
+	LoadCached bool `json:"loadCached"`
+
+	NodesByKind map[string]int `json:"nodesByKind"`
+	EdgesByKind map[string]int `json:"edgesByKind"`
+
+	// UnresolvedCalls counts call sites whose target could not be determined:
+	// calls through function values and fields, calls on project interfaces
+	// without a project implementation and, without type information, calls
+	// to names not declared in the project. ExternalCalls counts calls to
+	// functions and interfaces outside the project (without type information,
+	// qualified by an imported package name).
+	UnresolvedCalls int `json:"unresolvedCalls"`
+	ExternalCalls   int `json:"externalCalls"`
+
+	// Interface dispatch: call sites on interface values, the edges they fan
+	// out to, and the largest fan-out of a single call site.
+	InterfaceCallSites int `json:"interfaceCallSites"`
+	InterfaceEdges     int `json:"interfaceEdges"`
+	MaxInterfaceFanOut int `json:"maxInterfaceFanOut"`
+
+	// Call patterns, counted per edge (see Edge.ArgCounts): calls to
+	// variadic functions, and edges passing a literal nil to a parameter.
+	VariadicCalls    int `json:"variadicCalls"`
+	NilArgumentEdges int `json:"nilArgumentEdges"`
+
+	// Options.MaxGeneratedMethods: the large generated types linked through
+	// a single node, and the constructor edges to their methods saved.
+	GeneratedTypesSummarized    int `json:"generatedTypesSummarized"`
+	GeneratedMethodEdgesSkipped int `json:"generatedMethodEdgesSkipped"`
+
+	Phases []PhaseTiming `json:"phases"`
+	// PeakMemoryBytes is the memory obtained from the OS by the end of the
+	// run (runtime.MemStats.Sys). The runtime rarely returns address space,
+	// so it bounds the peak.
+	PeakMemoryBytes uint64 `json:"peakMemoryBytes"`
+
+	// explain collects the decisions behind Options.Explain, if set.
+	explain *explainer
+	// truncated reports the phases that ran over Options.Budgets.
+	truncated []Diagnostic
+	// sinceDirs are the package directories Options.SinceRef restricts the
+	// output to.
+	sinceDirs map[string]bool
+}
+
+// PhaseTiming is the wall-clock duration of one analysis phase.
+type PhaseTiming struct {
+	Name       string  `json:"name"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// phase records the time since start under name and returns the current
+// time, so consecutive phases can be chained:
+//
+//	t := time.Now()
+//	...
+//	t = stats.phase("load", t)
+func (s *Stats) phase(name string, start time.Time) time.Time {
+	now := time.Now()
+	s.Phases = append(s.Phases, PhaseTiming{
+		Name:       name,
+		DurationMs: float64(now.Sub(start).Microseconds()) / 1000,
+	})
+	return now
+}
+
+// interfaceCall records an interface method call site dispatching to n
+// implementations.
+func (s *Stats) interfaceCall(n int) {
+	s.InterfaceCallSites++
+	s.InterfaceEdges += n
+	if n == 0 {
+		s.UnresolvedCalls++
+	}
+	if n > s.MaxInterfaceFanOut {
+		s.MaxInterfaceFanOut = n
+	}
+}
+
+// finish fills in the counts derived from the finished graph.
+func (s *Stats) finish(g *Graph) {
+	s.NodesByKind = make(map[string]int)
+	for _, n := range g.Nodes {
+		s.NodesByKind[n.Kind]++
+	}
+	s.EdgesByKind = make(map[string]int)
+	s.VariadicCalls, s.NilArgumentEdges = 0, 0
+	for _, e := range g.Edges {
+		s.EdgesByKind[e.Kind]++
+		if e.Variadic {
+			s.VariadicCalls += len(e.ArgCounts)
+		}
+		if len(e.NilParameters) > 0 {
+			s.NilArgumentEdges++
+		}
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	s.PeakMemoryBytes = m.Sys
+}
@@ -0,0 +1,129 @@
+package goanalyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+)
+
+// externalGraphs holds the library graphs of Options.ExternalGraphs, with
+// their nodes renamed after their import path so that they cannot clash
+// with the project's ("github.com/org/lib/retry/retry.go:Do", as for
+// Options.ResolveIntoDeps).
+type externalGraphs struct {
+	nodes []Node
+	edges []Edge
+	// bySymbol maps SymbolIDs to the renamed node IDs.
+	bySymbol map[string]string
+}
+
+// loadExternalGraphs reads Options.ExternalGraphs, warning about the files
+// that cannot be read. It returns nil if there are none.
+func loadExternalGraphs(input Options) *externalGraphs {
+	if len(input.ExternalGraphs) == 0 {
+		return nil
+	}
+	x := &externalGraphs{bySymbol: make(map[string]string)}
+	for _, file := range input.ExternalGraphs {
+		data, err := os.ReadFile(resolvePath(input.ProjectRoot, file))
+		if err != nil {
+			input.warnf("external graph skipped: %v", err)
+			continue
+		}
+		var g Graph
+		if err := json.Unmarshal(data, &g); err != nil {
+			input.warnf("external graph %s skipped: %v", file, err)
+			continue
+		}
+		x.add(g)
+	}
+	return x
+}
+
+// add renames and keeps the nodes of g that another module can call: those
+// with a SymbolID outside package main, not already known from an earlier
+// graph. Edges between kept nodes are kept too.
+func (x *externalGraphs) add(g Graph) {
+	ids := make(map[string]string, len(g.Nodes))
+	files := make(map[string]string)
+	for _, n := range g.Nodes {
+		if n.SymbolID == "" || n.FilePath == "" || strings.HasPrefix(n.SymbolID, "main.") {
+			continue
+		}
+		if _, ok := x.bySymbol[n.SymbolID]; ok {
+			continue
+		}
+		pkgPath := symbolPackage(n.SymbolID)
+		file := pkgPath + "/" + path.Base(n.FilePath)
+		oldID, oldFile := n.ID, n.FilePath
+		n.ID = rebaseID(n.ID, n.FilePath, file)
+		n.QualifiedName = rebaseID(n.QualifiedName, n.FilePath, file)
+		n.FilePath, n.PackageOrModule = file, pkgPath
+		n.Dependency = true
+		// The library's entry points are not this project's: its functions
+		// are live only if the project reaches them.
+		n.IsEntryPoint, n.RegisteredBy, n.ReachableFrom = false, "", nil
+		ids[oldID] = n.ID
+		files[oldFile] = file
+		x.bySymbol[n.SymbolID] = n.ID
+		x.nodes = append(x.nodes, n)
+	}
+	for _, e := range g.Edges {
+		source, ok := ids[e.Source]
+		if !ok {
+			continue
+		}
+		e.Source = source
+		if target, ok := ids[e.Target]; ok {
+			e.Target = target
+		} else if e.IsResolved {
+			continue
+		}
+		if file, ok := files[e.CallSite.FilePath]; ok {
+			e.CallSite.FilePath = file
+		}
+		x.edges = append(x.edges, e)
+	}
+}
+
+// has reports whether symbol is a function of the external graphs.
+func (x *externalGraphs) has(symbol string) bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.bySymbol[symbol]
+	return ok
+}
+
+// stitch adds the external nodes and edges to the graph and links the
+// unresolved edges targeting their SymbolIDs (see resolveCallsTyped).
+func (x *externalGraphs) stitch(nodes *[]Node, edges *[]Edge) {
+	if x == nil {
+		return
+	}
+	*nodes = append(*nodes, x.nodes...)
+	for _, e := range x.edges {
+		// Calls between libraries are kept if both are known.
+		if e.IsResolved || x.has(e.Target) {
+			*edges = append(*edges, e)
+		}
+	}
+	for i := range *edges {
+		e := &(*edges)[i]
+		if id, ok := x.bySymbol[e.Target]; ok && !e.IsResolved {
+			e.Target, e.IsResolved = id, true
+			e.Provenance.Rule = ruleExternalGraph
+		}
+	}
+}
+
+// symbolPackage returns the package path of a linker symbol:
+// "example.com/lib/retry.(*T).Do" → "example.com/lib/retry".
+func symbolPackage(symbol string) string {
+	slash := strings.LastIndex(symbol, "/") + 1
+	if dot := strings.Index(symbol[slash:], "."); dot >= 0 {
+		return symbol[:slash+dot]
+	}
+	return symbol
+}
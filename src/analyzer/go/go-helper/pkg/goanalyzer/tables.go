@@ -0,0 +1,107 @@
+package goanalyzer
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Tabular report kinds for Options.Reports: the nodes and edges as flat
+// tables for spreadsheets and SQL, in codegraph-nodes.<ext> and
+// codegraph-edges.<ext>, or both CSV files in codegraph-tables.zip.
+const (
+	ReportCSV = "csv"
+	ReportTSV = "tsv"
+	ReportZip = "zip"
+)
+
+var nodeColumns = []string{
+	"id", "name", "qualifiedName", "package", "file", "startLine", "endLine", "linesOfCode",
+	"sloc", "commentLines", "blankLines", "kind", "visibility", "status", "isEntryPoint",
+	"fanIn", "fanOut", "unusedParameters", "owners", "keptBy",
+}
+
+var edgeColumns = []string{
+	"source", "target", "kind", "isResolved", "file", "line", "column", "weight", "phase", "rule",
+}
+
+// WriteNodeTable writes one row per node, with its fan-in and fan-out
+// counted over distinct callers and callees. sep is ',' or '\t'.
+func WriteNodeTable(w io.Writer, g *Graph, sep rune) error {
+	fanIn, fanOut := fanCounts(g)
+	t := csv.NewWriter(w)
+	t.Comma = sep
+	t.Write(nodeColumns)
+	for _, n := range g.Nodes {
+		t.Write([]string{
+			n.ID, n.Name, n.QualifiedName, n.PackageOrModule, n.FilePath,
+			strconv.Itoa(n.StartLine), strconv.Itoa(n.EndLine), strconv.Itoa(n.LinesOfCode),
+			n.Kind, n.Visibility, n.Status, strconv.FormatBool(n.IsEntryPoint),
+			strconv.Itoa(fanIn[n.ID]), strconv.Itoa(fanOut[n.ID]),
+			strings.Join(n.UnusedParameters, " "), strings.Join(n.Owners, " "), n.KeptBy,
+		})
+	}
+	t.Flush()
+	return t.Error()
+}
+
+// WriteEdgeTable writes one row per edge. sep is ',' or '\t'.
+func WriteEdgeTable(w io.Writer, g *Graph, sep rune) error {
+	t := csv.NewWriter(w)
+	t.Comma = sep
+	t.Write(edgeColumns)
+	for _, e := range g.Edges {
+		t.Write([]string{
+			e.Source, e.Target, e.Kind, strconv.FormatBool(e.IsResolved),
+			e.CallSite.FilePath, strconv.Itoa(e.CallSite.Line), strconv.Itoa(e.CallSite.Column),
+			strconv.FormatFloat(e.Weight, 'g', -1, 64), e.Provenance.Phase, e.Provenance.Rule,
+		})
+	}
+	t.Flush()
+	return t.Error()
+}
+
+// writeTableZip writes the CSV node and edge tables into one zip archive.
+func writeTableZip(w io.Writer, g *Graph) error {
+	z := zip.NewWriter(w)
+	for _, table := range []struct {
+		name  string
+		write func(io.Writer, *Graph, rune) error
+	}{
+		{"codegraph-nodes.csv", WriteNodeTable},
+		{"codegraph-edges.csv", WriteEdgeTable},
+	} {
+		f, err := z.Create(table.name)
+		if err != nil {
+			return err
+		}
+		if err := table.write(f, g, ','); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// countCalls sets Node.CallerCount and Node.CalleeCount.
+func (g *Graph) countCalls() {
+	fanIn, fanOut := fanCounts(g)
+	for i := range g.Nodes {
+		g.Nodes[i].CallerCount, g.Nodes[i].CalleeCount = fanIn[g.Nodes[i].ID], fanOut[g.Nodes[i].ID]
+	}
+}
+
+// fanCounts counts each node's distinct callers and callees.
+func fanCounts(g *Graph) (fanIn, fanOut map[string]int) {
+	fanIn, fanOut = make(map[string]int), make(map[string]int)
+	seen := make(map[[2]string]bool, len(g.Edges))
+	for _, e := range g.Edges {
+		if key := [2]string{e.Source, e.Target}; !seen[key] {
+			seen[key] = true
+			fanOut[e.Source]++
+			fanIn[e.Target]++
+		}
+	}
+	return fanIn, fanOut
+}
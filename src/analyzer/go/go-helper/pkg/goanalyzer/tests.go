@@ -0,0 +1,131 @@
+package goanalyzer
+
+import (
+	"context"
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// isTestFile reports whether path is a _test.go file.
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
+// addTests adds to g the functions declared in the project's _test.go
+// files and their calls and references, for Options.Tests. The packages are
+// loaded again with their tests, as for collectReferences, so functions
+// are matched to nodes by SymbolID; calls from tests are resolved
+// statically, without interface dispatch.
+func addTests(ctx context.Context, input Options, g *Graph) error {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedCompiledGoFiles |
+			packages.NeedSyntax |
+			packages.NeedTypes |
+			packages.NeedTypesInfo,
+		Dir:     input.ProjectRoot,
+		Overlay: input.absOverlays(),
+		Env:     input.packagesEnv(),
+		Tests:   true,
+	}
+	if input.build != nil {
+		cfg.BuildFlags = input.build.buildFlags()
+	}
+	pkgs, err := packages.Load(cfg, input.projectPatterns()...)
+	if err != nil {
+		return err
+	}
+	absRoot, _ := filepath.Abs(input.ProjectRoot)
+
+	bySymbol := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		if n.SymbolID != "" {
+			bySymbol[n.SymbolID] = n.ID
+		}
+	}
+
+	type testFunc struct {
+		decl *ast.FuncDecl
+		pkg  *packages.Package
+		node Node
+	}
+	var funcs []testFunc
+	// A package and its test variant share files; visit each file once.
+	visited := make(map[string]bool)
+	for _, pkg := range filterProjectPackages(pkgs, absRoot) {
+		for i, file := range pkg.Syntax {
+			absPath := pkg.CompiledGoFiles[i]
+			relPath, err := filepath.Rel(absRoot, absPath)
+			if err != nil || !isTestFile(absPath) || visited[absPath] {
+				continue
+			}
+			visited[absPath] = true
+			relPath = filepath.ToSlash(relPath)
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				node := buildNodeTyped(funcDecl, file.Comments, pkg.Fset, pkg.TypesInfo, relPath, pkg.Name, fn)
+				if _, ok := bySymbol[node.SymbolID]; ok {
+					continue
+				}
+				bySymbol[node.SymbolID] = node.ID
+				funcs = append(funcs, testFunc{decl: funcDecl, pkg: pkg, node: node})
+			}
+		}
+	}
+
+	for _, f := range funcs {
+		g.Nodes = append(g.Nodes, f.node)
+		if f.decl.Body == nil {
+			continue
+		}
+		info := f.pkg.TypesInfo
+		calls := make(map[*ast.Ident]bool)
+		linked := make(map[string]bool)
+		ast.Inspect(f.decl.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				if id := calledIdent(n); id != nil {
+					calls[id] = true
+				}
+			case *ast.Ident:
+				fn, ok := info.Uses[n].(*types.Func)
+				if !ok || fn.Pkg() == nil {
+					return true
+				}
+				target, ok := bySymbol[funcSymbol(fn.Origin())]
+				if !ok || target == f.node.ID || linked[target] {
+					return true
+				}
+				linked[target] = true
+				kind, rule := "direct", ruleCall
+				if !calls[n] {
+					kind, rule = "funcref", ruleFuncValue
+				}
+				pos := f.pkg.Fset.Position(n.Pos())
+				g.Edges = append(g.Edges, Edge{
+					Source:     f.node.ID,
+					Target:     target,
+					CallSite:   CallSite{FilePath: f.node.FilePath, Line: pos.Line, Column: pos.Column},
+					Kind:       kind,
+					IsResolved: true,
+					Provenance: newProvenance("tests", rule, n, input.Debug),
+				})
+			}
+			return true
+		})
+	}
+	return nil
+}
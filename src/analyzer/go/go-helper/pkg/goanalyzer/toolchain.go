@@ -0,0 +1,115 @@
+package goanalyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/version"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// goVersionPattern matches Options.GoVersion: "1.21", "1.22.3" or "go1.22.3".
+var goVersionPattern = regexp.MustCompile(`^(?:go)?1\.(\d+)(?:\.\d+)?$`)
+
+// checkGoVersion validates Options.GoVersion. Toolchains are selected with
+// GOTOOLCHAIN, which Go 1.21 introduced.
+func (o Options) checkGoVersion() error {
+	if o.GoVersion == "" {
+		return nil
+	}
+	m := goVersionPattern.FindStringSubmatch(o.GoVersion)
+	if m == nil {
+		return fmt.Errorf("goVersion %q is not a Go version such as \"1.22\" or \"1.22.3\"", o.GoVersion)
+	}
+	if version.Compare(o.goToolchainVersion(), "go1.21.0") < 0 {
+		return fmt.Errorf("goVersion %q: toolchains before Go 1.21 cannot be selected", o.GoVersion)
+	}
+	return nil
+}
+
+// goToolchainVersion returns Options.GoVersion as a toolchain name:
+// "1.22" is its first release, "go1.22.0".
+func (o Options) goToolchainVersion() string {
+	v := "go" + strings.TrimPrefix(o.GoVersion, "go")
+	if strings.Count(v, ".") == 1 {
+		v += ".0"
+	}
+	return v
+}
+
+// goToolchainEnv is the GOTOOLCHAIN setting for Options.GoVersion. With
+// "+auto" a toolchain or go directive in go.mod asking for a newer one
+// still wins, as it would with the go command's default.
+func (o Options) goToolchainEnv() string {
+	return "GOTOOLCHAIN=" + o.goToolchainVersion() + "+auto"
+}
+
+// checkToolchain runs the go command type-aware loads will run, and
+// reports why it cannot load the project: it fails, for instance because
+// go.mod requires a newer Go than GOTOOLCHAIN allows, or it is newer than
+// the Go the helper was built with, whose go/types may not read its export
+// data or language features. Projects loaded through a packages driver are
+// not checked. With Options.Cache, the result is reused until a module
+// file changes.
+func (o Options) checkToolchain(ctx context.Context) error {
+	if o.driver != "" {
+		return nil
+	}
+	absRoot, err := filepath.Abs(o.ProjectRoot)
+	if err != nil {
+		return err
+	}
+	return o.Cache.checkToolchain(ctx, absRoot, o.packagesEnv(), func() error { return o.runToolchainCheck(ctx) })
+}
+
+// runToolchainCheck runs the go commands of checkToolchain.
+func (o Options) runToolchainCheck(ctx context.Context) error {
+	out, err := o.goCommand(ctx, "env", "GOVERSION", "GOMOD")
+	if err != nil {
+		return err
+	}
+	running, gomod, _ := strings.Cut(out, "\n")
+	if gomod = strings.TrimSpace(gomod); gomod != "" && gomod != os.DevNull {
+		// go env does not check the go.mod requirements; go list -m does.
+		if _, err := o.goCommand(ctx, "list", "-m"); err != nil {
+			return err
+		}
+	}
+	if version.IsValid(running) && version.IsValid(runtime.Version()) &&
+		version.Compare(version.Lang(running), version.Lang(runtime.Version())) > 0 {
+		return fmt.Errorf("the project's Go toolchain %s is newer than the %s the helper was built with; set goVersion or rebuild the helper", running, runtime.Version())
+	}
+	return nil
+}
+
+// goCommand runs the go command in the project root with the environment
+// of type-aware loads and returns its trimmed output.
+func (o Options) goCommand(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = o.ProjectRoot
+	cmd.Env = o.packagesEnv()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := firstLines(stderr.String(), 3); msg != "" {
+			return "", fmt.Errorf("go toolchain unusable: %s", msg)
+		}
+		return "", fmt.Errorf("go toolchain unusable: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// toolchainDiagnostic reports a checkToolchain error, against go.mod when
+// the project has one.
+func (o Options) toolchainDiagnostic(err error) Diagnostic {
+	d := Diagnostic{Kind: "toolchain", Message: err.Error()}
+	if _, statErr := os.Stat(resolvePath(o.ProjectRoot, "go.mod")); statErr == nil {
+		d.FilePath = "go.mod"
+	}
+	return d
+}
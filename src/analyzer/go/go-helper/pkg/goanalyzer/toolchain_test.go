@@ -0,0 +1,88 @@
+package goanalyzer_test
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+	"golang.org/x/tools/go/gcexportdata"
+)
+
+var (
+	exportDataOnce sync.Once
+	exportDataErr  error
+)
+
+// requireTypedAnalysis skips the test when the installed Go toolchain writes
+// export data this version of x/tools cannot read. go/packages treats that as
+// an internal error and exits the process, so it must be detected up front.
+func requireTypedAnalysis(t testing.TB) {
+	t.Helper()
+	exportDataOnce.Do(func() {
+		out, err := exec.Command("go", "list", "-export", "-f", "{{.Export}}", "fmt").Output()
+		if err != nil {
+			exportDataErr = fmt.Errorf("go list -export: %w", err)
+			return
+		}
+		f, err := os.Open(strings.TrimSpace(string(out)))
+		if err != nil {
+			exportDataErr = err
+			return
+		}
+		defer f.Close()
+		r, err := gcexportdata.NewReader(f)
+		if err == nil {
+			_, err = gcexportdata.Read(r, token.NewFileSet(), make(map[string]*types.Package), "fmt")
+		}
+		exportDataErr = err
+	})
+	if exportDataErr != nil {
+		t.Skipf("type-aware analysis unavailable with this toolchain: %v", exportDataErr)
+	}
+}
+
+// TestToolchainCheckCached runs the go command through a wrapper logging
+// its arguments, and expects a single toolchain check for repeated
+// analyses sharing a LoadCache.
+func TestToolchainCheckCached(t *testing.T) {
+	requireTypedAnalysis(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script as the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	bin, root := t.TempDir(), t.TempDir()
+	log := filepath.Join(bin, "go.log")
+	writeFile(t, bin, "go", fmt.Sprintf("#!/bin/sh\necho \"$*\" >> %q\nexec %q \"$@\"\n", log, goCmd))
+	if err := os.Chmod(filepath.Join(bin, "go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	writeFile(t, root, "go.mod", "module example.com/app\n\ngo 1.24\n")
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+
+	opts := goanalyzer.Options{ProjectRoot: root, Cache: goanalyzer.NewLoadCache()}
+	for range 3 {
+		if _, err := goanalyzer.Analyze(context.Background(), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "env GOVERSION GOMOD"); n != 1 {
+		t.Errorf("toolchain checked %d times, want 1:\n%s", n, data)
+	}
+}
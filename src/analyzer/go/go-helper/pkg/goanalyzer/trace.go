@@ -0,0 +1,145 @@
+package goanalyzer
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// executionTrace is the set of functions (and optionally caller→callee pairs)
+// observed in a real run, gathered from Options.Executed and Options.ExecutionLog.
+type executionTrace struct {
+	funcs map[string]bool // node IDs or linker symbols
+	pairs map[[2]string]bool
+	// covered holds line ranges with a non-zero count from a Go coverage
+	// profile, keyed by project-relative file path.
+	covered map[string][][2]int
+}
+
+// loadExecutionTrace collects identifiers from the inline list and the log file.
+// Each entry is a node ID or a linker symbol ("pkg/path.(*T).M"); an entry of
+// the form "caller -> callee" records an observed call. A log file starting
+// with "mode:" is read as a `go test -coverprofile` file instead.
+func loadExecutionTrace(input Options) (*executionTrace, error) {
+	tr := &executionTrace{
+		funcs:   make(map[string]bool),
+		pairs:   make(map[[2]string]bool),
+		covered: make(map[string][][2]int),
+	}
+	for _, id := range input.Executed {
+		tr.add(id)
+	}
+
+	if input.ExecutionLog == "" {
+		return tr, nil
+	}
+	f, err := os.Open(resolvePath(input.ProjectRoot, input.ExecutionLog))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	first := true
+	coverMode := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			first = false
+			if strings.HasPrefix(line, "mode:") {
+				coverMode = true
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if coverMode {
+			tr.addCoverBlock(line, input.Module)
+		} else {
+			tr.add(line)
+		}
+	}
+	return tr, scanner.Err()
+}
+
+func (tr *executionTrace) add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if caller, callee, ok := strings.Cut(entry, "->"); ok {
+		caller = normalizeProfileSymbol(strings.TrimSpace(caller))
+		callee = normalizeProfileSymbol(strings.TrimSpace(callee))
+		tr.pairs[[2]string{caller, callee}] = true
+		tr.funcs[caller] = true
+		tr.funcs[callee] = true
+		return
+	}
+	if entry != "" {
+		tr.funcs[normalizeProfileSymbol(entry)] = true
+	}
+}
+
+// addCoverBlock parses "path/file.go:12.5,14.2 3 1" and keeps blocks that ran.
+func (tr *executionTrace) addCoverBlock(line, module string) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[2] == "0" {
+		return
+	}
+	file, rng, ok := strings.Cut(fields[0], ":")
+	if !ok {
+		return
+	}
+	start, end, ok := strings.Cut(rng, ",")
+	if !ok {
+		return
+	}
+	startLine, err1 := strconv.Atoi(strings.Split(start, ".")[0])
+	endLine, err2 := strconv.Atoi(strings.Split(end, ".")[0])
+	if err1 != nil || err2 != nil {
+		return
+	}
+	if module != "" {
+		file = strings.TrimPrefix(file, module+"/")
+	}
+	tr.covered[file] = append(tr.covered[file], [2]int{startLine, endLine})
+}
+
+func (tr *executionTrace) observedNode(n Node) bool {
+	if tr.funcs[n.ID] || (n.SymbolID != "" && tr.funcs[n.SymbolID]) {
+		return true
+	}
+	for _, r := range tr.covered[n.FilePath] {
+		if r[0] >= n.StartLine && r[0] <= n.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// applyExecutionTrace sets ObservedAtRuntime on every node and edge. Edges are
+// observed when the trace names the pair explicitly or, for traces that only
+// list functions, when both endpoints were observed.
+func applyExecutionTrace(output *Graph, tr *executionTrace) {
+	observed := make(map[string]bool, len(output.Nodes))
+	symbols := make(map[string]string, len(output.Nodes))
+	for i := range output.Nodes {
+		n := &output.Nodes[i]
+		ok := tr.observedNode(*n)
+		n.ObservedAtRuntime = &ok
+		observed[n.ID] = ok
+		symbols[n.ID] = n.SymbolID
+	}
+
+	for i := range output.Edges {
+		e := &output.Edges[i]
+		var ok bool
+		if len(tr.pairs) > 0 {
+			ok = tr.pairs[[2]string{e.Source, e.Target}] ||
+				tr.pairs[[2]string{symbols[e.Source], symbols[e.Target]}]
+		} else {
+			ok = observed[e.Source] && observed[e.Target]
+		}
+		e.ObservedAtRuntime = &ok
+	}
+}
@@ -0,0 +1,30 @@
+package goanalyzer_test
+
+import (
+	"testing"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+func TestCoverProfileModulePrefix(t *testing.T) {
+	graph := analyzeTyped(t, map[string]string{
+		"main.go":   "package main\n\nimport \"example.com/app/util\"\n\nfunc main() { util.X() }\n",
+		"util/x.go": "package util\n\nfunc X() {}\n",
+		// The second block is in another module whose path starts with
+		// this one's.
+		"cover.out": "mode: set\nexample.com/app/main.go:5.13,5.23 1 1\nexample.com/apputil/x.go:3.12,3.13 0 1\n",
+	}, goanalyzer.Options{ExecutionLog: "cover.out"})
+
+	for id, want := range map[string]bool{"main.go:main": true, "util/x.go:X": false} {
+		n, err := graph.FindNode(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.ObservedAtRuntime == nil {
+			t.Fatalf("%s has no observedAtRuntime", id)
+		}
+		if got := *n.ObservedAtRuntime; got != want {
+			t.Errorf("%s observed at runtime = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package goanalyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// Policies for Options.TrivialWrappers.
+const (
+	// TrivialTag sets Node.IsTrivial on trivial functions.
+	TrivialTag = "tag"
+	// TrivialCollapse removes trivial functions from the output, linking
+	// their callers directly to their callees.
+	TrivialCollapse = "collapse"
+)
+
+// checkTrivialWrappers reports an unknown Options.TrivialWrappers policy.
+func (o Options) checkTrivialWrappers() error {
+	switch o.TrivialWrappers {
+	case "", TrivialTag, TrivialCollapse:
+		return nil
+	}
+	return fmt.Errorf("unknown trivialWrappers %q (want %q or %q)", o.TrivialWrappers, TrivialTag, TrivialCollapse)
+}
+
+// isTrivial reports whether funcDecl's body is a single statement that
+// only reads or writes a field of the receiver (a getter or setter) or
+// passes plain operands on to another call (pure delegation):
+//
+//	func (u *User) Name() string     { return u.name }
+//	func (u *User) SetName(n string) { u.name = n }
+//	func Open(path string) (*DB, error) { return open(path, defaultOptions) }
+func isTrivial(funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Body == nil || len(funcDecl.Body.List) != 1 {
+		return false
+	}
+	recv := ""
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) == 1 && len(funcDecl.Recv.List[0].Names) == 1 {
+		recv = funcDecl.Recv.List[0].Names[0].Name
+	}
+	switch stmt := funcDecl.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(stmt.Results) != 1 {
+			return false
+		}
+		if call, ok := stmt.Results[0].(*ast.CallExpr); ok {
+			return isDelegation(call)
+		}
+		return recv != "" && isFieldOf(stmt.Results[0], recv)
+	case *ast.ExprStmt:
+		call, ok := stmt.X.(*ast.CallExpr)
+		return ok && isDelegation(call)
+	case *ast.AssignStmt:
+		return recv != "" && stmt.Tok == token.ASSIGN && len(stmt.Lhs) == 1 && len(stmt.Rhs) == 1 &&
+			isFieldOf(stmt.Lhs[0], recv) && isOperand(stmt.Rhs[0])
+	}
+	return false
+}
+
+// isDelegation reports whether call is a call of a named function or
+// method with plain operands.
+func isDelegation(call *ast.CallExpr) bool {
+	if !isOperand(call.Fun) {
+		return false
+	}
+	for _, arg := range call.Args {
+		if !isOperand(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFieldOf reports whether expr is recv.f, recv.f.g and so on.
+func isFieldOf(expr ast.Expr, recv string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if id, ok := sel.X.(*ast.Ident); ok {
+		return id.Name == recv
+	}
+	return isFieldOf(sel.X, recv)
+}
+
+// isOperand reports whether expr is a name, a selector of names, a
+// literal, or the address of one of those: nothing that computes.
+func isOperand(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.SelectorExpr:
+		return isOperand(e.X)
+	case *ast.UnaryExpr:
+		return e.Op == token.AND && isOperand(e.X)
+	case *ast.ParenExpr:
+		return isOperand(e.X)
+	}
+	return false
+}
+
+// applyTrivialWrappers applies Options.TrivialWrappers to g. Collapsing
+// keeps entry points and kept functions, whose removal would lose a root,
+// and functions that call themselves; each call into a collapsed function
+// becomes a call, at the same site, to each of its callees, unless the
+// caller already calls that callee.
+func (o Options) applyTrivialWrappers(g *Graph) {
+	switch o.TrivialWrappers {
+	case TrivialTag:
+		for i := range g.Nodes {
+			g.Nodes[i].IsTrivial = g.Nodes[i].trivial
+		}
+	case TrivialCollapse:
+		collapse := make(map[string]bool)
+		for _, n := range g.Nodes {
+			if n.trivial && !n.IsEntryPoint && n.KeptBy == "" {
+				collapse[n.ID] = true
+			}
+		}
+		callees := make(map[string][]string)
+		for _, e := range g.Edges {
+			if collapse[e.Source] {
+				if e.Target == e.Source {
+					delete(collapse, e.Source)
+				}
+				callees[e.Source] = append(callees[e.Source], e.Target)
+			}
+		}
+		if len(collapse) == 0 {
+			return
+		}
+		exists := make(map[[2]string]bool, len(g.Edges))
+		for _, e := range g.Edges {
+			exists[[2]string{e.Source, e.Target}] = true
+		}
+		var added []Edge
+		for _, e := range g.Edges {
+			if collapse[e.Source] || !collapse[e.Target] {
+				continue
+			}
+			// Follow chains of wrappers to their first non-trivial callees.
+			seen := map[string]bool{e.Target: true}
+			queue := []string{e.Target}
+			for len(queue) > 0 {
+				id := queue[0]
+				queue = queue[1:]
+				for _, callee := range callees[id] {
+					if seen[callee] {
+						continue
+					}
+					seen[callee] = true
+					if collapse[callee] {
+						queue = append(queue, callee)
+						continue
+					}
+					if key := [2]string{e.Source, callee}; !exists[key] {
+						exists[key] = true
+						direct := e
+						direct.Target = callee
+						direct.Provenance = Provenance{Phase: "postprocess", Rule: ruleCollapsedWrapper}
+						added = append(added, direct)
+					}
+				}
+			}
+		}
+		g.Edges = append(g.Edges, added...)
+		g.retain(func(n Node) bool { return !collapse[n.ID] })
+	}
+}
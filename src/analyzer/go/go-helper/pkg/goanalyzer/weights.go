@@ -0,0 +1,127 @@
+package goanalyzer
+
+import "go/ast"
+
+// Static call-frequency estimates. A call inside a loop body is assumed to run
+// loopWeight times per invocation of the enclosing function; a call guarded by
+// an if/switch/select branch is assumed to run condWeight times. Nesting
+// multiplies, so a call in an if inside a for gets loopWeight * condWeight.
+const (
+	loopWeight = 10.0
+	condWeight = 0.5
+)
+
+// Call contexts reported on edges, from the strongest enclosing structure.
+const (
+	contextTopLevel    = "toplevel"
+	contextConditional = "conditional"
+	contextLoop        = "loop"
+)
+
+// callWeight is the estimated frequency of a single call site.
+type callWeight struct {
+	weight  float64
+	context string
+}
+
+// estimateCallWeights walks a function body and assigns a callWeight to every
+// call expression, selector and identifier based on the enclosing loops and
+// conditionals. Nodes outside any loop or branch get weight 1 ("toplevel").
+func estimateCallWeights(body *ast.BlockStmt) map[ast.Node]callWeight {
+	weights := make(map[ast.Node]callWeight)
+	if body == nil {
+		return weights
+	}
+
+	var walk func(n ast.Node, loops, conds int)
+	walk = func(n ast.Node, loops, conds int) {
+		ast.Inspect(n, func(child ast.Node) bool {
+			if child == nil {
+				return false
+			}
+
+			switch c := child.(type) {
+			case *ast.CallExpr, *ast.SelectorExpr, *ast.Ident:
+				weights[c] = newCallWeight(loops, conds)
+
+			case *ast.ForStmt:
+				walkOpt(walk, c.Init, loops, conds)
+				walkOpt(walk, c.Cond, loops+1, conds)
+				walkOpt(walk, c.Post, loops+1, conds)
+				walk(c.Body, loops+1, conds)
+				return false
+
+			case *ast.RangeStmt:
+				walkOpt(walk, c.Key, loops+1, conds)
+				walkOpt(walk, c.Value, loops+1, conds)
+				walk(c.X, loops, conds)
+				walk(c.Body, loops+1, conds)
+				return false
+
+			case *ast.IfStmt:
+				walkOpt(walk, c.Init, loops, conds)
+				walk(c.Cond, loops, conds)
+				walk(c.Body, loops, conds+1)
+				walkOpt(walk, c.Else, loops, conds+1)
+				return false
+
+			case *ast.SwitchStmt:
+				walkOpt(walk, c.Init, loops, conds)
+				walkOpt(walk, c.Tag, loops, conds)
+				walk(c.Body, loops, conds+1)
+				return false
+
+			case *ast.TypeSwitchStmt:
+				walkOpt(walk, c.Init, loops, conds)
+				walk(c.Assign, loops, conds)
+				walk(c.Body, loops, conds+1)
+				return false
+
+			case *ast.SelectStmt:
+				walk(c.Body, loops, conds+1)
+				return false
+			}
+			return true
+		})
+	}
+	walk(body, 0, 0)
+
+	return weights
+}
+
+// walkOpt calls walk on n unless it is absent (e.g. a for loop without Init).
+func walkOpt(walk func(ast.Node, int, int), n ast.Node, loops, conds int) {
+	if n == nil {
+		return
+	}
+	walk(n, loops, conds)
+}
+
+func newCallWeight(loops, conds int) callWeight {
+	w := 1.0
+	for i := 0; i < loops; i++ {
+		w *= loopWeight
+	}
+	for i := 0; i < conds; i++ {
+		w *= condWeight
+	}
+
+	ctx := contextTopLevel
+	if loops > 0 {
+		ctx = contextLoop
+	} else if conds > 0 {
+		ctx = contextConditional
+	}
+	return callWeight{weight: w, context: ctx}
+}
+
+// contextRank orders call contexts so merged edges keep the strongest one.
+func contextRank(ctx string) int {
+	switch ctx {
+	case contextLoop:
+		return 2
+	case contextConditional:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,1302 @@
+// The gRPC service of go-helper --grpc. Options travel as the same JSON
+// document as on the command line (schema/options.schema.json); graphs and
+// diffs are the messages below, mirroring the fields of goanalyzer.Graph
+// that make up the call graph. The optional sections of the JSON output
+// (summary, findings, routes, search index, ...) are not carried.
+//
+// The Go code in codegraphv1 is generated with protoc-gen-go and
+// protoc-gen-go-grpc:
+//
+//	protoc --go_out=. --go_opt=module=github.com/codegraph/go-helper \
+//	  --go-grpc_out=. --go-grpc_opt=module=github.com/codegraph/go-helper \
+//	  proto/codegraph.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: proto/codegraph.proto
+
+package codegraphv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AnalyzeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// options is a goanalyzer.Options JSON object (schema/options.schema.json).
+	Options       string `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRequest) Reset() {
+	*x = AnalyzeRequest{}
+	mi := &file_proto_codegraph_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRequest) ProtoMessage() {}
+
+func (x *AnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AnalyzeRequest) GetOptions() string {
+	if x != nil {
+		return x.Options
+	}
+	return ""
+}
+
+type QueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       string                 `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	Roots         []string               `protobuf:"bytes,2,rep,name=roots,proto3" json:"roots,omitempty"`
+	MaxDepth      int32                  `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_proto_codegraph_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *QueryRequest) GetOptions() string {
+	if x != nil {
+		return x.Options
+	}
+	return ""
+}
+
+func (x *QueryRequest) GetRoots() []string {
+	if x != nil {
+		return x.Roots
+	}
+	return nil
+}
+
+func (x *QueryRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+type DiffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OldGraph      *Graph                 `protobuf:"bytes,1,opt,name=old_graph,json=oldGraph,proto3" json:"old_graph,omitempty"`
+	NewGraph      *Graph                 `protobuf:"bytes,2,opt,name=new_graph,json=newGraph,proto3" json:"new_graph,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffRequest) Reset() {
+	*x = DiffRequest{}
+	mi := &file_proto_codegraph_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRequest) ProtoMessage() {}
+
+func (x *DiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRequest.ProtoReflect.Descriptor instead.
+func (*DiffRequest) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DiffRequest) GetOldGraph() *Graph {
+	if x != nil {
+		return x.OldGraph
+	}
+	return nil
+}
+
+func (x *DiffRequest) GetNewGraph() *Graph {
+	if x != nil {
+		return x.NewGraph
+	}
+	return nil
+}
+
+type WatchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*WatchEvent_Graph
+	//	*WatchEvent_Error
+	Event         isWatchEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_proto_codegraph_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WatchEvent) GetEvent() isWatchEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetGraph() *Graph {
+	if x != nil {
+		if x, ok := x.Event.(*WatchEvent_Graph); ok {
+			return x.Graph
+		}
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetError() string {
+	if x != nil {
+		if x, ok := x.Event.(*WatchEvent_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isWatchEvent_Event interface {
+	isWatchEvent_Event()
+}
+
+type WatchEvent_Graph struct {
+	Graph *Graph `protobuf:"bytes,1,opt,name=graph,proto3,oneof"`
+}
+
+type WatchEvent_Error struct {
+	// error is the message of a failed analysis.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*WatchEvent_Graph) isWatchEvent_Event() {}
+
+func (*WatchEvent_Error) isWatchEvent_Event() {}
+
+// Graph is goanalyzer.Graph.
+type Graph struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Edges         []*Edge                `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
+	Diagnostics   []*Diagnostic          `protobuf:"bytes,3,rep,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+	GraphHash     string                 `protobuf:"bytes,4,opt,name=graph_hash,json=graphHash,proto3" json:"graph_hash,omitempty"`
+	PackageHashes map[string]string      `protobuf:"bytes,5,rep,name=package_hashes,json=packageHashes,proto3" json:"package_hashes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Graph) Reset() {
+	*x = Graph{}
+	mi := &file_proto_codegraph_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Graph) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Graph) ProtoMessage() {}
+
+func (x *Graph) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Graph.ProtoReflect.Descriptor instead.
+func (*Graph) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Graph) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Graph) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *Graph) GetDiagnostics() []*Diagnostic {
+	if x != nil {
+		return x.Diagnostics
+	}
+	return nil
+}
+
+func (x *Graph) GetGraphHash() string {
+	if x != nil {
+		return x.GraphHash
+	}
+	return ""
+}
+
+func (x *Graph) GetPackageHashes() map[string]string {
+	if x != nil {
+		return x.PackageHashes
+	}
+	return nil
+}
+
+// Node is goanalyzer.Node.
+type Node struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	QualifiedName    string                 `protobuf:"bytes,3,opt,name=qualified_name,json=qualifiedName,proto3" json:"qualified_name,omitempty"`
+	FilePath         string                 `protobuf:"bytes,4,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	StartLine        int32                  `protobuf:"varint,5,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`
+	EndLine          int32                  `protobuf:"varint,6,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	Language         string                 `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	Kind             string                 `protobuf:"bytes,8,opt,name=kind,proto3" json:"kind,omitempty"`
+	Visibility       string                 `protobuf:"bytes,9,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	IsEntryPoint     bool                   `protobuf:"varint,10,opt,name=is_entry_point,json=isEntryPoint,proto3" json:"is_entry_point,omitempty"`
+	Parameters       []*Parameter           `protobuf:"bytes,11,rep,name=parameters,proto3" json:"parameters,omitempty"`
+	UnusedParameters []string               `protobuf:"bytes,12,rep,name=unused_parameters,json=unusedParameters,proto3" json:"unused_parameters,omitempty"`
+	PackageOrModule  string                 `protobuf:"bytes,13,opt,name=package_or_module,json=packageOrModule,proto3" json:"package_or_module,omitempty"`
+	LinesOfCode      int32                  `protobuf:"varint,14,opt,name=lines_of_code,json=linesOfCode,proto3" json:"lines_of_code,omitempty"`
+	Status           string                 `protobuf:"bytes,15,opt,name=status,proto3" json:"status,omitempty"`
+	Color            string                 `protobuf:"bytes,16,opt,name=color,proto3" json:"color,omitempty"`
+	SymbolId         string                 `protobuf:"bytes,17,opt,name=symbol_id,json=symbolId,proto3" json:"symbol_id,omitempty"`
+	BodyHash         string                 `protobuf:"bytes,18,opt,name=body_hash,json=bodyHash,proto3" json:"body_hash,omitempty"`
+	Dependency       bool                   `protobuf:"varint,19,opt,name=dependency,proto3" json:"dependency,omitempty"`
+	Approximate      bool                   `protobuf:"varint,20,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	RegisteredBy     string                 `protobuf:"bytes,21,opt,name=registered_by,json=registeredBy,proto3" json:"registered_by,omitempty"`
+	CallerCount      int32                  `protobuf:"varint,22,opt,name=caller_count,json=callerCount,proto3" json:"caller_count,omitempty"`
+	CalleeCount      int32                  `protobuf:"varint,23,opt,name=callee_count,json=calleeCount,proto3" json:"callee_count,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_proto_codegraph_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Node) GetQualifiedName() string {
+	if x != nil {
+		return x.QualifiedName
+	}
+	return ""
+}
+
+func (x *Node) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *Node) GetStartLine() int32 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+func (x *Node) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *Node) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Node) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Node) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
+
+func (x *Node) GetIsEntryPoint() bool {
+	if x != nil {
+		return x.IsEntryPoint
+	}
+	return false
+}
+
+func (x *Node) GetParameters() []*Parameter {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *Node) GetUnusedParameters() []string {
+	if x != nil {
+		return x.UnusedParameters
+	}
+	return nil
+}
+
+func (x *Node) GetPackageOrModule() string {
+	if x != nil {
+		return x.PackageOrModule
+	}
+	return ""
+}
+
+func (x *Node) GetLinesOfCode() int32 {
+	if x != nil {
+		return x.LinesOfCode
+	}
+	return 0
+}
+
+func (x *Node) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Node) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Node) GetSymbolId() string {
+	if x != nil {
+		return x.SymbolId
+	}
+	return ""
+}
+
+func (x *Node) GetBodyHash() string {
+	if x != nil {
+		return x.BodyHash
+	}
+	return ""
+}
+
+func (x *Node) GetDependency() bool {
+	if x != nil {
+		return x.Dependency
+	}
+	return false
+}
+
+func (x *Node) GetApproximate() bool {
+	if x != nil {
+		return x.Approximate
+	}
+	return false
+}
+
+func (x *Node) GetRegisteredBy() string {
+	if x != nil {
+		return x.RegisteredBy
+	}
+	return ""
+}
+
+func (x *Node) GetCallerCount() int32 {
+	if x != nil {
+		return x.CallerCount
+	}
+	return 0
+}
+
+func (x *Node) GetCalleeCount() int32 {
+	if x != nil {
+		return x.CalleeCount
+	}
+	return 0
+}
+
+type Parameter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is unset for parameters whose type is unknown.
+	Type          *string `protobuf:"bytes,2,opt,name=type,proto3,oneof" json:"type,omitempty"`
+	IsUsed        bool    `protobuf:"varint,3,opt,name=is_used,json=isUsed,proto3" json:"is_used,omitempty"`
+	Position      int32   `protobuf:"varint,4,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Parameter) Reset() {
+	*x = Parameter{}
+	mi := &file_proto_codegraph_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Parameter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Parameter) ProtoMessage() {}
+
+func (x *Parameter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Parameter.ProtoReflect.Descriptor instead.
+func (*Parameter) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Parameter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Parameter) GetType() string {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return ""
+}
+
+func (x *Parameter) GetIsUsed() bool {
+	if x != nil {
+		return x.IsUsed
+	}
+	return false
+}
+
+func (x *Parameter) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+// Edge is goanalyzer.Edge.
+type Edge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	CallSite      *CallSite              `protobuf:"bytes,3,opt,name=call_site,json=callSite,proto3" json:"call_site,omitempty"`
+	Kind          string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	IsResolved    bool                   `protobuf:"varint,5,opt,name=is_resolved,json=isResolved,proto3" json:"is_resolved,omitempty"`
+	Weight        float64                `protobuf:"fixed64,6,opt,name=weight,proto3" json:"weight,omitempty"`
+	Provenance    *Provenance            `protobuf:"bytes,7,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	Route         string                 `protobuf:"bytes,8,opt,name=route,proto3" json:"route,omitempty"`
+	Middleware    []string               `protobuf:"bytes,9,rep,name=middleware,proto3" json:"middleware,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_proto_codegraph_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Edge) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Edge) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Edge) GetCallSite() *CallSite {
+	if x != nil {
+		return x.CallSite
+	}
+	return nil
+}
+
+func (x *Edge) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Edge) GetIsResolved() bool {
+	if x != nil {
+		return x.IsResolved
+	}
+	return false
+}
+
+func (x *Edge) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Edge) GetProvenance() *Provenance {
+	if x != nil {
+		return x.Provenance
+	}
+	return nil
+}
+
+func (x *Edge) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *Edge) GetMiddleware() []string {
+	if x != nil {
+		return x.Middleware
+	}
+	return nil
+}
+
+type CallSite struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FilePath      string                 `protobuf:"bytes,1,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Line          int32                  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column        int32                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallSite) Reset() {
+	*x = CallSite{}
+	mi := &file_proto_codegraph_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallSite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallSite) ProtoMessage() {}
+
+func (x *CallSite) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallSite.ProtoReflect.Descriptor instead.
+func (*CallSite) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CallSite) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *CallSite) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *CallSite) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+type Provenance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phase         string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	Rule          string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Provenance) Reset() {
+	*x = Provenance{}
+	mi := &file_proto_codegraph_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Provenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Provenance) ProtoMessage() {}
+
+func (x *Provenance) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Provenance.ProtoReflect.Descriptor instead.
+func (*Provenance) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Provenance) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Provenance) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *Provenance) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type Diagnostic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	FilePath      string                 `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Line          int32                  `protobuf:"varint,3,opt,name=line,proto3" json:"line,omitempty"`
+	Column        int32                  `protobuf:"varint,4,opt,name=column,proto3" json:"column,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Diagnostic) Reset() {
+	*x = Diagnostic{}
+	mi := &file_proto_codegraph_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Diagnostic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Diagnostic) ProtoMessage() {}
+
+func (x *Diagnostic) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Diagnostic.ProtoReflect.Descriptor instead.
+func (*Diagnostic) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Diagnostic) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetFilePath() string {
+	if x != nil {
+		return x.FilePath
+	}
+	return ""
+}
+
+func (x *Diagnostic) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Diagnostic) GetColumn() int32 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *Diagnostic) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// GraphDiff is goanalyzer.GraphDiff.
+type GraphDiff struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         []string               `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []string               `protobuf:"bytes,2,rep,name=removed,proto3" json:"removed,omitempty"`
+	Changed       []string               `protobuf:"bytes,3,rep,name=changed,proto3" json:"changed,omitempty"`
+	Moved         []*NodeMove            `protobuf:"bytes,4,rep,name=moved,proto3" json:"moved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphDiff) Reset() {
+	*x = GraphDiff{}
+	mi := &file_proto_codegraph_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphDiff) ProtoMessage() {}
+
+func (x *GraphDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphDiff.ProtoReflect.Descriptor instead.
+func (*GraphDiff) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GraphDiff) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *GraphDiff) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *GraphDiff) GetChanged() []string {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *GraphDiff) GetMoved() []*NodeMove {
+	if x != nil {
+		return x.Moved
+	}
+	return nil
+}
+
+type NodeMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	FromSymbol    string                 `protobuf:"bytes,3,opt,name=from_symbol,json=fromSymbol,proto3" json:"from_symbol,omitempty"`
+	ToSymbol      string                 `protobuf:"bytes,4,opt,name=to_symbol,json=toSymbol,proto3" json:"to_symbol,omitempty"`
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Renamed       bool                   `protobuf:"varint,6,opt,name=renamed,proto3" json:"renamed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeMove) Reset() {
+	*x = NodeMove{}
+	mi := &file_proto_codegraph_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeMove) ProtoMessage() {}
+
+func (x *NodeMove) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_codegraph_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeMove.ProtoReflect.Descriptor instead.
+func (*NodeMove) Descriptor() ([]byte, []int) {
+	return file_proto_codegraph_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *NodeMove) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *NodeMove) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *NodeMove) GetFromSymbol() string {
+	if x != nil {
+		return x.FromSymbol
+	}
+	return ""
+}
+
+func (x *NodeMove) GetToSymbol() string {
+	if x != nil {
+		return x.ToSymbol
+	}
+	return ""
+}
+
+func (x *NodeMove) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *NodeMove) GetRenamed() bool {
+	if x != nil {
+		return x.Renamed
+	}
+	return false
+}
+
+var File_proto_codegraph_proto protoreflect.FileDescriptor
+
+const file_proto_codegraph_proto_rawDesc = "" +
+	"\n" +
+	"\x15proto/codegraph.proto\x12\fcodegraph.v1\"*\n" +
+	"\x0eAnalyzeRequest\x12\x18\n" +
+	"\aoptions\x18\x01 \x01(\tR\aoptions\"[\n" +
+	"\fQueryRequest\x12\x18\n" +
+	"\aoptions\x18\x01 \x01(\tR\aoptions\x12\x14\n" +
+	"\x05roots\x18\x02 \x03(\tR\x05roots\x12\x1b\n" +
+	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\"q\n" +
+	"\vDiffRequest\x120\n" +
+	"\told_graph\x18\x01 \x01(\v2\x13.codegraph.v1.GraphR\boldGraph\x120\n" +
+	"\tnew_graph\x18\x02 \x01(\v2\x13.codegraph.v1.GraphR\bnewGraph\"Z\n" +
+	"\n" +
+	"WatchEvent\x12+\n" +
+	"\x05graph\x18\x01 \x01(\v2\x13.codegraph.v1.GraphH\x00R\x05graph\x12\x16\n" +
+	"\x05error\x18\x02 \x01(\tH\x00R\x05errorB\a\n" +
+	"\x05event\"\xc7\x02\n" +
+	"\x05Graph\x12(\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x12.codegraph.v1.NodeR\x05nodes\x12(\n" +
+	"\x05edges\x18\x02 \x03(\v2\x12.codegraph.v1.EdgeR\x05edges\x12:\n" +
+	"\vdiagnostics\x18\x03 \x03(\v2\x18.codegraph.v1.DiagnosticR\vdiagnostics\x12\x1d\n" +
+	"\n" +
+	"graph_hash\x18\x04 \x01(\tR\tgraphHash\x12M\n" +
+	"\x0epackage_hashes\x18\x05 \x03(\v2&.codegraph.v1.Graph.PackageHashesEntryR\rpackageHashes\x1a@\n" +
+	"\x12PackageHashesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe9\x05\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12%\n" +
+	"\x0equalified_name\x18\x03 \x01(\tR\rqualifiedName\x12\x1b\n" +
+	"\tfile_path\x18\x04 \x01(\tR\bfilePath\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x05 \x01(\x05R\tstartLine\x12\x19\n" +
+	"\bend_line\x18\x06 \x01(\x05R\aendLine\x12\x1a\n" +
+	"\blanguage\x18\a \x01(\tR\blanguage\x12\x12\n" +
+	"\x04kind\x18\b \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\t \x01(\tR\n" +
+	"visibility\x12$\n" +
+	"\x0eis_entry_point\x18\n" +
+	" \x01(\bR\fisEntryPoint\x127\n" +
+	"\n" +
+	"parameters\x18\v \x03(\v2\x17.codegraph.v1.ParameterR\n" +
+	"parameters\x12+\n" +
+	"\x11unused_parameters\x18\f \x03(\tR\x10unusedParameters\x12*\n" +
+	"\x11package_or_module\x18\r \x01(\tR\x0fpackageOrModule\x12\"\n" +
+	"\rlines_of_code\x18\x0e \x01(\x05R\vlinesOfCode\x12\x16\n" +
+	"\x06status\x18\x0f \x01(\tR\x06status\x12\x14\n" +
+	"\x05color\x18\x10 \x01(\tR\x05color\x12\x1b\n" +
+	"\tsymbol_id\x18\x11 \x01(\tR\bsymbolId\x12\x1b\n" +
+	"\tbody_hash\x18\x12 \x01(\tR\bbodyHash\x12\x1e\n" +
+	"\n" +
+	"dependency\x18\x13 \x01(\bR\n" +
+	"dependency\x12 \n" +
+	"\vapproximate\x18\x14 \x01(\bR\vapproximate\x12#\n" +
+	"\rregistered_by\x18\x15 \x01(\tR\fregisteredBy\x12!\n" +
+	"\fcaller_count\x18\x16 \x01(\x05R\vcallerCount\x12!\n" +
+	"\fcallee_count\x18\x17 \x01(\x05R\vcalleeCount\"v\n" +
+	"\tParameter\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\x04type\x18\x02 \x01(\tH\x00R\x04type\x88\x01\x01\x12\x17\n" +
+	"\ais_used\x18\x03 \x01(\bR\x06isUsed\x12\x1a\n" +
+	"\bposition\x18\x04 \x01(\x05R\bpositionB\a\n" +
+	"\x05_type\"\xa8\x02\n" +
+	"\x04Edge\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x123\n" +
+	"\tcall_site\x18\x03 \x01(\v2\x16.codegraph.v1.CallSiteR\bcallSite\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12\x1f\n" +
+	"\vis_resolved\x18\x05 \x01(\bR\n" +
+	"isResolved\x12\x16\n" +
+	"\x06weight\x18\x06 \x01(\x01R\x06weight\x128\n" +
+	"\n" +
+	"provenance\x18\a \x01(\v2\x18.codegraph.v1.ProvenanceR\n" +
+	"provenance\x12\x14\n" +
+	"\x05route\x18\b \x01(\tR\x05route\x12\x1e\n" +
+	"\n" +
+	"middleware\x18\t \x03(\tR\n" +
+	"middleware\"S\n" +
+	"\bCallSite\x12\x1b\n" +
+	"\tfile_path\x18\x01 \x01(\tR\bfilePath\x12\x12\n" +
+	"\x04line\x18\x02 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x05R\x06column\"P\n" +
+	"\n" +
+	"Provenance\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\"\x83\x01\n" +
+	"\n" +
+	"Diagnostic\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1b\n" +
+	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12\x12\n" +
+	"\x04line\x18\x03 \x01(\x05R\x04line\x12\x16\n" +
+	"\x06column\x18\x04 \x01(\x05R\x06column\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\"\x83\x01\n" +
+	"\tGraphDiff\x12\x14\n" +
+	"\x05added\x18\x01 \x03(\tR\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x03(\tR\aremoved\x12\x18\n" +
+	"\achanged\x18\x03 \x03(\tR\achanged\x12,\n" +
+	"\x05moved\x18\x04 \x03(\v2\x16.codegraph.v1.NodeMoveR\x05moved\"\x9e\x01\n" +
+	"\bNodeMove\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x1f\n" +
+	"\vfrom_symbol\x18\x03 \x01(\tR\n" +
+	"fromSymbol\x12\x1b\n" +
+	"\tto_symbol\x18\x04 \x01(\tR\btoSymbol\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x18\n" +
+	"\arenamed\x18\x06 \x01(\bR\arenamed2\x81\x02\n" +
+	"\bGoHelper\x12<\n" +
+	"\aAnalyze\x12\x1c.codegraph.v1.AnalyzeRequest\x1a\x13.codegraph.v1.Graph\x128\n" +
+	"\x05Query\x12\x1a.codegraph.v1.QueryRequest\x1a\x13.codegraph.v1.Graph\x12:\n" +
+	"\x04Diff\x12\x19.codegraph.v1.DiffRequest\x1a\x17.codegraph.v1.GraphDiff\x12A\n" +
+	"\x05Watch\x12\x1c.codegraph.v1.AnalyzeRequest\x1a\x18.codegraph.v1.WatchEvent0\x01B2Z0github.com/codegraph/go-helper/proto/codegraphv1b\x06proto3"
+
+var (
+	file_proto_codegraph_proto_rawDescOnce sync.Once
+	file_proto_codegraph_proto_rawDescData []byte
+)
+
+func file_proto_codegraph_proto_rawDescGZIP() []byte {
+	file_proto_codegraph_proto_rawDescOnce.Do(func() {
+		file_proto_codegraph_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_codegraph_proto_rawDesc), len(file_proto_codegraph_proto_rawDesc)))
+	})
+	return file_proto_codegraph_proto_rawDescData
+}
+
+var file_proto_codegraph_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_codegraph_proto_goTypes = []any{
+	(*AnalyzeRequest)(nil), // 0: codegraph.v1.AnalyzeRequest
+	(*QueryRequest)(nil),   // 1: codegraph.v1.QueryRequest
+	(*DiffRequest)(nil),    // 2: codegraph.v1.DiffRequest
+	(*WatchEvent)(nil),     // 3: codegraph.v1.WatchEvent
+	(*Graph)(nil),          // 4: codegraph.v1.Graph
+	(*Node)(nil),           // 5: codegraph.v1.Node
+	(*Parameter)(nil),      // 6: codegraph.v1.Parameter
+	(*Edge)(nil),           // 7: codegraph.v1.Edge
+	(*CallSite)(nil),       // 8: codegraph.v1.CallSite
+	(*Provenance)(nil),     // 9: codegraph.v1.Provenance
+	(*Diagnostic)(nil),     // 10: codegraph.v1.Diagnostic
+	(*GraphDiff)(nil),      // 11: codegraph.v1.GraphDiff
+	(*NodeMove)(nil),       // 12: codegraph.v1.NodeMove
+	nil,                    // 13: codegraph.v1.Graph.PackageHashesEntry
+}
+var file_proto_codegraph_proto_depIdxs = []int32{
+	4,  // 0: codegraph.v1.DiffRequest.old_graph:type_name -> codegraph.v1.Graph
+	4,  // 1: codegraph.v1.DiffRequest.new_graph:type_name -> codegraph.v1.Graph
+	4,  // 2: codegraph.v1.WatchEvent.graph:type_name -> codegraph.v1.Graph
+	5,  // 3: codegraph.v1.Graph.nodes:type_name -> codegraph.v1.Node
+	7,  // 4: codegraph.v1.Graph.edges:type_name -> codegraph.v1.Edge
+	10, // 5: codegraph.v1.Graph.diagnostics:type_name -> codegraph.v1.Diagnostic
+	13, // 6: codegraph.v1.Graph.package_hashes:type_name -> codegraph.v1.Graph.PackageHashesEntry
+	6,  // 7: codegraph.v1.Node.parameters:type_name -> codegraph.v1.Parameter
+	8,  // 8: codegraph.v1.Edge.call_site:type_name -> codegraph.v1.CallSite
+	9,  // 9: codegraph.v1.Edge.provenance:type_name -> codegraph.v1.Provenance
+	12, // 10: codegraph.v1.GraphDiff.moved:type_name -> codegraph.v1.NodeMove
+	0,  // 11: codegraph.v1.GoHelper.Analyze:input_type -> codegraph.v1.AnalyzeRequest
+	1,  // 12: codegraph.v1.GoHelper.Query:input_type -> codegraph.v1.QueryRequest
+	2,  // 13: codegraph.v1.GoHelper.Diff:input_type -> codegraph.v1.DiffRequest
+	0,  // 14: codegraph.v1.GoHelper.Watch:input_type -> codegraph.v1.AnalyzeRequest
+	4,  // 15: codegraph.v1.GoHelper.Analyze:output_type -> codegraph.v1.Graph
+	4,  // 16: codegraph.v1.GoHelper.Query:output_type -> codegraph.v1.Graph
+	11, // 17: codegraph.v1.GoHelper.Diff:output_type -> codegraph.v1.GraphDiff
+	3,  // 18: codegraph.v1.GoHelper.Watch:output_type -> codegraph.v1.WatchEvent
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_proto_codegraph_proto_init() }
+func file_proto_codegraph_proto_init() {
+	if File_proto_codegraph_proto != nil {
+		return
+	}
+	file_proto_codegraph_proto_msgTypes[3].OneofWrappers = []any{
+		(*WatchEvent_Graph)(nil),
+		(*WatchEvent_Error)(nil),
+	}
+	file_proto_codegraph_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_codegraph_proto_rawDesc), len(file_proto_codegraph_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_codegraph_proto_goTypes,
+		DependencyIndexes: file_proto_codegraph_proto_depIdxs,
+		MessageInfos:      file_proto_codegraph_proto_msgTypes,
+	}.Build()
+	File_proto_codegraph_proto = out.File
+	file_proto_codegraph_proto_goTypes = nil
+	file_proto_codegraph_proto_depIdxs = nil
+}
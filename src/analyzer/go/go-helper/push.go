@@ -0,0 +1,212 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// Retry policy of --sync: each batch is attempted syncAttempts times,
+// waiting syncRetryDelay and then twice as long after each failure.
+const (
+	syncAttempts   = 5
+	syncRetryDelay = time.Second
+)
+
+// defaultSyncBatch is --sync-batch when unset.
+const defaultSyncBatch = 1000
+
+// syncBatch is the JSON body of each request of --sync: a slice of the
+// change set from the graph the endpoint holds to the current one.
+// Sequence counts batches over the run, and Last marks the final batch of
+// a change set, after which the endpoint holds the graph of GraphHash.
+type syncBatch struct {
+	Project   string `json:"project"`
+	Sequence  int    `json:"sequence"`
+	Last      bool   `json:"last"`
+	GraphHash string `json:"graphHash,omitempty"`
+	goanalyzer.ChangeSet
+}
+
+// syncStatusError is an unsuccessful HTTP response; rate limiting and
+// server errors are retried, other statuses are not.
+type syncStatusError struct {
+	status string
+	retry  bool
+}
+
+func (e *syncStatusError) Error() string { return "endpoint answered " + e.status }
+
+// pusher posts change sets to the --sync endpoint.
+type pusher struct {
+	url      string
+	headers  http.Header
+	size     int
+	client   *http.Client
+	log      io.Writer
+	project  string
+	sequence int
+}
+
+// syncGraph keeps the endpoint's copy of the graph fresh: it analyzes the
+// project, pushes the whole graph as upserts, then polls the project for
+// changes and pushes the writes from the last pushed graph to each new
+// one. A push that fails after its retries is attempted again from the
+// same pushed graph on the next poll, so the writes it missed are not lost
+// (re-sending those that succeeded is harmless: upserts and deletes are
+// idempotent). It runs until the project can no longer be read.
+func syncGraph(cfg *cliConfig, base goanalyzer.Options, log io.Writer) error {
+	headers := make(http.Header)
+	for _, h := range cfg.syncHeaders {
+		name, value, _ := strings.Cut(h, ":")
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	p := &pusher{
+		url:     cfg.sync,
+		headers: headers,
+		size:    cfg.syncBatch,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		log:     log,
+	}
+	cache := goanalyzer.NewLoadCache()
+
+	var pushed goanalyzer.Graph
+	var last string
+	for {
+		// Files are discovered again on each analysis, to pick up new ones.
+		opts, err := cfg.complete(base)
+		if err != nil {
+			return err
+		}
+		root, err := filepath.Abs(opts.ProjectRoot)
+		if err != nil {
+			return err
+		}
+		p.project = opts.Module
+		fingerprint, err := goanalyzer.FingerprintProject(root)
+		if err != nil {
+			return err
+		}
+		if fingerprint != last {
+			opts.Cache, opts.Log = cache, log
+			graph, err := goanalyzer.Analyze(context.Background(), opts)
+			if err != nil {
+				// The project is analyzed again once it changes.
+				fmt.Fprintf(log, "Analysis failed: %v\n", err)
+				last = fingerprint
+			} else if err := p.push(goanalyzer.Changes(pushed, graph), graph.GraphHash); err != nil {
+				fmt.Fprintf(log, "Sync failed, retrying at the next check: %v\n", err)
+			} else {
+				pushed, last = graph, fingerprint
+			}
+		}
+		time.Sleep(watchInterval)
+	}
+}
+
+// push posts the change set in batches of at most p.size writes: node
+// upserts first and node deletes last, so that no batch has an edge to a
+// node the endpoint has not seen.
+func (p *pusher) push(c goanalyzer.ChangeSet, graphHash string) error {
+	if c.Len() == 0 {
+		return nil
+	}
+	var batches []goanalyzer.ChangeSet
+	cur := emptyChangeSet()
+	// next starts a new batch when the current one is full.
+	next := func() {
+		if cur.Len() == p.size {
+			batches = append(batches, cur)
+			cur = emptyChangeSet()
+		}
+	}
+	for _, n := range c.UpsertNodes {
+		next()
+		cur.UpsertNodes = append(cur.UpsertNodes, n)
+	}
+	for _, e := range c.UpsertEdges {
+		next()
+		cur.UpsertEdges = append(cur.UpsertEdges, e)
+	}
+	for _, k := range c.DeleteEdges {
+		next()
+		cur.DeleteEdges = append(cur.DeleteEdges, k)
+	}
+	for _, id := range c.DeleteNodes {
+		next()
+		cur.DeleteNodes = append(cur.DeleteNodes, id)
+	}
+	batches = append(batches, cur)
+
+	for i, b := range batches {
+		p.sequence++
+		batch := syncBatch{Project: p.project, Sequence: p.sequence, ChangeSet: b}
+		if i == len(batches)-1 {
+			batch.Last, batch.GraphHash = true, graphHash
+		}
+		if err := p.post(batch); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(p.log, "Synced %d changes in %d batches\n", c.Len(), len(batches))
+	return nil
+}
+
+// emptyChangeSet has empty rather than null lists, for the endpoint's sake.
+func emptyChangeSet() goanalyzer.ChangeSet {
+	return goanalyzer.ChangeSet{UpsertNodes: []goanalyzer.Node{}, DeleteNodes: []string{}, UpsertEdges: []goanalyzer.Edge{}, DeleteEdges: []goanalyzer.EdgeKey{}}
+}
+
+// post sends one batch, retrying failed attempts that may succeed later.
+func (p *pusher) post(batch syncBatch) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	delay := syncRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := p.postOnce(body)
+		var status *syncStatusError
+		if err == nil || attempt == syncAttempts || (errors.As(err, &status) && !status.retry) {
+			return err
+		}
+		fmt.Fprintf(p.log, "Sync attempt %d of batch %d failed: %v\n", attempt, batch.Sequence, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (p *pusher) postOnce(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range p.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	return &syncStatusError{
+		status: resp.Status,
+		retry:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+}
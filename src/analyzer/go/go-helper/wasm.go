@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/codegraph/go-helper/pkg/goanalyzer"
+)
+
+// main registers codegraphAnalyzeSources on the JavaScript global object and
+// blocks so the function stays callable:
+//
+//	const json = codegraphAnalyzeSources({"main.go": src}, {module: "example.com/m", passes: []});
+//
+// The first argument maps file names to contents; the optional second is a
+// goanalyzer.Options object (files and projectRoot are ignored). The result
+// is the Graph encoded as a JSON string, or an {error: message} object on
+// failure (a Go panic inside the callback would stop the runtime rather than
+// throw).
+func main() {
+	js.Global().Set("codegraphAnalyzeSources", js.FuncOf(analyzeSourcesJS))
+	select {}
+}
+
+func analyzeSourcesJS(this js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeObject {
+		return jsError("codegraphAnalyzeSources: expected an object of file name → source")
+	}
+
+	sources := make(map[string]string)
+	keys := js.Global().Get("Object").Call("keys", args[0])
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		sources[name] = args[0].Get(name).String()
+	}
+
+	var opts goanalyzer.Options
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		raw := js.Global().Get("JSON").Call("stringify", args[1]).String()
+		var err error
+		if opts, err = goanalyzer.ParseOptions([]byte(raw)); err != nil {
+			return jsError("codegraphAnalyzeSources: invalid options: " + err.Error())
+		}
+	}
+	opts.ProjectRoot = ""
+
+	out, err := json.Marshal(goanalyzer.AnalyzeSources(sources, opts))
+	if err != nil {
+		return jsError("codegraphAnalyzeSources: " + err.Error())
+	}
+	return string(out)
+}
+
+func jsError(msg string) any {
+	return map[string]any{"error": msg}
+}
@@ -7,11 +7,11 @@ export type FunctionKind = 'function' | 'method' | 'constructor' | 'arrow' | 'cl
 /** Visibility/access level of a function */
 export type Visibility = 'exported' | 'public' | 'private' | 'internal' | 'module';
 
-/** Status of a node in the call graph */
-export type NodeStatus = 'live' | 'dead' | 'entry';
+/** Status of a node in the call graph ('test-only': reachable only from Go tests) */
+export type NodeStatus = 'live' | 'dead' | 'entry' | 'test-only';
 
 /** Color derived from node status */
-export type NodeColor = 'green' | 'red' | 'yellow' | 'orange' | 'blue';
+export type NodeColor = 'green' | 'red' | 'yellow' | 'orange' | 'blue' | 'purple';
 
 /** The nature of a call edge */
 export type EdgeKind = 'direct' | 'method' | 'constructor' | 'callback' | 'dynamic';
@@ -44,6 +44,16 @@ export interface GraphNode {
   color: NodeColor;
   /** Decorator/annotation names applied to this function (e.g., ["app.route", "login_required"]) */
   decorators?: string[];
+  /** Why the analyzer keeps this function live regardless of its callers (e.g., a //codegraph:keep directive) */
+  keptBy?: string;
+  /** linesOfCode split into code, comment-only and blank lines (Go only) */
+  sloc?: number;
+  commentLines?: number;
+  blankLines?: number;
+  /** Main packages whose binary reaches this function (Go only, e.g. ["cmd/api"]) */
+  reachableFrom?: string[];
+  /** Build contexts (GOOS/GOARCH/tags) the function exists in, when not all of them (Go only) */
+  buildContexts?: string[];
 }
 
 /** Location of a call site */
@@ -48,6 +48,7 @@ function onSearchInput(): void {
       node.color === 'yellow' ? 'var(--yellow)' :
       node.color === 'orange' ? 'var(--orange)' :
       node.color === 'blue' ? 'var(--blue)' :
+      node.color === 'purple' ? 'var(--purple)' :
       'var(--green)';
 
     return `
@@ -8,6 +8,7 @@ export interface ColorPalette {
   unused: THREE.Color;
   orange: THREE.Color;
   entry: THREE.Color;
+  testOnly: THREE.Color;
   selected: THREE.Color;
   hovered: THREE.Color;
   dimmed: THREE.Color;
@@ -24,6 +25,7 @@ const NORMAL_PALETTE: ColorPalette = {
   unused: new THREE.Color(0xffc84d),
   orange: new THREE.Color(0xff8a4d),
   entry: new THREE.Color(0x4d9eff),
+  testOnly: new THREE.Color(0xb07dff),
   selected: new THREE.Color(0xffffff),
   hovered: new THREE.Color(0xe0e0ff),
   dimmed: new THREE.Color(0x333344),
@@ -40,6 +42,7 @@ const COLORBLIND_PALETTE: ColorPalette = {
   unused: new THREE.Color(0xe69f00),   // Orange
   orange: new THREE.Color(0xd55e00),   // Vermillion
   entry: new THREE.Color(0x009e73),    // Teal
+  testOnly: new THREE.Color(0xf0e442), // Yellow
   selected: new THREE.Color(0xffffff),
   hovered: new THREE.Color(0xe0e0ff),
   dimmed: new THREE.Color(0x333344),
@@ -83,6 +86,7 @@ export function getNodeColor(status: string, color: string): THREE.Color {
     case 'yellow': return p.unused;
     case 'orange': return p.orange;
     case 'blue': return p.entry;
+    case 'purple': return p.testOnly;
     case 'green':
     default: return p.live;
   }
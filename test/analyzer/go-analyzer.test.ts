@@ -87,6 +87,18 @@ describe.skipIf(!goAvailable)('Go Analyzer', () => {
       expect(anotherDead!.unusedParameters).toContain('param1');
       expect(anotherDead!.unusedParameters).toContain('param2');
     });
+
+    it('should flag a parameter shadowed by a local variable', () => {
+      const trimmed = nodes.find(n => n.name === 'trimmed');
+      expect(trimmed).toBeDefined();
+      expect(trimmed!.unusedParameters).toEqual(['input']);
+    });
+
+    it('should not count a field of the same name as a use', () => {
+      const requestName = nodes.find(n => n.name === 'requestName');
+      expect(requestName).toBeDefined();
+      expect(requestName!.unusedParameters).toEqual(['name']);
+    });
   });
 
   describe('Call resolution', () => {
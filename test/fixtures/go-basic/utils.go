@@ -8,3 +8,20 @@ func sanitize(input string, encoding string) string {
 	// encoding is unused
 	return input
 }
+
+type request struct {
+	name string
+}
+
+func trimmed(input string) string {
+	// input is shadowed, so the parameter itself is unused
+	if input := "default"; input != "" {
+		return input
+	}
+	return ""
+}
+
+func requestName(name string, r request) string {
+	// name is unused; r.name is the field
+	return r.name
+}
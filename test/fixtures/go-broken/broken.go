@@ -0,0 +1,16 @@
+package main
+
+// broken is mid-edit: the call is never closed.
+func broken() {
+	ok(
+}
+
+func recovered() {
+	ok()
+}
+
+type Store struct{}
+
+func (s *Store) Save() {
+	ok()
+}
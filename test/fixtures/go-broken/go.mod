@@ -0,0 +1,3 @@
+module example.com/go-broken
+
+go 1.21
@@ -0,0 +1,9 @@
+package main
+
+func main() {
+	ok()
+	broken()
+	recovered()
+}
+
+func ok() {}